@@ -0,0 +1,143 @@
+package pond
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// AdaptiveConcurrency configures a controller that adjusts the number of tasks a pool runs at the same time based
+// on their observed latency, to protect the downstream dependencies they call from overload (see ConcurrencyLimit).
+// It uses an additive-increase/multiplicative-decrease (AIMD) algorithm: the limit grows by one every time a limit's
+// worth of tasks complete below the latency threshold while the pool is busy, and it's multiplied by the backoff
+// ratio when a task is slower than the threshold or panics, at most once per latency sample.
+type AdaptiveConcurrency struct {
+	// LatencyThreshold is the task duration above which the downstream dependency is considered overloaded
+	LatencyThreshold time.Duration
+	// MinLimit is the lowest concurrency limit. Defaults to 1.
+	MinLimit int
+	// InitialLimit is the concurrency limit the pool starts with. Defaults to the pool's maximum number of workers,
+	// which is also the highest limit.
+	InitialLimit int
+	// BackoffRatio is the factor the limit is multiplied by when an overload is detected, between 0 and 1
+	// (both excluded). Defaults to 0.9.
+	BackoffRatio float64
+}
+
+// AdaptiveLimit enables the adaptive concurrency controller of a pool, which lowers the number of tasks that can run
+// at the same time below the maximum number of workers when tasks get slow (see AdaptiveConcurrency). Workers that
+// exceed the limit wait before running their next task.
+func AdaptiveLimit(config AdaptiveConcurrency) Option {
+	return func(pool *WorkerPool) {
+		pool.adaptive = &adaptiveLimiter{
+			requested: config,
+			config:    config,
+		}
+	}
+}
+
+// adaptiveLimiter enforces a concurrency limit adjusted by an AIMD controller
+type adaptiveLimiter struct {
+	// Settings as given to AdaptiveLimit and after invalid values were replaced by their defaults
+	requested AdaptiveConcurrency
+	config    AdaptiveConcurrency
+	maxLimit  int
+	limit     float64
+	running   int
+	// Time of the last decrease of the limit
+	decreasedAt time.Time
+	gate        *semaphore
+	mutex       sync.Mutex
+}
+
+// normalize replaces invalid settings by their defaults, given the pool's maximum number of workers
+func (l *adaptiveLimiter) normalize(maxWorkers int) {
+	if l == nil {
+		return
+	}
+	l.maxLimit = maxWorkers
+	if l.config.MinLimit <= 0 {
+		l.config.MinLimit = 1
+	}
+	if l.config.MinLimit > maxWorkers {
+		l.config.MinLimit = maxWorkers
+	}
+	if l.config.InitialLimit <= 0 || l.config.InitialLimit > maxWorkers {
+		l.config.InitialLimit = maxWorkers
+	}
+	if l.config.InitialLimit < l.config.MinLimit {
+		l.config.InitialLimit = l.config.MinLimit
+	}
+	if l.config.BackoffRatio <= 0 || l.config.BackoffRatio >= 1 {
+		l.config.BackoffRatio = 0.9
+	}
+	l.limit = float64(l.config.InitialLimit)
+	l.gate = newSemaphore(int64(l.config.InitialLimit))
+}
+
+// acquire waits until a task can run within the current limit
+func (l *adaptiveLimiter) acquire() {
+	if l == nil {
+		return
+	}
+	l.gate.Acquire(context.Background(), 1)
+
+	l.mutex.Lock()
+	l.running++
+	l.mutex.Unlock()
+}
+
+// release frees the slot of a task
+func (l *adaptiveLimiter) release() {
+	if l == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	l.running--
+	l.mutex.Unlock()
+
+	l.gate.Release(1)
+}
+
+// observe adjusts the limit according to a latency sample, measured by a running task (see ReportLatency)
+// or the duration of a task, and whether the task or the downstream call it measured failed
+func (l *adaptiveLimiter) observe(latency time.Duration, failed bool, now time.Time) {
+	if l == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	if failed || latency > l.config.LatencyThreshold {
+		// Back off, unless the limit was already lowered while this sample was being measured
+		if now.Sub(l.decreasedAt) >= latency {
+			l.limit = math.Max(float64(l.config.MinLimit), l.limit*l.config.BackoffRatio)
+			l.decreasedAt = now
+		}
+	} else if float64(l.running) >= l.limit/2 {
+		// Only grow while the pool makes use of the current limit
+		l.limit = math.Min(float64(l.maxLimit), l.limit+1/l.limit)
+	}
+	size := int64(l.limit)
+	l.mutex.Unlock()
+
+	l.gate.Resize(size)
+}
+
+// current returns the current concurrency limit
+func (l *adaptiveLimiter) current() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return int(l.limit)
+}
+
+// ConcurrencyLimit returns the concurrency limit currently set by the adaptive concurrency controller,
+// or the maximum number of workers if the controller is not enabled (see AdaptiveLimit)
+func (p *WorkerPool) ConcurrencyLimit() int {
+	if p.adaptive == nil {
+		return p.maxWorkers
+	}
+	return p.adaptive.current()
+}
@@ -0,0 +1,89 @@
+package pond_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestAdaptiveLimitBacksOffOnSlowTasks(t *testing.T) {
+
+	pool := pond.New(10, 100, pond.AdaptiveLimit(pond.AdaptiveConcurrency{
+		LatencyThreshold: time.Millisecond,
+		BackoffRatio:     0.5,
+	}))
+	defer pool.StopAndWait()
+
+	assertEqual(t, 10, pool.ConcurrencyLimit())
+
+	for i := 0; i < 5; i++ {
+		pool.SubmitAndWait(func() {
+			time.Sleep(5 * time.Millisecond)
+		})
+	}
+
+	assertEqual(t, 1, pool.ConcurrencyLimit())
+}
+
+func TestAdaptiveLimitGrowsWhileBusy(t *testing.T) {
+
+	pool := pond.New(4, 100, pond.AdaptiveLimit(pond.AdaptiveConcurrency{
+		LatencyThreshold: time.Second,
+		InitialLimit:     1,
+	}))
+	defer pool.StopAndWait()
+
+	// Tasks run one at a time, so the limit only grows as long as that's at least half of it
+	for i := 0; i < 10; i++ {
+		pool.SubmitAndWait(func() {})
+	}
+
+	assertEqual(t, 2, pool.ConcurrencyLimit())
+}
+
+func TestAdaptiveLimitIsEnforced(t *testing.T) {
+
+	pool := pond.New(10, 100, pond.AdaptiveLimit(pond.AdaptiveConcurrency{
+		LatencyThreshold: time.Second,
+		InitialLimit:     2,
+	}))
+
+	release := make(chan struct{})
+	var running int32
+	for i := 0; i < 6; i++ {
+		pool.Submit(func() {
+			atomic.AddInt32(&running, 1)
+			<-release
+		})
+	}
+
+	for atomic.LoadInt32(&running) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	assertEqual(t, int32(2), atomic.LoadInt32(&running))
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, int32(6), atomic.LoadInt32(&running))
+}
+
+func TestAdaptiveLimitInvalidConfig(t *testing.T) {
+
+	_, err := pond.NewWithOptions(10, 100, pond.AdaptiveLimit(pond.AdaptiveConcurrency{}))
+	assertEqual(t, "invalid worker pool configuration: adaptive concurrency latency threshold must be greater than 0, got 0s", err.Error())
+
+	_, err = pond.NewWithOptions(10, 100, pond.AdaptiveLimit(pond.AdaptiveConcurrency{
+		LatencyThreshold: time.Second,
+		BackoffRatio:     1.5,
+	}))
+	assertEqual(t, "invalid worker pool configuration: adaptive concurrency backoff ratio must be between 0 and 1, got 1.5", err.Error())
+
+	pool := pond.New(10, 100)
+	defer pool.StopAndWait()
+
+	assertEqual(t, 10, pool.ConcurrencyLimit())
+}
@@ -0,0 +1,25 @@
+//go:build linux
+
+package pond
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// setThreadAffinity binds the calling OS thread to the given CPUs
+func setThreadAffinity(cpus []int) error {
+	var mask [16]uint64 // Up to 1024 CPUs, like glibc's cpu_set_t
+	for _, cpu := range cpus {
+		if cpu >= 0 && cpu < len(mask)*64 {
+			mask[cpu/64] |= 1 << (uint(cpu) % 64)
+		}
+	}
+
+	// A pid of 0 designates the calling thread
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, 0, unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
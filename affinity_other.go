@@ -0,0 +1,8 @@
+//go:build !linux
+
+package pond
+
+// setThreadAffinity is a no-op on platforms that don't support binding threads to CPUs
+func setThreadAffinity(cpus []int) error {
+	return nil
+}
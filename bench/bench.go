@@ -0,0 +1,228 @@
+// Package bench provides a load generator for worker pools, to compare pool configurations under a given load
+// and guard against performance regressions, and to replay traces of production load (see pond.RecordTrace).
+package bench
+
+import (
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+// Distribution returns task durations drawn from a probability distribution
+type Distribution func(r *rand.Rand) time.Duration
+
+// Fixed returns a distribution in which all tasks last the given duration
+func Fixed(duration time.Duration) Distribution {
+	return func(r *rand.Rand) time.Duration {
+		return duration
+	}
+}
+
+// Uniform returns a distribution in which task durations are uniformly distributed between min and max
+func Uniform(min, max time.Duration) Distribution {
+	return func(r *rand.Rand) time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(r.Int63n(int64(max-min)+1))
+	}
+}
+
+// Exponential returns a distribution in which task durations are exponentially distributed around the given mean,
+// so most tasks are short and a few are much longer
+func Exponential(mean time.Duration) Distribution {
+	return func(r *rand.Rand) time.Duration {
+		return time.Duration(r.ExpFloat64() * float64(mean))
+	}
+}
+
+// Load describes the load generated by Run
+type Load struct {
+	// Tasks is the total number of tasks to submit
+	Tasks int
+	// Submitters is the number of goroutines submitting tasks concurrently. It defaults to 1.
+	Submitters int
+	// Rate is the total number of tasks submitted per second by all submitters. If it's 0, tasks are submitted
+	// as fast as the pool accepts them.
+	Rate float64
+	// Duration is the distribution of task durations. If it's nil, tasks return immediately.
+	Duration Distribution
+	// Spin makes tasks keep the CPU busy for their duration, to simulate CPU-bound work, instead of sleeping
+	Spin bool
+	// Seed is the seed of the random durations, so runs can be reproduced
+	Seed int64
+}
+
+// Result holds the measurements of a load run
+type Result struct {
+	// Tasks is the number of tasks that ran
+	Tasks int `json:"tasks"`
+	// Elapsed is the time between the first submission and the completion of the last task
+	Elapsed time.Duration `json:"elapsed"`
+	// Throughput is the number of tasks completed per second
+	Throughput float64 `json:"throughput"`
+	// QueueWait holds the percentiles of the time tasks waited between their submission and their start
+	QueueWait Percentiles `json:"queueWait"`
+	// Allocs and Bytes are the number of heap allocations and allocated bytes per task, including the
+	// allocations of the load generator itself
+	Allocs float64 `json:"allocs"`
+	Bytes  float64 `json:"bytes"`
+}
+
+// Percentiles holds percentiles of a set of durations
+type Percentiles struct {
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+}
+
+// Run submits the tasks described by load to the pool, waits for all of them to complete and reports
+// the measurements. It doesn't stop the pool, which must not run other tasks meanwhile for allocation
+// counts to be accurate.
+func Run(pool *pond.WorkerPool, load Load) Result {
+
+	submitters := load.Submitters
+	if submitters < 1 {
+		submitters = 1
+	}
+
+	plan := schedule{
+		durations:  make([]time.Duration, load.Tasks),
+		submitters: submitters,
+		spin:       load.Spin,
+	}
+
+	// Draw task durations and arrival times up front, so they don't slow down submitters
+	if load.Duration != nil {
+		r := rand.New(rand.NewSource(load.Seed))
+		for i := range plan.durations {
+			plan.durations[i] = load.Duration(r)
+		}
+	}
+	if load.Rate > 0 {
+		plan.arrivals = make([]time.Duration, load.Tasks)
+		for i := range plan.arrivals {
+			plan.arrivals[i] = time.Duration(float64(i) / load.Rate * float64(time.Second))
+		}
+	}
+
+	return plan.run(pool)
+}
+
+// schedule describes the tasks to submit to a pool
+type schedule struct {
+	// Duration of each task
+	durations []time.Duration
+	// Time at which each task is submitted, relative to the start of the run, or nil to submit them as fast as possible
+	arrivals []time.Duration
+	// Label and lane of each task, if any
+	labels []string
+	lanes  []pond.Lane
+	// Number of goroutines submitting tasks, which take turns so tasks are submitted in order overall
+	submitters int
+	spin       bool
+}
+
+// run submits the tasks of the schedule, waits for them to complete and reports the measurements
+func (s schedule) run(pool *pond.WorkerPool) Result {
+
+	count := len(s.durations)
+	waits := make([]time.Duration, count)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	var tasks, submissions sync.WaitGroup
+	tasks.Add(count)
+	submissions.Add(s.submitters)
+
+	start := time.Now()
+	for submitter := 0; submitter < s.submitters; submitter++ {
+		go func(submitter int) {
+			defer submissions.Done()
+
+			for i := submitter; i < count; i += s.submitters {
+				if s.arrivals != nil {
+					if delay := time.Until(start.Add(s.arrivals[i])); delay > 0 {
+						time.Sleep(delay)
+					}
+				}
+
+				i, submitted := i, time.Now()
+				task := func() {
+					defer tasks.Done()
+					waits[i] = time.Since(submitted)
+					work(s.durations[i], s.spin)
+				}
+
+				switch {
+				case s.lanes != nil && s.lanes[i] == pond.BatchLane:
+					pool.SubmitBatch(task)
+				case s.labels != nil && s.labels[i] != "":
+					pool.SubmitLabeled(s.labels[i], task)
+				default:
+					pool.Submit(task)
+				}
+			}
+		}(submitter)
+	}
+	submissions.Wait()
+	tasks.Wait()
+	elapsed := time.Since(start)
+
+	runtime.ReadMemStats(&after)
+
+	result := Result{
+		Tasks:     count,
+		Elapsed:   elapsed,
+		QueueWait: percentiles(waits),
+	}
+	if elapsed > 0 {
+		result.Throughput = float64(count) / elapsed.Seconds()
+	}
+	if count > 0 {
+		result.Allocs = float64(after.Mallocs-before.Mallocs) / float64(count)
+		result.Bytes = float64(after.TotalAlloc-before.TotalAlloc) / float64(count)
+	}
+	return result
+}
+
+// work simulates a task lasting the given duration
+func work(duration time.Duration, spin bool) {
+	if duration <= 0 {
+		return
+	}
+	if !spin {
+		time.Sleep(duration)
+		return
+	}
+	for deadline := time.Now().Add(duration); time.Now().Before(deadline); {
+	}
+}
+
+// percentiles computes the percentiles of the given durations, reordering them
+func percentiles(durations []time.Duration) Percentiles {
+	if len(durations) == 0 {
+		return Percentiles{}
+	}
+
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+	at := func(q float64) time.Duration {
+		return durations[int(q*float64(len(durations)-1))]
+	}
+
+	return Percentiles{
+		P50: at(0.5),
+		P90: at(0.9),
+		P99: at(0.99),
+		Max: durations[len(durations)-1],
+	}
+}
@@ -0,0 +1,81 @@
+package bench_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+	"github.com/kraneware/pond/bench"
+)
+
+func assertEqual(t *testing.T, expected interface{}, actual interface{}) {
+	if expected != actual {
+		t.Helper()
+		t.Errorf("Expected %T(%v) but was %T(%v)", expected, expected, actual, actual)
+	}
+}
+
+func TestRun(t *testing.T) {
+
+	pool := pond.New(4, 100)
+	defer pool.StopAndWait()
+
+	result := bench.Run(pool, bench.Load{
+		Tasks:      200,
+		Submitters: 4,
+		Duration:   bench.Uniform(0, time.Millisecond),
+	})
+
+	assertEqual(t, 200, result.Tasks)
+	assertEqual(t, uint64(200), pool.CompletedTasks())
+	assertEqual(t, true, result.Throughput > 0)
+	assertEqual(t, true, result.QueueWait.P50 <= result.QueueWait.P99)
+	assertEqual(t, true, result.QueueWait.P99 <= result.QueueWait.Max)
+	assertEqual(t, true, result.Allocs > 0)
+}
+
+func TestRunAtRate(t *testing.T) {
+
+	pool := pond.New(4, 100)
+	defer pool.StopAndWait()
+
+	// 20 tasks at 200 tasks per second take about 100ms
+	result := bench.Run(pool, bench.Load{
+		Tasks:      20,
+		Submitters: 2,
+		Rate:       200,
+	})
+
+	assertEqual(t, true, result.Elapsed >= 90*time.Millisecond)
+}
+
+func TestDistributions(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+
+	assertEqual(t, time.Second, bench.Fixed(time.Second)(r))
+	for i := 0; i < 100; i++ {
+		d := bench.Uniform(time.Millisecond, 2*time.Millisecond)(r)
+		assertEqual(t, true, d >= time.Millisecond && d <= 2*time.Millisecond)
+		assertEqual(t, true, bench.Exponential(time.Millisecond)(r) >= 0)
+	}
+}
+
+func BenchmarkRun(b *testing.B) {
+
+	pool := pond.New(4, 1000)
+	defer pool.StopAndWait()
+
+	var result bench.Result
+	for i := 0; i < b.N; i++ {
+		result = bench.Run(pool, bench.Load{
+			Tasks:      1000,
+			Submitters: 4,
+		})
+	}
+
+	b.ReportMetric(result.Throughput, "tasks/s")
+	b.ReportMetric(float64(result.QueueWait.P99.Nanoseconds()), "p99-wait-ns")
+	b.ReportMetric(result.Allocs, "allocs/task")
+}
@@ -0,0 +1,35 @@
+package bench
+
+import (
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+// Replay re-drives the load captured in a trace (see pond.RecordTrace and pond.ReadTrace) against the given pool,
+// to evaluate configuration changes offline with production-shaped load: each task is submitted with its label
+// and lane at the same time, relative to the first submission, as in the trace, and sleeps for its recorded
+// duration. A speedup greater than 1 compresses the arrival pattern (e.g. 2 replays it twice as fast), while task
+// durations are left unchanged. Records must be sorted by submission time, as returned by pond.ReadTrace.
+func Replay(pool *pond.WorkerPool, records []pond.TraceRecord, speedup float64) Result {
+
+	if speedup <= 0 {
+		speedup = 1
+	}
+
+	plan := schedule{
+		durations:  make([]time.Duration, len(records)),
+		arrivals:   make([]time.Duration, len(records)),
+		labels:     make([]string, len(records)),
+		lanes:      make([]pond.Lane, len(records)),
+		submitters: 1,
+	}
+	for i, record := range records {
+		plan.durations[i] = record.Duration
+		plan.arrivals[i] = time.Duration(float64(record.SubmittedAt.Sub(records[0].SubmittedAt)) / speedup)
+		plan.labels[i] = record.Label
+		plan.lanes[i] = record.Lane
+	}
+
+	return plan.run(pool)
+}
@@ -0,0 +1,33 @@
+package bench_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+	"github.com/kraneware/pond/bench"
+)
+
+func TestReplay(t *testing.T) {
+
+	start := time.Now()
+	var records []pond.TraceRecord
+	for i := 0; i < 10; i++ {
+		records = append(records, pond.TraceRecord{
+			ID:          uint64(i + 1),
+			Label:       "replayed",
+			SubmittedAt: start.Add(time.Duration(i) * 20 * time.Millisecond),
+			Duration:    time.Millisecond,
+		})
+	}
+
+	pool := pond.New(2, 10)
+	defer pool.StopAndWait()
+
+	// Arrivals spread over 180ms are replayed twice as fast
+	result := bench.Replay(pool, records, 2)
+
+	assertEqual(t, 10, result.Tasks)
+	assertEqual(t, true, result.Elapsed >= 90*time.Millisecond && result.Elapsed < 180*time.Millisecond)
+	assertEqual(t, uint64(10), pool.LabelStats()["replayed"].Successful)
+}
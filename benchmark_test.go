@@ -0,0 +1,79 @@
+package pond_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+// BenchmarkSubmitIdleWorkers measures the dispatch of tasks to a pool that always has idle workers
+// waiting for them, which is the common case the dispatcher is optimized for
+func BenchmarkSubmitIdleWorkers(b *testing.B) {
+	pool := pond.New(4, 0, pond.MinWorkers(4))
+	defer pool.StopAndWait()
+
+	var waitGroup sync.WaitGroup
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		waitGroup.Add(1)
+		pool.Submit(waitGroup.Done)
+		waitGroup.Wait()
+	}
+}
+
+// BenchmarkSubmitBuffered measures the submission of bursts of tasks that are buffered in the queue
+func BenchmarkSubmitBuffered(b *testing.B) {
+	pool := pond.New(4, 1000)
+	defer pool.StopAndWait()
+
+	var waitGroup sync.WaitGroup
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		waitGroup.Add(1)
+		pool.Submit(waitGroup.Done)
+	}
+	waitGroup.Wait()
+}
+
+// BenchmarkSubmitParallel measures the submission of tasks from multiple goroutines at the same time
+func BenchmarkSubmitParallel(b *testing.B) {
+	pool := pond.New(8, 1000)
+	defer pool.StopAndWait()
+
+	var waitGroup sync.WaitGroup
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			waitGroup.Add(1)
+			pool.Submit(waitGroup.Done)
+		}
+	})
+	waitGroup.Wait()
+}
+
+// BenchmarkSubmitAll measures the submission of bursts of tasks with SubmitAll, to compare with BenchmarkSubmitBuffered
+func BenchmarkSubmitAll(b *testing.B) {
+	pool := pond.New(4, 1000)
+	defer pool.StopAndWait()
+
+	var waitGroup sync.WaitGroup
+	tasks := make([]func(), 1000)
+	for i := range tasks {
+		tasks[i] = waitGroup.Done
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += len(tasks) {
+		waitGroup.Add(len(tasks))
+		pool.SubmitAll(tasks)
+	}
+	waitGroup.Wait()
+}
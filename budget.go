@@ -0,0 +1,240 @@
+package pond
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// budgetWaiter represents a caller blocked until n units of a budget become available
+type budgetWaiter struct {
+	budget *ConcurrencyBudget
+	n      int64
+	ready  chan struct{}
+}
+
+// ConcurrencyBudget caps the number of tasks running concurrently across all the worker pools
+// that share it, on top of the maximum number of workers of each individual pool.
+// Budgets can be organized in a hierarchy by creating weighted children (see Child), which
+// split the units of their parent proportionally to their weights when under contention.
+type ConcurrencyBudget struct {
+	root     *ConcurrencyBudget
+	parent   *ConcurrencyBudget
+	weight   int64
+	children []*ConcurrencyBudget
+	inUse    int64
+	// Root-only properties
+	total   int64
+	waiters list.List
+	mutex   sync.Mutex
+}
+
+// NewConcurrencyBudget creates a concurrency budget that allows up to total tasks to run at the same time
+func NewConcurrencyBudget(total int) *ConcurrencyBudget {
+
+	if total < 1 {
+		total = 1
+	}
+
+	budget := &ConcurrencyBudget{
+		total: int64(total),
+	}
+	budget.root = budget
+
+	return budget
+}
+
+// Budget configures a worker pool to draw from a concurrency budget shared with other pools
+func Budget(budget *ConcurrencyBudget) Option {
+	return func(pool *WorkerPool) {
+		pool.budget = budget
+	}
+}
+
+// Child creates a budget nested under this one with the given weight.
+// When siblings compete for units, each child is guaranteed a share of its parent proportional to its weight
+// (e.g. children with weights 70 and 30 under a budget of 100 get 70 and 30 units respectively).
+// Sharing is work-conserving: a child may borrow units beyond its share while its siblings are not using them,
+// and waiters within their share are always served before waiters trying to borrow.
+func (b *ConcurrencyBudget) Child(weight int) *ConcurrencyBudget {
+
+	if weight < 1 {
+		weight = 1
+	}
+
+	child := &ConcurrencyBudget{
+		root:   b.root,
+		parent: b,
+		weight: int64(weight),
+	}
+
+	b.root.mutex.Lock()
+	b.children = append(b.children, child)
+	b.root.mutex.Unlock()
+
+	return child
+}
+
+// Total returns the maximum number of tasks that can run concurrently across all pools sharing this budget's hierarchy
+func (b *ConcurrencyBudget) Total() int {
+	return int(b.root.total)
+}
+
+// Guaranteed returns the number of units this budget is entitled to when all its siblings are busy
+func (b *ConcurrencyBudget) Guaranteed() int {
+	b.root.mutex.Lock()
+	defer b.root.mutex.Unlock()
+
+	return int(b.share())
+}
+
+// InUse returns the number of units of this budget currently held by running tasks or Acquire callers
+func (b *ConcurrencyBudget) InUse() int {
+	b.root.mutex.Lock()
+	defer b.root.mutex.Unlock()
+
+	return int(b.inUse)
+}
+
+// acquire reserves n units of this budget, blocking until they are available or ctx is done
+func (b *ConcurrencyBudget) acquire(ctx context.Context, n int64) error {
+	if b == nil {
+		return nil
+	}
+
+	root := b.root
+	root.mutex.Lock()
+	if root.waiters.Len() == 0 && root.inUse+n <= root.total {
+		b.grant(n)
+		root.mutex.Unlock()
+		return nil
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		root.mutex.Unlock()
+		return ctxErr
+	}
+
+	ready := make(chan struct{})
+	elem := root.waiters.PushBack(budgetWaiter{budget: b, n: n, ready: ready})
+	root.mutex.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		root.mutex.Lock()
+		select {
+		case <-ready:
+			// Acquired the units right after ctx was cancelled, give them back
+			b.grant(-n)
+		default:
+			root.waiters.Remove(elem)
+		}
+		root.notifyWaiters()
+		root.mutex.Unlock()
+		return ctx.Err()
+	}
+}
+
+// tryAcquire reserves n units of this budget without blocking
+func (b *ConcurrencyBudget) tryAcquire(n int64) bool {
+	if b == nil {
+		return true
+	}
+
+	root := b.root
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	if root.waiters.Len() == 0 && root.inUse+n <= root.total {
+		b.grant(n)
+		return true
+	}
+	return false
+}
+
+// release returns n units to this budget
+func (b *ConcurrencyBudget) release(n int64) {
+	if b == nil {
+		return
+	}
+
+	root := b.root
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	if b.inUse < n {
+		panic("pond: released more units than held")
+	}
+	b.grant(-n)
+	root.notifyWaiters()
+}
+
+// grant adds n units (which can be negative) to this budget and all its ancestors. Must be called with the root mutex held.
+func (b *ConcurrencyBudget) grant(n int64) {
+	for node := b; node != nil; node = node.parent {
+		node.inUse += n
+	}
+}
+
+// share returns the number of units this budget is entitled to. Must be called with the root mutex held.
+func (b *ConcurrencyBudget) share() int64 {
+	if b.parent == nil {
+		return b.total
+	}
+
+	var totalWeight int64
+	for _, sibling := range b.parent.children {
+		totalWeight += sibling.weight
+	}
+
+	share := b.parent.share() * b.weight / totalWeight
+	if share < 1 {
+		share = 1
+	}
+	return share
+}
+
+// withinShare returns true if reserving n more units keeps this budget and its ancestors within their shares.
+// Must be called with the root mutex held.
+func (b *ConcurrencyBudget) withinShare(n int64) bool {
+	for node := b; node.parent != nil; node = node.parent {
+		if node.inUse+n > node.share() {
+			return false
+		}
+	}
+	return true
+}
+
+// notifyWaiters wakes up as many waiters as possible. Waiters within their share are served first, in FIFO order,
+// and only then waiters that need to borrow units from their siblings. Must be called on the root with its mutex held.
+func (b *ConcurrencyBudget) notifyWaiters() {
+
+	for elem := b.waiters.Front(); elem != nil; {
+		next := elem.Next()
+		w := elem.Value.(budgetWaiter)
+		if w.budget.withinShare(w.n) {
+			if b.inUse+w.n > b.total {
+				// Not enough units, keep them for this waiter instead of lending them to others
+				return
+			}
+			w.budget.grant(w.n)
+			b.waiters.Remove(elem)
+			close(w.ready)
+		}
+		elem = next
+	}
+
+	for elem := b.waiters.Front(); elem != nil; {
+		next := elem.Next()
+		w := elem.Value.(budgetWaiter)
+		if b.inUse+w.n > b.total {
+			return
+		}
+		w.budget.grant(w.n)
+		b.waiters.Remove(elem)
+		close(w.ready)
+		elem = next
+	}
+}
@@ -0,0 +1,124 @@
+package pond_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestConcurrencyBudgetSharedAcrossPools(t *testing.T) {
+
+	budget := pond.NewConcurrencyBudget(3)
+	assertEqual(t, 3, budget.Total())
+
+	pool1 := pond.New(3, 100, pond.Budget(budget))
+	pool2 := pond.New(3, 100, pond.Budget(budget))
+
+	var running, maxRunning int32
+	task := func() {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	}
+
+	for i := 0; i < 20; i++ {
+		pool1.Submit(task)
+		pool2.Submit(task)
+	}
+
+	pool1.StopAndWait()
+	pool2.StopAndWait()
+
+	assertEqual(t, true, atomic.LoadInt32(&maxRunning) <= 3)
+	assertEqual(t, 0, budget.InUse())
+}
+
+func TestConcurrencyBudgetWithAcquire(t *testing.T) {
+
+	budget := pond.NewConcurrencyBudget(2)
+	pool1 := pond.New(2, 10, pond.Budget(budget))
+	pool2 := pond.New(2, 10, pond.Budget(budget))
+	defer pool1.StopAndWait()
+	defer pool2.StopAndWait()
+
+	err := pool1.Acquire(context.Background(), 2)
+	assertEqual(t, nil, err)
+	assertEqual(t, 2, budget.InUse())
+
+	// The other pool cannot reserve units while the budget is exhausted
+	assertEqual(t, false, pool2.TryAcquire(1))
+
+	pool1.Release(2)
+	assertEqual(t, true, pool2.TryAcquire(1))
+	pool2.Release(1)
+}
+
+func TestConcurrencyBudgetWithInvalidTotal(t *testing.T) {
+
+	budget := pond.NewConcurrencyBudget(0)
+
+	assertEqual(t, 1, budget.Total())
+}
+
+func TestConcurrencyBudgetHierarchy(t *testing.T) {
+
+	parent := pond.NewConcurrencyBudget(10)
+	childA := parent.Child(70)
+	childB := parent.Child(30)
+
+	assertEqual(t, 10, parent.Guaranteed())
+	assertEqual(t, 7, childA.Guaranteed())
+	assertEqual(t, 3, childB.Guaranteed())
+	assertEqual(t, 10, childB.Total())
+
+	poolA := pond.New(10, 10, pond.Budget(childA))
+	poolB := pond.New(20, 10, pond.Budget(childB))
+	defer poolA.StopAndWait()
+	defer poolB.StopAndWait()
+
+	// Child B can borrow the whole budget while child A is idle
+	assertEqual(t, true, poolB.TryAcquire(10))
+	assertEqual(t, 10, childB.InUse())
+	assertEqual(t, 10, parent.InUse())
+
+	// Child B asks for units beyond its share first, then child A asks for units within its share
+	acquiredB := make(chan struct{})
+	go func() {
+		poolB.Acquire(context.Background(), 4)
+		close(acquiredB)
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	acquiredA := make(chan struct{})
+	go func() {
+		poolA.Acquire(context.Background(), 7)
+		close(acquiredA)
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	// Child A is served first because it is within its share
+	poolB.Release(10)
+	<-acquiredA
+	assertEqual(t, 7, childA.InUse())
+
+	select {
+	case <-acquiredB:
+		t.Fatal("Child B should not be able to borrow units reserved for child A")
+	case <-time.After(5 * time.Millisecond):
+	}
+
+	poolA.Release(7)
+	<-acquiredB
+	assertEqual(t, 4, childB.InUse())
+	poolB.Release(4)
+	assertEqual(t, 0, parent.InUse())
+}
@@ -0,0 +1,20 @@
+package pond
+
+import "context"
+
+// WorkerBuffer returns a scratch buffer of the given size that belongs to the worker running the task the given
+// context was passed to (see SubmitContext). The buffer is reused by the tasks that run on the same worker, which
+// avoids allocating large temporary byte slices for each task, so its content is undefined and it must not be used
+// once the task returns. It grows as needed and it's released when the worker exits.
+// It must be called from the task's goroutine. If ctx doesn't belong to a running task, a new buffer is allocated.
+func WorkerBuffer(ctx context.Context, size int) []byte {
+	state := runningWorkerState(ctx)
+	if state == nil {
+		return make([]byte, size)
+	}
+
+	if cap(state.buffer) < size {
+		state.buffer = make([]byte, size)
+	}
+	return state.buffer[:size]
+}
@@ -0,0 +1,73 @@
+package pond_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestWorkerBuffer(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	var first, second, third []byte
+	pool.SubmitContext(func(ctx context.Context) {
+		first = pond.WorkerBuffer(ctx, 1024)
+		first[0] = 1
+	})
+	pool.SubmitContext(func(ctx context.Context) {
+		second = pond.WorkerBuffer(ctx, 512)
+	})
+	pool.SubmitContext(func(ctx context.Context) {
+		third = pond.WorkerBuffer(ctx, 2048)
+	})
+
+	pool.StopAndWait()
+
+	assertEqual(t, 1024, len(first))
+	assertEqual(t, 512, len(second))
+	assertEqual(t, 2048, len(third))
+
+	// The buffer is reused by the tasks of the same worker, as long as it's large enough
+	assertEqual(t, &first[0], &second[0])
+	assertEqual(t, byte(1), second[0])
+	assertEqual(t, false, &first[0] == &third[0])
+}
+
+func TestWorkerBufferOutsideOfTask(t *testing.T) {
+
+	first := pond.WorkerBuffer(context.Background(), 16)
+	second := pond.WorkerBuffer(context.Background(), 16)
+
+	assertEqual(t, 16, len(first))
+	assertEqual(t, false, &first[0] == &second[0])
+}
+
+func TestWorkerBufferWhileYielding(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.Lanes(0))
+
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+	var batch, interactive []byte
+
+	pool.SubmitBatchContext(func(ctx context.Context) {
+		batch = pond.WorkerBuffer(ctx, 64)
+		close(started)
+		<-proceed
+		pond.Yield(ctx)
+	})
+	<-started
+
+	pool.SubmitContext(func(ctx context.Context) {
+		interactive = pond.WorkerBuffer(ctx, 64)
+	})
+	close(proceed)
+
+	pool.StopAndWait()
+
+	// The interactive task ran while the batch task was paused, so it must not get the same buffer
+	assertEqual(t, 64, len(interactive))
+	assertEqual(t, false, &batch[0] == &interactive[0])
+}
@@ -0,0 +1,159 @@
+package pond
+
+import (
+	"context"
+	"time"
+)
+
+// TaskBuilder describes a task to submit to a worker pool with options, as a fluent alternative to SubmitTask.
+// Each method returns a copy of the builder with the option set, so builders can be shared and reused.
+type TaskBuilder struct {
+	pool *WorkerPool
+	task Task
+}
+
+// Task returns a builder of a task that runs fn on this pool, e.g.
+// pool.Task(fn).WithLabel("sync").WithTimeout(2 * time.Second).WithRetries(3).Submit()
+func (p *WorkerPool) Task(fn func(ctx context.Context) error) TaskBuilder {
+	return TaskBuilder{
+		pool: p,
+		task: Task{Fn: fn},
+	}
+}
+
+// WithLabel sets the label of the task (see Task.Label)
+func (b TaskBuilder) WithLabel(label string) TaskBuilder {
+	b.task.Label = label
+	return b
+}
+
+// WithSubmitter sets who submits the task (see Task.Submitter)
+func (b TaskBuilder) WithSubmitter(submitter string) TaskBuilder {
+	b.task.Submitter = submitter
+	return b
+}
+
+// WithKey sets the key that serializes the task with the others that share it (see Task.Key)
+func (b TaskBuilder) WithKey(key string) TaskBuilder {
+	b.task.Key = key
+	return b
+}
+
+// WithPriority sets where the task is queued (see Task.Priority)
+func (b TaskBuilder) WithPriority(priority Priority) TaskBuilder {
+	b.task.Priority = priority
+	return b
+}
+
+// WithDeadline sets the time by which the task must start executing (see Task.Deadline)
+func (b TaskBuilder) WithDeadline(deadline time.Time) TaskBuilder {
+	b.task.Deadline = deadline
+	return b
+}
+
+// WithTimeout sets how long each attempt of the task can run (see Task.Timeout)
+func (b TaskBuilder) WithTimeout(timeout time.Duration) TaskBuilder {
+	b.task.Timeout = timeout
+	return b
+}
+
+// WithRetries sets the number of times the task is retried if it returns an error (see Task.Retries)
+func (b TaskBuilder) WithRetries(retries int) TaskBuilder {
+	b.task.Retries = retries
+	return b
+}
+
+// WithBackoff sets the delay before the first retry of the task, which is doubled after each retry (see Task.Backoff)
+func (b TaskBuilder) WithBackoff(backoff time.Duration) TaskBuilder {
+	b.task.Backoff = backoff
+	return b
+}
+
+// Submit sends the task to the pool, just like SubmitTask, and returns a Future that holds its outcome
+func (b TaskBuilder) Submit() *Future[struct{}] {
+	return b.pool.SubmitTask(b.task)
+}
+
+// GroupBuilder describes a task group to create on a worker pool, so that combinations of group options (context,
+// timeout, limits, error policy, label) compose without a constructor per combination. Each method returns a copy of
+// the builder with the option set, so builders can be shared and reused.
+type GroupBuilder struct {
+	pool    *WorkerPool
+	ctx     context.Context
+	timeout time.Duration
+	label   string
+	options []GroupOption
+}
+
+// NewGroup returns a builder of a task group on this pool, e.g.
+// pool.NewGroup().WithContext(ctx).WithMaxConcurrency(8).WithCollectAllErrors().Build()
+func (p *WorkerPool) NewGroup() GroupBuilder {
+	return GroupBuilder{
+		pool: p,
+	}
+}
+
+// WithContext sets the context the group's context is derived from (see GroupContext). It defaults to the pool's
+// base context (see BaseContext).
+func (b GroupBuilder) WithContext(ctx context.Context) GroupBuilder {
+	b.ctx = ctx
+	return b
+}
+
+// WithTimeout makes the group's context expire after the given timeout (see GroupWithTimeout)
+func (b GroupBuilder) WithTimeout(timeout time.Duration) GroupBuilder {
+	b.timeout = timeout
+	return b
+}
+
+// WithLabel sets the label attached to the tasks of the group (see TaskGroup.SetLabel)
+func (b GroupBuilder) WithLabel(label string) GroupBuilder {
+	b.label = label
+	return b
+}
+
+// WithMaxConcurrency limits the number of tasks of the group running at the same time (see MaxConcurrency)
+func (b GroupBuilder) WithMaxConcurrency(tasks int) GroupBuilder {
+	return b.With(MaxConcurrency(tasks))
+}
+
+// WithRate limits the rate at which the tasks of the group are submitted (see RateLimit)
+func (b GroupBuilder) WithRate(rps float64) GroupBuilder {
+	return b.With(RateLimit(rps))
+}
+
+// WithRetry makes the group retry the tasks that return an error (see Retry)
+func (b GroupBuilder) WithRetry(policy RetryPolicy) GroupBuilder {
+	return b.With(Retry(policy))
+}
+
+// WithCollectAllErrors makes the group retain the errors of all of its tasks (see CollectErrors)
+func (b GroupBuilder) WithCollectAllErrors() GroupBuilder {
+	return b.With(CollectErrors())
+}
+
+// With adds the given options to the group
+func (b GroupBuilder) With(options ...GroupOption) GroupBuilder {
+	// Copy the options so the ones of other copies of the builder are not overwritten
+	b.options = append(b.options[:len(b.options):len(b.options)], options...)
+	return b
+}
+
+// Build creates the group, returning it along with its context
+func (b GroupBuilder) Build() (*TaskGroupWithContext, context.Context) {
+	parent := b.ctx
+	if parent == nil {
+		parent = b.pool.baseContext
+	}
+
+	var group *TaskGroupWithContext
+	var ctx context.Context
+	if b.timeout > 0 {
+		group, ctx = b.pool.groupWithTimeout(parent, b.timeout, b.options...)
+	} else {
+		group, ctx = b.pool.GroupContext(parent, b.options...)
+	}
+	group.SetLabel(b.label)
+
+	return group, ctx
+}
@@ -0,0 +1,104 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestTaskBuilder(t *testing.T) {
+
+	pool := pond.New(1, 10)
+	defer pool.StopAndWait()
+
+	errFlaky := errors.New("flaky")
+	var infos []pond.TaskInfo
+	builder := pool.Task(func(ctx context.Context) error {
+		info, _ := pond.FromContext(ctx)
+		infos = append(infos, info)
+		if info.Attempt < 2 {
+			return errFlaky
+		}
+		return nil
+	}).WithLabel("sync").WithSubmitter("alice").WithKey("alice").WithTimeout(time.Second)
+
+	_, err := builder.WithRetries(3).WithBackoff(time.Millisecond).Submit().Wait()
+	assertEqual(t, nil, err)
+	assertEqual(t, 2, len(infos))
+	assertEqual(t, "sync", infos[1].Label)
+	assertEqual(t, "alice", infos[1].Submitter)
+
+	// The builder is not modified by the options set on its copies
+	_, err = builder.Submit().Wait()
+	assertEqual(t, errFlaky, err)
+}
+
+func TestGroupBuilder(t *testing.T) {
+
+	pool := pond.New(10, 100)
+	defer pool.StopAndWait()
+
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errFailed := errors.New("failed")
+	group, ctx := pool.NewGroup().
+		WithContext(parent).
+		WithLabel("fanout").
+		WithMaxConcurrency(2).
+		WithCollectAllErrors().
+		Build()
+
+	var running, maxRunning int32
+	var labels sync.Map
+	for i := 0; i < 10; i++ {
+		group.SubmitContext(func(ctx context.Context) error {
+			current := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+					break
+				}
+			}
+			info, _ := pond.FromContext(ctx)
+			labels.Store(info.Label, true)
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+	}
+	group.Submit(func() error {
+		return errFailed
+	})
+
+	err := group.Wait()
+	var groupErr *pond.GroupError
+	assertEqual(t, true, errors.As(err, &groupErr))
+	assertEqual(t, 1, len(group.Errors()))
+	assertEqual(t, true, atomic.LoadInt32(&maxRunning) <= 2)
+	_, labeled := labels.Load("fanout")
+	assertEqual(t, true, labeled)
+	assertEqual(t, context.Canceled, ctx.Err())
+}
+
+func TestGroupBuilderTimeout(t *testing.T) {
+
+	pool := pond.New(1, 10)
+	defer pool.StopAndWait()
+
+	builder := pool.NewGroup().WithTimeout(10 * time.Millisecond)
+	group, ctx := builder.Build()
+
+	group.SubmitContext(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	assertEqual(t, context.DeadlineExceeded, group.Wait())
+	assertEqual(t, context.DeadlineExceeded, ctx.Err())
+}
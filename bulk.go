@@ -0,0 +1,99 @@
+package pond
+
+import (
+	"sync/atomic"
+)
+
+// SubmitAll sends the given tasks to this worker pool for execution, just like calling Submit for each of them,
+// but amortizing the cost of submission: the tasks are allocated together, the pool's counters are updated once,
+// and the tasks that don't start new workers are handed to idle workers or queued under a single lock.
+// It's meant to fan out thousands of small tasks at once. If the queue gets full, it waits until all the tasks
+// are queued. Nil tasks are ignored.
+func (p *WorkerPool) SubmitAll(tasks []func()) {
+	p.submitAll(newQueuedTasks(tasks))
+}
+
+// submitAll submits the given tasks, which must not be nil, waiting for room in the queue if needed.
+// It panics if the pool is stopped before all of them are queued. Tasks vetoed by submit hooks are rejected.
+func (p *WorkerPool) submitAll(tasks []*queuedTask) {
+	if len(tasks) == 0 {
+		return
+	}
+
+	if p.Stopped() {
+		for _, task := range tasks {
+			p.dropTask(DropStopped, task.info)
+		}
+		panic(ErrSubmitOnStoppedPool)
+	}
+
+	if p.callSiteDepth > 0 {
+		callSite := captureCallSite(p.callSiteDepth)
+		for _, task := range tasks {
+			if task.info.SubmittedFrom == "" {
+				task.info.SubmittedFrom = callSite
+			}
+		}
+	}
+
+	for _, task := range tasks {
+		task.info.Pool, task.info.Tags = p.name, p.tags
+	}
+	if p.deterministic != nil {
+		now := p.now()
+		for _, task := range tasks {
+			task.info.SubmittedAt = now
+		}
+	}
+
+	if len(p.submitHooks) > 0 {
+		admitted := tasks[:0:0]
+		for _, task := range tasks {
+			if p.runSubmitHooks(task) == nil {
+				admitted = append(admitted, task)
+			}
+		}
+		if tasks = admitted; len(tasks) == 0 {
+			return
+		}
+	}
+
+	// Account for all the tasks at once
+	count := uint64(len(tasks))
+	atomic.AddUint64(&p.submittedTaskCount.value, count)
+	atomic.AddUint64(&p.waitingTaskCount.value, count)
+	for _, task := range tasks {
+		p.lanes.submit(task.info.Lane)
+	}
+	p.tasksWaitGroup.Add(len(tasks))
+
+	// Start workers for the first tasks as long as we haven't reached the limit
+	started := 0
+	for _, task := range tasks {
+		if !p.tasks.Admit(task) {
+			break
+		}
+		if !p.maybeStartWorker(task) {
+			p.tasks.Release(task)
+			break
+		}
+		started++
+	}
+
+	// Queue the rest, waiting for room if needed
+	submitted := started + p.tasks.PushAll(tasks[started:])
+
+	now := p.now()
+	for i, task := range tasks {
+		if i >= submitted {
+			p.unsubmit(task)
+		}
+		p.health.recordSubmission(i >= submitted, now)
+	}
+	p.updatePressure()
+
+	if submitted < len(tasks) {
+		// Queue was closed while waiting, which means the pool was stopped
+		panic(ErrSubmitOnStoppedPool)
+	}
+}
@@ -0,0 +1,99 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestSubmitAll(t *testing.T) {
+
+	pool := pond.New(4, 10)
+
+	var done int32
+	tasks := make([]func(), 100)
+	for i := range tasks {
+		tasks[i] = func() {
+			atomic.AddInt32(&done, 1)
+		}
+	}
+	tasks[50] = nil
+
+	pool.SubmitAll(tasks)
+	pool.StopAndWait()
+
+	assertEqual(t, int32(99), atomic.LoadInt32(&done))
+	assertEqual(t, uint64(99), pool.SubmittedTasks())
+	assertEqual(t, uint64(99), pool.SuccessfulTasks())
+	assertEqual(t, uint64(0), pool.WaitingTasks())
+}
+
+func TestSubmitAllOnStoppedPool(t *testing.T) {
+
+	var dropped int
+	pool := pond.New(1, 10, pond.DroppedTaskHandler(func(reason pond.DropReason, info pond.TaskInfo) {
+		dropped++
+	}))
+	pool.StopAndWait()
+
+	var thrownPanic interface{}
+	func() {
+		defer func() {
+			thrownPanic = recover()
+		}()
+		pool.SubmitAll([]func(){func() {}, func() {}})
+	}()
+
+	assertEqual(t, pond.ErrSubmitOnStoppedPool, thrownPanic)
+	assertEqual(t, 2, dropped)
+	assertEqual(t, uint64(0), pool.SubmittedTasks())
+}
+
+func TestGroupSubmitAll(t *testing.T) {
+
+	pool := pond.New(4, 10)
+	defer pool.StopAndWait()
+
+	group := pool.Group()
+
+	var done int32
+	tasks := make([]func(), 50)
+	for i := range tasks {
+		tasks[i] = func() {
+			atomic.AddInt32(&done, 1)
+		}
+	}
+
+	group.SubmitAll(tasks)
+	group.Wait()
+
+	assertEqual(t, int32(50), atomic.LoadInt32(&done))
+}
+
+func TestGroupContextSubmitAll(t *testing.T) {
+
+	pool := pond.New(4, 10)
+	defer pool.StopAndWait()
+
+	group, _ := pool.GroupContext(context.Background(), pond.CollectErrors())
+
+	tasks := make([]func() error, 20)
+	for i := range tasks {
+		tasks[i] = func() error {
+			return nil
+		}
+	}
+	tasks[7] = func() error {
+		return errors.New("failed")
+	}
+
+	group.SubmitAll(tasks)
+	err := group.Wait()
+
+	var taskErr *pond.TaskError
+	assertEqual(t, true, errors.As(err, &taskErr))
+	assertEqual(t, 7, taskErr.Index)
+}
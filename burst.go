@@ -0,0 +1,58 @@
+package pond
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Burst temporarily raises the maximum number of workers of this pool by extraWorkers for the given duration,
+// e.g. during a known traffic spike or backfill. Once the duration elapses, the pool returns to its normal size
+// and the extra workers are retired as they become idle. Calling Burst again replaces the ongoing burst, and
+// calling it with extraWorkers or duration <= 0 ends it.
+func (p *WorkerPool) Burst(extraWorkers int, duration time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.burstTimer != nil {
+		p.burstTimer.Stop()
+		p.burstTimer = nil
+	}
+
+	if extraWorkers <= 0 || duration <= 0 || p.Stopped() || p.deterministic != nil {
+		p.setBurstWorkers(0)
+		return
+	}
+
+	p.setBurstWorkers(extraWorkers)
+
+	var timer *time.Timer
+	timer = time.AfterFunc(duration, func() {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+
+		// Ignore the timer if the burst was replaced in the meantime
+		if p.burstTimer != timer {
+			return
+		}
+		p.burstTimer = nil
+		p.setBurstWorkers(0)
+	})
+	p.burstTimer = timer
+}
+
+// BurstWorkers returns the number of extra workers the pool is currently allowed to run because of Burst
+func (p *WorkerPool) BurstWorkers() int {
+	return int(atomic.LoadInt32(&p.burstWorkers))
+}
+
+// setBurstWorkers changes the number of extra workers and resizes the semaphore accordingly.
+// Must be called with the pool mutex held.
+func (p *WorkerPool) setBurstWorkers(extraWorkers int) {
+	atomic.StoreInt32(&p.burstWorkers, int32(extraWorkers))
+	p.semaphore.Resize(int64(p.maxWorkers + extraWorkers))
+}
+
+// effectiveMaxWorkers returns the maximum number of workers, including those allowed by an ongoing burst
+func (p *WorkerPool) effectiveMaxWorkers() int {
+	return p.maxWorkers + p.BurstWorkers()
+}
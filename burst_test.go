@@ -0,0 +1,57 @@
+package pond_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestBurst(t *testing.T) {
+
+	pool := pond.New(2, 100, pond.IdleTimeout(1*time.Millisecond))
+
+	pool.Burst(3, 100*time.Millisecond)
+	assertEqual(t, 3, pool.BurstWorkers())
+
+	release := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		pool.Submit(func() {
+			<-release
+		})
+	}
+
+	// The pool can temporarily run up to 5 workers
+	assertEqual(t, 5, pool.RunningWorkers())
+	close(release)
+
+	// Once the burst is over, the pool returns to its normal size
+	time.Sleep(200 * time.Millisecond)
+	assertEqual(t, 0, pool.BurstWorkers())
+
+	release = make(chan struct{})
+	for i := 0; i < 10; i++ {
+		pool.Submit(func() {
+			<-release
+		})
+	}
+	assertEqual(t, 2, pool.RunningWorkers())
+	close(release)
+
+	pool.StopAndWait()
+
+	assertEqual(t, uint64(20), pool.CompletedTasks())
+}
+
+func TestBurstEndedEarly(t *testing.T) {
+
+	pool := pond.New(2, 100)
+
+	pool.Burst(3, time.Hour)
+	assertEqual(t, 3, pool.BurstWorkers())
+
+	pool.Burst(0, 0)
+	assertEqual(t, 0, pool.BurstWorkers())
+
+	pool.StopAndWait()
+}
@@ -0,0 +1,57 @@
+package pond
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// maxCallSiteDepth is the maximum number of frames recorded by CaptureCallSite
+const maxCallSiteDepth = 32
+
+// pondFunctionPrefix is the prefix of the names of the functions of this package, which are skipped when
+// capturing call sites
+var pondFunctionPrefix = reflect.TypeOf(WorkerPool{}).PkgPath() + "."
+
+// CaptureCallSite makes the pool record the call site that submitted each task in TaskInfo.SubmittedFrom,
+// up to the given number of stack frames (at most 32), so that panic reports and event listeners can trace
+// anonymous closures back to their source. Capturing the call site has a cost, so it's disabled by default.
+func CaptureCallSite(depth int) Option {
+	return func(pool *WorkerPool) {
+		pool.callSiteDepth = depth
+	}
+}
+
+// captureCallSite returns the given number of stack frames of the caller, starting at the first frame that
+// doesn't belong to this package, formatted as "function\n\tfile:line" lines.
+// It returns an empty string if the task was submitted by this package itself (e.g. by a background goroutine).
+func captureCallSite(depth int) string {
+	pcs := make([]uintptr, maxCallSiteDepth+16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var builder strings.Builder
+	recorded := 0
+	for recorded < depth {
+		frame, more := frames.Next()
+		if recorded == 0 && strings.HasPrefix(frame.Function, pondFunctionPrefix) {
+			if !more {
+				break
+			}
+			continue
+		}
+		if frame.Function == "runtime.goexit" {
+			break
+		}
+		if recorded > 0 {
+			builder.WriteByte('\n')
+		}
+		fmt.Fprintf(&builder, "%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+		recorded++
+		if !more {
+			break
+		}
+	}
+	return builder.String()
+}
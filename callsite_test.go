@@ -0,0 +1,58 @@
+package pond_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestCaptureCallSite(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.CaptureCallSite(1))
+
+	var submittedFrom string
+	pool.SubmitContext(func(ctx context.Context) {
+		info, _ := pond.FromContext(ctx)
+		submittedFrom = info.SubmittedFrom
+	})
+	pool.StopAndWait()
+
+	// Only the test function is recorded, not the functions of the pond package
+	assertEqual(t, true, strings.HasPrefix(submittedFrom, "github.com/kraneware/pond_test.TestCaptureCallSite\n\t"))
+	assertEqual(t, true, strings.Contains(submittedFrom, "callsite_test.go:"))
+	assertEqual(t, 1, strings.Count(submittedFrom, "\n\t"))
+}
+
+func TestCaptureCallSiteFromGroup(t *testing.T) {
+
+	var submittedFrom string
+	pool := pond.New(1, 10, pond.CaptureCallSite(2), pond.Events(pond.EventListener{
+		OnTaskFinished: func(info pond.TaskInfo, panic interface{}) {
+			submittedFrom = info.SubmittedFrom
+		},
+	}))
+
+	group := pool.Group()
+	group.Submit(func() {})
+	group.Wait()
+	pool.StopAndWait()
+
+	assertEqual(t, true, strings.HasPrefix(submittedFrom, "github.com/kraneware/pond_test.TestCaptureCallSiteFromGroup\n\t"))
+	assertEqual(t, 2, strings.Count(submittedFrom, "\n\t"))
+}
+
+func TestCaptureCallSiteDisabled(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	submittedFrom := "unset"
+	pool.SubmitContext(func(ctx context.Context) {
+		info, _ := pond.FromContext(ctx)
+		submittedFrom = info.SubmittedFrom
+	})
+	pool.StopAndWait()
+
+	assertEqual(t, "", submittedFrom)
+}
@@ -0,0 +1,22 @@
+package pond
+
+// CancelWhere removes the waiting tasks whose metadata matches the given predicate and returns how many were removed,
+// e.g. to evict the backlog of a misbehaving tenant or submitter without restarting the process. Tasks waiting in the
+// queue of a tenant (see SubmitTenant) are matched with the tenant as their submitter. Removed tasks are reported to
+// the dropped task handler with DropCancelled and complete with ErrTaskCancelled, so the groups and callers waiting
+// for them return, while tasks still blocked in a submission, waiting for room in the queue, are not matched.
+// The predicate is invoked while the queue is locked, so it must return quickly.
+func (p *WorkerPool) CancelWhere(match func(info TaskInfo) bool) int {
+	removed := p.tasks.Remove(func(task *queuedTask) bool {
+		// Runners of tenant tasks are matched against the tasks they would run below
+		return !task.internal && match(task.info)
+	})
+	for _, task := range removed {
+		p.discardQueued(task, DropCancelled)
+	}
+	if len(removed) > 0 {
+		p.updatePressure()
+	}
+
+	return len(removed) + p.tenants.cancel(match, DropCancelled)
+}
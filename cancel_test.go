@@ -0,0 +1,134 @@
+package pond_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestCancelWhere(t *testing.T) {
+
+	var cancelled int32
+	pool := pond.New(1, 10, pond.MinWorkers(1), pond.DroppedTaskHandler(func(reason pond.DropReason, info pond.TaskInfo) {
+		if reason == pond.DropCancelled {
+			atomic.AddInt32(&cancelled, 1)
+		}
+	}))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var ran int32
+	for i := 0; i < 3; i++ {
+		pool.SubmitLabeled("spam", func() {
+			atomic.AddInt32(&ran, 100)
+		})
+		pool.SubmitLabeled("mail", func() {
+			atomic.AddInt32(&ran, 1)
+		})
+	}
+	assertEqual(t, uint64(6), pool.WaitingTasks())
+
+	removed := pool.CancelWhere(func(info pond.TaskInfo) bool {
+		return info.Label == "spam"
+	})
+	assertEqual(t, 3, removed)
+	assertEqual(t, uint64(3), pool.WaitingTasks())
+	assertEqual(t, 3, len(pool.QueueSnapshot(0)))
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, int32(3), atomic.LoadInt32(&ran))
+	assertEqual(t, int32(3), atomic.LoadInt32(&cancelled))
+	assertEqual(t, uint64(4), pool.CompletedTasks())
+}
+
+func TestCancelWhereTenant(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.DefaultTenantQuota(pond.TenantQuota{MaxConcurrency: 1}))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.SubmitTenant("noisy", func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var ran int32
+	for i := 0; i < 5; i++ {
+		pool.SubmitTenant("noisy", func() {
+			atomic.AddInt32(&ran, 1)
+		})
+	}
+	pool.SubmitTenant("quiet", func() {
+		atomic.AddInt32(&ran, 10)
+	})
+
+	removed := pool.CancelWhere(func(info pond.TaskInfo) bool {
+		return info.Submitter == "noisy"
+	})
+	assertEqual(t, 5, removed)
+	assertEqual(t, 0, pool.TenantStats("noisy").Waiting)
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, int32(10), atomic.LoadInt32(&ran))
+}
+
+func TestCancelWhereCompletesWaiters(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	group := pool.Group()
+	group.Submit(func() {})
+
+	errGroup, _ := pond.NewErrGroup(context.Background(), pool)
+	errGroup.Go(func() error {
+		return nil
+	})
+
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		pool.SubmitAndWait(func() {})
+	}()
+	for pool.WaitingTasks() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	assertEqual(t, 3, pool.CancelWhere(func(info pond.TaskInfo) bool {
+		return true
+	}))
+
+	assertReturns(t, group.Wait)
+	var err error
+	assertReturns(t, func() {
+		err = errGroup.Wait()
+	})
+	assertEqual(t, pond.ErrTaskCancelled, err)
+	assertReturns(t, func() {
+		<-returned
+	})
+
+	close(release)
+	pool.StopAndWait()
+}
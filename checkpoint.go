@@ -0,0 +1,87 @@
+package pond
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// taskCheckpoint holds the metadata of a running task that reported a checkpoint, along with its latest state
+type taskCheckpoint struct {
+	info  TaskInfo
+	mutex sync.Mutex
+}
+
+// Checkpoint records the progress of the running task the given context was passed to (see SubmitContext),
+// e.g. the offset of the last processed record of a multi-minute job. The state is reported in TaskInfo.Checkpoint
+// to handlers and event listeners, and by Checkpoints while the task runs. It's also handed over to the next
+// attempt of a task group's task that is retried with RetryPolicy.ResumeFromCheckpoint, so it can resume from there.
+// Checkpoint can be called from any goroutine and has no effect if ctx doesn't belong to a running task.
+func Checkpoint(ctx context.Context, state interface{}) {
+	task := taskFromContext(ctx)
+	if task == nil || task.pool == nil {
+		return
+	}
+
+	value, _ := task.pool.checkpoints.LoadOrStore(task.info.ID, &taskCheckpoint{info: task.info})
+	checkpoint := value.(*taskCheckpoint)
+
+	checkpoint.mutex.Lock()
+	checkpoint.info.Checkpoint = state
+	checkpoint.mutex.Unlock()
+
+	atomic.StoreInt32(&task.checkpointed, 1)
+}
+
+// Checkpoints returns the metadata of the running tasks that reported their progress with Checkpoint,
+// each one with its latest state, ordered by task ID. It lets operators follow long-running jobs,
+// e.g. by serving it from a debug endpoint.
+func (p *WorkerPool) Checkpoints() []TaskInfo {
+	var tasks []TaskInfo
+
+	p.checkpoints.Range(func(_, value interface{}) bool {
+		checkpoint := value.(*taskCheckpoint)
+
+		checkpoint.mutex.Lock()
+		tasks = append(tasks, checkpoint.info)
+		checkpoint.mutex.Unlock()
+
+		return true
+	})
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].ID < tasks[j].ID
+	})
+	return tasks
+}
+
+// lastCheckpoint returns the latest state reported by the given task, or the one it was submitted with if it
+// didn't report any
+func (p *WorkerPool) lastCheckpoint(task *queuedTask) interface{} {
+	if atomic.LoadInt32(&task.checkpointed) == 0 {
+		return task.info.Checkpoint
+	}
+
+	value, ok := p.checkpoints.Load(task.info.ID)
+	if !ok {
+		return task.info.Checkpoint
+	}
+	checkpoint := value.(*taskCheckpoint)
+
+	checkpoint.mutex.Lock()
+	defer checkpoint.mutex.Unlock()
+
+	return checkpoint.info.Checkpoint
+}
+
+// clearCheckpoint stores the latest state reported by the given task in its metadata and stops reporting it
+// as running. Must be called by the worker once the task returned.
+func (p *WorkerPool) clearCheckpoint(task *queuedTask) {
+	if atomic.LoadInt32(&task.checkpointed) == 0 {
+		return
+	}
+
+	task.info.Checkpoint = p.lastCheckpoint(task)
+	p.checkpoints.Delete(task.info.ID)
+}
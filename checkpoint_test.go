@@ -0,0 +1,113 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestCheckpoint(t *testing.T) {
+
+	var finished pond.TaskInfo
+	pool := pond.New(1, 10, pond.Events(pond.EventListener{
+		OnTaskFinished: func(info pond.TaskInfo, panic interface{}) {
+			finished = info
+		},
+	}))
+
+	checkpointed := make(chan struct{})
+	release := make(chan struct{})
+
+	pool.SubmitContext(func(ctx context.Context) {
+		pond.Checkpoint(ctx, 10)
+		pond.Checkpoint(ctx, 20)
+		close(checkpointed)
+		<-release
+		pond.Checkpoint(ctx, 30)
+	})
+	<-checkpointed
+
+	running := pool.Checkpoints()
+	assertEqual(t, 1, len(running))
+	assertEqual(t, 20, running[0].Checkpoint)
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, 30, finished.Checkpoint)
+	assertEqual(t, 0, len(pool.Checkpoints()))
+}
+
+func TestCheckpointOutsideOfTask(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	pond.Checkpoint(context.Background(), 10)
+
+	assertEqual(t, 0, len(pool.Checkpoints()))
+}
+
+func TestGroupRetryResumesFromCheckpoint(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	group, _ := pool.GroupContext(context.Background(), pond.Retry(pond.RetryPolicy{
+		MaxAttempts:          3,
+		ResumeFromCheckpoint: true,
+	}))
+
+	var starts []int
+	group.SubmitContext(func(ctx context.Context) error {
+		info, _ := pond.FromContext(ctx)
+
+		start := 0
+		if info.Checkpoint != nil {
+			start = info.Checkpoint.(int)
+		}
+		starts = append(starts, start)
+
+		for i := start; i < 10; i++ {
+			if i == start+4 {
+				return errors.New("interrupted")
+			}
+			pond.Checkpoint(ctx, i)
+		}
+		return nil
+	})
+
+	err := group.Wait()
+	pool.StopAndWait()
+
+	assertEqual(t, nil, err)
+	assertEqual(t, 3, len(starts))
+	assertEqual(t, 0, starts[0])
+	assertEqual(t, 3, starts[1])
+	assertEqual(t, 6, starts[2])
+}
+
+func TestGroupRetryWithoutResume(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	group, _ := pool.GroupContext(context.Background(), pond.Retry(pond.RetryPolicy{
+		MaxAttempts: 2,
+	}))
+
+	var checkpoints []interface{}
+	group.SubmitContext(func(ctx context.Context) error {
+		info, _ := pond.FromContext(ctx)
+		checkpoints = append(checkpoints, info.Checkpoint)
+
+		pond.Checkpoint(ctx, info.Attempt)
+		return errors.New("failed")
+	})
+
+	err := group.Wait()
+	pool.StopAndWait()
+
+	assertEqual(t, "failed", err.Error())
+	assertEqual(t, 2, len(checkpoints))
+	assertEqual(t, nil, checkpoints[1])
+}
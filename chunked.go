@@ -0,0 +1,72 @@
+package pond
+
+import "sync"
+
+// splitChunks splits the given items in contiguous chunks of the given size (the last one may be shorter)
+func splitChunks[T any](items []T, chunkSize int) [][]T {
+	chunks := make([][]T, 0, (len(items)+chunkSize-1)/chunkSize)
+	for len(items) > chunkSize {
+		chunks = append(chunks, items[:chunkSize:chunkSize])
+		items = items[chunkSize:]
+	}
+	if len(items) > 0 {
+		chunks = append(chunks, items)
+	}
+	return chunks
+}
+
+// MapChunked splits the given items in contiguous chunks of chunkSize items (the last one may be shorter) and
+// processes each chunk as a single task on the worker pool, which keeps the per-task overhead low when processing
+// large numbers of tiny items. It waits for all chunks to be processed and returns a GroupError holding a TaskError
+// per failed chunk (whose Index is the position of the chunk), or nil if all chunks succeeded.
+// A chunkSize lower than 1 is treated as 1.
+func MapChunked[T any](pool *WorkerPool, items []T, chunkSize int, fn func([]T) error) error {
+
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+
+	var waitGroup sync.WaitGroup
+	var mutex sync.Mutex
+	var errs []error
+
+	fail := func(index int, err error) {
+		mutex.Lock()
+		errs = append(errs, &TaskError{Index: index, Err: err})
+		mutex.Unlock()
+	}
+
+	chunks := splitChunks(items, chunkSize)
+	waitGroup.Add(len(chunks))
+	for i, chunk := range chunks {
+		i, chunk := i, chunk
+		pool.submitOrReject(func() {
+			defer waitGroup.Done()
+
+			completed := false
+			defer func() {
+				if !completed {
+					// Report the panic as the error of the chunk and let the pool handle it
+					p := recover()
+					fail(i, newPanicError(p))
+					panic(p)
+				}
+			}()
+
+			if err := fn(chunk); err != nil {
+				fail(i, err)
+			}
+			completed = true
+		}, func(err error) {
+			fail(i, err)
+			waitGroup.Done()
+		})
+	}
+
+	waitGroup.Wait()
+
+	if len(errs) > 0 {
+		return &GroupError{Errors: errs}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package pond_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestMapChunked(t *testing.T) {
+
+	pool := pond.New(4, 100)
+	defer pool.StopAndWait()
+
+	items := make([]int64, 10000)
+	for i := range items {
+		items[i] = 1
+	}
+
+	var sum int64
+	err := pond.MapChunked(pool, items, 1000, func(chunk []int64) error {
+		for _, item := range chunk {
+			atomic.AddInt64(&sum, item)
+		}
+		return nil
+	})
+
+	assertEqual(t, nil, err)
+	assertEqual(t, int64(10000), sum)
+	assertEqual(t, uint64(10), pool.SubmittedTasks())
+}
+
+func TestMapChunkedWithErrors(t *testing.T) {
+
+	pool := pond.New(4, 100)
+	defer pool.StopAndWait()
+
+	sampleErr := errors.New("sample error")
+	err := pond.MapChunked(pool, []int{1, 2, 3, 4, 5}, 2, func(chunk []int) error {
+		if chunk[0] == 5 {
+			return sampleErr
+		}
+		return nil
+	})
+
+	assertEqual(t, true, errors.Is(err, sampleErr))
+	assertEqual(t, "1 tasks failed: task 2: sample error", err.Error())
+}
+
+func TestMapChunkedWithoutItems(t *testing.T) {
+
+	pool := pond.New(4, 100)
+	defer pool.StopAndWait()
+
+	err := pond.MapChunked(pool, nil, 0, func(chunk []int) error {
+		return errors.New("unexpected call")
+	})
+
+	assertEqual(t, nil, err)
+}
@@ -0,0 +1,58 @@
+package pond
+
+import (
+	"context"
+	"sync"
+)
+
+// taskCleanups holds the cleanup functions registered by a running task
+type taskCleanups struct {
+	funcs []func()
+	done  bool
+	mutex sync.Mutex
+}
+
+// OnTaskDone registers a function that the worker calls once the running task the given context was passed to
+// (see SubmitContext) returns or panics, so the task's resources are released even if it panics, without writing
+// a chain of defer statements. Functions are called in the reverse order of their registration, like deferred calls,
+// and a panic raised by one of them is reported to the panic handler without preventing the others from running.
+// It returns false, without registering the function, if ctx doesn't belong to a running task or the task is done.
+func OnTaskDone(ctx context.Context, f func()) bool {
+	task := taskFromContext(ctx)
+	if task == nil || f == nil {
+		return false
+	}
+
+	task.cleanups.mutex.Lock()
+	defer task.cleanups.mutex.Unlock()
+
+	if task.cleanups.done {
+		return false
+	}
+	task.cleanups.funcs = append(task.cleanups.funcs, f)
+	return true
+}
+
+// runCleanups calls the cleanup functions registered by the given task, in reverse order
+func (p *WorkerPool) runCleanups(task *queuedTask) {
+	task.cleanups.mutex.Lock()
+	funcs := task.cleanups.funcs
+	task.cleanups.funcs = nil
+	task.cleanups.done = true
+	task.cleanups.mutex.Unlock()
+
+	for i := len(funcs) - 1; i >= 0; i-- {
+		p.runCleanup(funcs[i], task.info)
+	}
+}
+
+// runCleanup calls the given cleanup function, reporting its panic to the panic handler if it raises one
+func (p *WorkerPool) runCleanup(f func(), info TaskInfo) {
+	defer func() {
+		if panic := recover(); panic != nil {
+			p.handlePanic(panic, info)
+		}
+	}()
+
+	f()
+}
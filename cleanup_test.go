@@ -0,0 +1,73 @@
+package pond_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestOnTaskDone(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	var calls []string
+	var registered bool
+	pool.SubmitContext(func(ctx context.Context) {
+		registered = pond.OnTaskDone(ctx, func() {
+			calls = append(calls, "first")
+		})
+		pond.OnTaskDone(ctx, func() {
+			calls = append(calls, "second")
+		})
+		calls = append(calls, "task")
+	})
+
+	pool.StopAndWait()
+
+	assertEqual(t, true, registered)
+	assertEqual(t, "task,second,first", strings.Join(calls, ","))
+}
+
+func TestOnTaskDoneWhenTaskPanics(t *testing.T) {
+
+	var panics []interface{}
+	pool := pond.New(1, 10, pond.PanicHandler(func(p interface{}) {
+		panics = append(panics, p)
+	}))
+
+	var calls []string
+	pool.SubmitContext(func(ctx context.Context) {
+		pond.OnTaskDone(ctx, func() {
+			calls = append(calls, "first")
+		})
+		pond.OnTaskDone(ctx, func() {
+			calls = append(calls, "second")
+			panic("cleanup failed")
+		})
+		panic("task failed")
+	})
+
+	pool.StopAndWait()
+
+	assertEqual(t, "second,first", strings.Join(calls, ","))
+	assertEqual(t, 2, len(panics))
+	assertEqual(t, "cleanup failed", panics[0])
+	assertEqual(t, "task failed", panics[1])
+	assertEqual(t, uint64(1), pool.FailedTasks())
+}
+
+func TestOnTaskDoneOutsideOfTask(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	var taskCtx context.Context
+	pool.SubmitContext(func(ctx context.Context) {
+		taskCtx = ctx
+	})
+	pool.StopAndWait()
+
+	assertEqual(t, false, pond.OnTaskDone(context.Background(), func() {}))
+	assertEqual(t, false, pond.OnTaskDone(taskCtx, func() {}))
+}
@@ -0,0 +1,82 @@
+package pond
+
+// CloneWith creates a new pool with the same configuration as this one (size, resizing strategy, handlers,
+// metrics sink, budget, etc.), with the given options applied on top, e.g. to create a family of similar pools
+// that only differ in size. The new pool has its own workers, queue and counters, and it doesn't inherit the
+// parent context given to the Context option, so it's not stopped along with this pool. If this pool records a trace
+// (see RecordTrace), the new pool records its own trace to the same writer.
+func (p *WorkerPool) CloneWith(options ...Option) *WorkerPool {
+	return New(p.maxWorkers, p.QueueCap(), append([]Option{p.inherit()}, options...)...)
+}
+
+// inherit returns an option that copies the configurable settings of this pool to another one
+func (p *WorkerPool) inherit() Option {
+	return func(pool *WorkerPool) {
+		pool.name = p.name
+		pool.tags = p.tags
+		pool.submitHooks = append([]SubmitHook(nil), p.submitHooks...)
+		pool.minWorkers = p.minWorkers
+		pool.idleTimeout = p.idleTimeout
+		pool.idleJitter = p.idleJitter
+		pool.keepWarm = p.keepWarm
+		pool.reservedWorkers = p.reservedWorkers
+		pool.cpus = append([]int(nil), p.cpus...)
+		pool.callSiteDepth = p.callSiteDepth
+		pool.strategy = p.strategy
+		pool.panicHandler = p.panicHandler
+		pool.queueOrder = p.queueOrder
+		pool.maxQueueAge = p.maxQueueAge
+		pool.expiredTaskHandler = p.expiredTaskHandler
+		pool.droppedTaskHandler = p.droppedTaskHandler
+		pool.reportInterval = p.reportInterval
+		pool.reporter = p.reporter
+		pool.events = p.events
+		pool.measureAllocations = p.measureAllocations
+		pool.budget = p.budget
+		pool.labels.maxLabels = p.labels.maxLabels
+		pool.tenants.defaultQuota = p.tenants.defaultQuota
+
+		// Resizing strategies may keep state, so preset ones are recreated rather than shared
+		if resizer, ok := p.strategy.(*ratedResizer); ok {
+			pool.strategy = RatedResizer(int(resizer.rate))
+		}
+
+		if p.trace != nil {
+			pool.trace = newTraceRecorder(p.trace.writer, p.trace.mutex)
+		}
+		if p.pressure != nil {
+			Backpressure(p.pressure.high, p.pressure.low, p.pressure.handler)(pool)
+		}
+		if p.metrics != nil {
+			Metrics(p.metrics.sink, p.metrics.interval)(pool)
+		}
+		if p.health != nil {
+			HealthCheck(p.health.criteria)(pool)
+		}
+		if p.scaling != nil {
+			Scaling(p.scaling.policy)(pool)
+		}
+		if p.utilization != nil {
+			TargetUtilization(p.utilization.target, p.utilization.window)(pool)
+		}
+		if p.starvation != nil {
+			StarvationAlert(p.starvation.threshold, p.starvation.handler)(pool)
+		}
+		if p.deterministic != nil {
+			Deterministic(p.deterministic.seed, p.deterministic.clock)(pool)
+		}
+		if p.adaptive != nil {
+			AdaptiveLimit(p.adaptive.requested)(pool)
+		}
+		if p.lanes != nil {
+			Lanes(p.lanes.interactivePerBatch)(pool)
+		}
+		if p.labelWeights != nil {
+			LabelWeights(p.labelWeights)(pool)
+		}
+		FireAndForget(p.forget.maxOutstanding, p.forget.overflow)(pool)
+		if p.submitters != nil {
+			MaxQueuedPerSubmitter(p.submitters.maxQueued)(pool)
+		}
+	}
+}
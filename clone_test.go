@@ -0,0 +1,72 @@
+package pond_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestCloneWith(t *testing.T) {
+
+	var panics []interface{}
+	pool := pond.New(10, 100,
+		pond.Name("images"),
+		pond.IdleTimeout(1*time.Second),
+		pond.QueueOrder(pond.LIFO),
+		pond.PanicHandler(func(p interface{}) {
+			panics = append(panics, p)
+		}),
+	)
+
+	clone := pool.CloneWith(pond.MaxWorkers(2), pond.MaxCapacity(5), pond.Name("thumbnails"))
+
+	config := clone.Options()
+	assertEqual(t, "thumbnails", config.Name)
+	assertEqual(t, 2, config.MaxWorkers)
+	assertEqual(t, 5, config.MaxCapacity)
+	assertEqual(t, 1*time.Second, config.IdleTimeout)
+	assertEqual(t, pond.LIFO, config.QueueOrder)
+	assertNotEqual(t, pool.Options().Strategy, config.Strategy)
+
+	clone.SubmitAndWait(func() {
+		panic("inherited handler")
+	})
+
+	assertEqual(t, uint64(0), pool.SubmittedTasks())
+
+	// Stopping the original pool doesn't stop the clone
+	pool.StopAndWait()
+
+	assertEqual(t, false, clone.Stopped())
+
+	clone.StopAndWait()
+
+	assertEqual(t, 1, len(panics))
+}
+
+func TestCloneWithTrace(t *testing.T) {
+
+	var trace bytes.Buffer
+	pool := pond.New(2, 10, pond.Name("images"), pond.RecordTrace(&trace))
+	clone := pool.CloneWith(pond.Name("thumbnails"))
+
+	for i := 0; i < 10; i++ {
+		pool.Submit(func() {})
+		clone.Submit(func() {})
+	}
+	pool.StopAndWait()
+	clone.StopAndWait()
+
+	records, err := pond.ReadTrace(&trace)
+	assertEqual(t, nil, err)
+	assertEqual(t, 20, len(records))
+
+	byPool := make(map[string]int)
+	for _, record := range records {
+		byPool[record.Pool]++
+	}
+	assertEqual(t, 10, byPool["images"])
+	assertEqual(t, 10, byPool["thumbnails"])
+}
@@ -0,0 +1,135 @@
+package pond
+
+import "time"
+
+// Config describes the effective configuration of a worker pool, after options were applied
+// and invalid values were replaced by their defaults
+type Config struct {
+	Name        string
+	Tags        map[string]string
+	MinWorkers  int
+	MaxWorkers  int
+	MaxCapacity int
+	IdleTimeout time.Duration
+	// IdleJitter is the fraction of the idle timeout by which worker retirement is randomized
+	IdleJitter float64
+	// KeepWarm is the number of workers that are never retired once started
+	KeepWarm   int
+	Strategy   ResizingStrategy
+	QueueOrder Order
+	// LabelWeights are the weights of weighted fair queuing between labels, or nil if it's disabled
+	LabelWeights map[string]int
+	MaxQueueAge  time.Duration
+	MaxLabels    int
+	// DefaultTenantQuota is the quota applied to tenants that do not have a specific one
+	DefaultTenantQuota TenantQuota
+	// Budget is the concurrency budget the pool draws from, or nil if it has none
+	Budget *ConcurrencyBudget
+	// BackpressureHigh and BackpressureLow are the backpressure watermarks, or 0 if backpressure is disabled
+	BackpressureHigh float64
+	BackpressureLow  float64
+	// HealthCriteria are the criteria used by Healthy, or nil if none were configured
+	HealthCriteria *HealthCriteria
+	// MetricsInterval is the interval at which metrics are flushed, or 0 if metrics are disabled
+	MetricsInterval time.Duration
+	// ReportInterval is the interval at which stats are reported, or 0 if reporting is disabled
+	ReportInterval time.Duration
+	// MeasureAllocations is true if the memory allocated by tasks is measured
+	MeasureAllocations bool
+	// ScalingPolicy is the policy that constrains how fast the pool grows and shrinks, or nil if there is none
+	ScalingPolicy *ScalingPolicy
+	// CallSiteDepth is the number of stack frames recorded for the call site that submitted each task, or 0 if disabled
+	CallSiteDepth int
+	// TargetUtilization and UtilizationWindow configure target-utilization scaling, or are 0 if it's disabled
+	TargetUtilization float64
+	UtilizationWindow time.Duration
+	// StarvationThreshold is how long tasks can wait before starvation alerts are raised, or 0 if they are disabled
+	StarvationThreshold time.Duration
+	// Deterministic is true if the pool runs in deterministic mode (see Deterministic)
+	Deterministic bool
+	// AdaptiveConcurrency holds the settings of the adaptive concurrency controller, or nil if it's disabled
+	AdaptiveConcurrency *AdaptiveConcurrency
+	// PinnedCPUs are the CPUs the workers are pinned to, or nil if they are not pinned (see PinWorkers and PinShards)
+	PinnedCPUs []int
+	// MaxQueuedPerSubmitter is the maximum number of tasks a submitter can have waiting to start, or 0 if unlimited
+	MaxQueuedPerSubmitter int
+	// Lanes is true if the pool runs in two-lane mode, in which case InteractivePerBatch is the number of interactive
+	// tasks dequeued for each batch task, or 0 if interactive tasks have strict priority (see Lanes)
+	Lanes               bool
+	InteractivePerBatch int
+	// ReservedWorkers is the number of workers kept available for interactive tasks (see ReserveWorkers)
+	ReservedWorkers int
+	// MaxFireAndForget is the maximum number of outstanding fire-and-forget tasks, or 0 if it's the maximum number
+	// of workers, and FireAndForgetOverflow is what happens to the ones submitted beyond it (see FireAndForget)
+	MaxFireAndForget      int
+	FireAndForgetOverflow OverflowPolicy
+}
+
+// Options returns the effective configuration of this pool
+func (p *WorkerPool) Options() Config {
+	config := Config{
+		Name:               p.name,
+		Tags:               p.Tags(),
+		MinWorkers:         p.minWorkers,
+		MaxWorkers:         p.maxWorkers,
+		MaxCapacity:        p.QueueCap(),
+		IdleTimeout:        p.idleTimeout,
+		IdleJitter:         p.idleJitter,
+		KeepWarm:           p.keepWarm,
+		Strategy:           p.strategy,
+		QueueOrder:         p.queueOrder,
+		LabelWeights:       copyWeights(p.labelWeights),
+		MaxQueueAge:        p.maxQueueAge,
+		MaxLabels:          p.labels.maxLabels,
+		DefaultTenantQuota: p.tenants.defaultQuota,
+		Budget:             p.budget,
+		MeasureAllocations: p.measureAllocations,
+		CallSiteDepth:      p.callSiteDepth,
+		Deterministic:      p.deterministic != nil,
+		ReservedWorkers:    p.reservedWorkers,
+	}
+	if p.lanes != nil {
+		config.Lanes = true
+		config.InteractivePerBatch = p.lanes.interactivePerBatch
+	}
+	if p.forget != nil {
+		config.MaxFireAndForget = p.forget.maxOutstanding
+		config.FireAndForgetOverflow = p.forget.overflow
+	}
+	if p.utilization != nil {
+		config.TargetUtilization = p.utilization.target
+		config.UtilizationWindow = p.utilization.window
+	}
+	if p.adaptive != nil {
+		adaptive := p.adaptive.config
+		config.AdaptiveConcurrency = &adaptive
+	}
+	if len(p.cpus) > 0 {
+		config.PinnedCPUs = append([]int(nil), p.cpus...)
+	}
+	if p.pressure != nil {
+		config.BackpressureHigh = p.pressure.high
+		config.BackpressureLow = p.pressure.low
+	}
+	if p.health != nil {
+		criteria := p.health.criteria
+		config.HealthCriteria = &criteria
+	}
+	if p.metrics != nil {
+		config.MetricsInterval = p.metrics.interval
+	}
+	if p.reporter != nil {
+		config.ReportInterval = p.reportInterval
+	}
+	if p.scaling != nil {
+		policy := p.scaling.policy
+		config.ScalingPolicy = &policy
+	}
+	if p.submitters != nil {
+		config.MaxQueuedPerSubmitter = p.submitters.maxQueued
+	}
+	if p.starvation != nil {
+		config.StarvationThreshold = p.starvation.threshold
+	}
+	return config
+}
@@ -0,0 +1,51 @@
+package pond_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestOptions(t *testing.T) {
+
+	strategy := pond.Lazy()
+	pool := pond.New(10, 100,
+		pond.Name("thumbnails"),
+		pond.MinWorkers(2),
+		pond.IdleTimeout(0),
+		pond.Strategy(strategy),
+		pond.QueueOrder(pond.LIFO),
+		pond.Backpressure(0.8, 0.2, func(pond.PressureEvent) {}),
+		pond.HealthCheck(pond.HealthCriteria{MaxSaturation: 1 * time.Second}),
+		pond.Lanes(3),
+		pond.ReserveWorkers(2),
+		pond.FireAndForget(5, pond.BlockOnOverflow),
+	)
+	defer pool.StopAndWait()
+
+	config := pool.Options()
+
+	assertEqual(t, "thumbnails", config.Name)
+	assertEqual(t, 2, config.MinWorkers)
+	assertEqual(t, 10, config.MaxWorkers)
+	assertEqual(t, 100, config.MaxCapacity)
+	assertEqual(t, 5*time.Second, config.IdleTimeout)
+	assertEqual(t, strategy, config.Strategy)
+	assertEqual(t, pond.LIFO, config.QueueOrder)
+	assertEqual(t, 100, config.MaxLabels)
+	assertEqual(t, 0.8, config.BackpressureHigh)
+	assertEqual(t, 0.2, config.BackpressureLow)
+	assertEqual(t, 1*time.Second, config.HealthCriteria.MaxSaturation)
+	assertEqual(t, time.Minute, config.HealthCriteria.RejectionWindow)
+	assertEqual(t, time.Duration(0), config.ReportInterval)
+	assertEqual(t, true, config.Lanes)
+	assertEqual(t, 3, config.InteractivePerBatch)
+	assertEqual(t, 2, config.ReservedWorkers)
+	assertEqual(t, 5, config.MaxFireAndForget)
+	assertEqual(t, pond.BlockOnOverflow, config.FireAndForgetOverflow)
+
+	pool.ResizeQueue(50)
+
+	assertEqual(t, 50, pool.Options().MaxCapacity)
+}
@@ -0,0 +1,127 @@
+package pond
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MessageSource represents a stream of messages that can be consumed by a worker pool,
+// such as a NATS JetStream subscription or any other broker client offering acknowledgements
+type MessageSource[M any] interface {
+	// Next blocks until the next message is available or the context is cancelled
+	Next(ctx context.Context) (M, error)
+	// Ack acknowledges a message that was processed successfully
+	Ack(msg M)
+	// Nack signals that a message could not be processed (the handler returned an error or panicked, or the message
+	// was dropped by the pool)
+	Nack(msg M, reason error)
+}
+
+// Consume reads messages from the given source and dispatches each one to the worker pool, where it is
+// processed by the handler. Messages are acknowledged when the handler returns nil and negatively
+// acknowledged when it returns an error or panics, or when the pool drops their task without executing it
+// (e.g. because it expired, see MaxQueueAge). Concurrency is bounded by the pool: when all workers
+// are busy and the queue is full, no more messages are read until a task completes.
+// Consume blocks until the context is cancelled or the source returns an error, and then waits for all
+// dispatched messages to be processed. It returns nil when the context was cancelled and the source error otherwise.
+func Consume[M any](ctx context.Context, pool *WorkerPool, source MessageSource[M], handler func(context.Context, M) error) error {
+
+	var inFlight sync.WaitGroup
+	defer inFlight.Wait()
+
+	for {
+		msg, err := source.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				// Context was cancelled, exit gracefully
+				return nil
+			}
+			return err
+		}
+
+		if pool.Stopped() {
+			source.Nack(msg, ErrSubmitOnStoppedPool)
+			return ErrSubmitOnStoppedPool
+		}
+
+		inFlight.Add(1)
+		pool.submitOrReject(func() {
+			defer inFlight.Done()
+
+			consumeMessage(ctx, source, handler, msg)
+		}, func(err error) {
+			source.Nack(msg, err)
+			inFlight.Done()
+		})
+	}
+}
+
+// ConsumePartitioned works like Consume, but messages that belong to the same partition (as determined
+// by the partition function) are processed serially and in the order they were read, while different
+// partitions are processed in parallel. This preserves per-partition ordering when consuming from
+// partitioned logs such as Kafka topics.
+// The number of messages read but not yet processed is bounded by the pool's maximum number of workers
+// plus its maximum capacity.
+func ConsumePartitioned[M any, K comparable](ctx context.Context, pool *WorkerPool, source MessageSource[M], partition func(M) K, handler func(context.Context, M) error) error {
+
+	executor := NewKeyedExecutor[K](pool)
+	defer executor.Wait()
+
+	// Limit the number of messages buffered in the executor so a slow partition cannot grow them unbounded
+	inFlight := make(chan struct{}, pool.MaxWorkers()+pool.MaxCapacity())
+
+	for {
+		msg, err := source.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				// Context was cancelled, exit gracefully
+				return nil
+			}
+			return err
+		}
+
+		if pool.Stopped() {
+			source.Nack(msg, ErrSubmitOnStoppedPool)
+			return ErrSubmitOnStoppedPool
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		case <-ctx.Done():
+			source.Nack(msg, ctx.Err())
+			return nil
+		}
+
+		executor.submitOrReject(partition(msg), func() {
+			defer func() {
+				<-inFlight
+			}()
+
+			consumeMessage(ctx, source, handler, msg)
+		}, func(err error) {
+			source.Nack(msg, err)
+			<-inFlight
+		})
+	}
+}
+
+// consumeMessage invokes the handler for a single message and acknowledges it according to the outcome
+func consumeMessage[M any](ctx context.Context, source MessageSource[M], handler func(context.Context, M) error, msg M) {
+
+	defer func() {
+		if p := recover(); p != nil {
+			source.Nack(msg, fmt.Errorf("message handler panicked: %v", p))
+
+			// Propagate the panic so it is reported by the pool's panic handler
+			panic(p)
+		}
+	}()
+
+	if err := handler(ctx, msg); err != nil {
+		source.Nack(msg, err)
+		return
+	}
+
+	source.Ack(msg)
+}
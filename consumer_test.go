@@ -0,0 +1,215 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+// sliceSource is a message source backed by a slice of integers
+type sliceSource struct {
+	messages []int
+	next     int
+	mutex    sync.Mutex
+	acked    []int
+	nacked   []int
+	err      error
+	cancel   context.CancelFunc
+}
+
+func (s *sliceSource) Next(ctx context.Context) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.next >= len(s.messages) {
+		if s.err != nil {
+			return 0, s.err
+		}
+		s.cancel()
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+
+	msg := s.messages[s.next]
+	s.next++
+	return msg, nil
+}
+
+func (s *sliceSource) Ack(msg int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.acked = append(s.acked, msg)
+}
+
+func (s *sliceSource) Nack(msg int, reason error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.nacked = append(s.nacked, msg)
+}
+
+func TestConsume(t *testing.T) {
+
+	pool := pond.New(3, 0, pond.PanicHandler(func(interface{}) {}))
+	defer pool.StopAndWait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	source := &sliceSource{
+		messages: []int{1, 2, 3, 4, 5, 6},
+		cancel:   cancel,
+	}
+
+	err := pond.Consume[int](ctx, pool, source, func(ctx context.Context, msg int) error {
+		switch msg {
+		case 3:
+			return errors.New("invalid message")
+		case 5:
+			panic("handler panicked")
+		}
+		return nil
+	})
+
+	assertEqual(t, nil, err)
+	assertEqual(t, 4, len(source.acked))
+	assertEqual(t, 2, len(source.nacked))
+}
+
+func TestConsumeWithSourceError(t *testing.T) {
+
+	pool := pond.New(1, 0)
+	defer pool.StopAndWait()
+
+	expectedErr := errors.New("connection lost")
+	source := &sliceSource{
+		messages: []int{1},
+		err:      expectedErr,
+	}
+
+	err := pond.Consume[int](context.Background(), pool, source, func(ctx context.Context, msg int) error {
+		return nil
+	})
+
+	assertEqual(t, expectedErr, err)
+	assertEqual(t, 1, len(source.acked))
+}
+
+func TestConsumeOnStoppedPool(t *testing.T) {
+
+	pool := pond.New(1, 0)
+	pool.StopAndWait()
+
+	source := &sliceSource{
+		messages: []int{1},
+	}
+
+	err := pond.Consume[int](context.Background(), pool, source, func(ctx context.Context, msg int) error {
+		return nil
+	})
+
+	assertEqual(t, pond.ErrSubmitOnStoppedPool, err)
+	assertEqual(t, 1, len(source.nacked))
+}
+
+func TestConsumeWithExpiredMessages(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MaxQueueAge(time.Millisecond))
+	defer pool.StopAndWait()
+
+	// Occupy the only worker until the messages expire
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	go func() {
+		for pool.WaitingTasks() < 3 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(5 * time.Millisecond)
+		close(release)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	source := &sliceSource{
+		messages: []int{1, 2, 3},
+		cancel:   cancel,
+	}
+
+	var err error
+	assertReturns(t, func() {
+		err = pond.Consume[int](ctx, pool, source, func(ctx context.Context, msg int) error {
+			return nil
+		})
+	})
+
+	assertEqual(t, nil, err)
+	assertEqual(t, 0, len(source.acked))
+	assertEqual(t, 3, len(source.nacked))
+	assertEqual(t, uint64(3), pool.ExpiredTasks())
+}
+
+func TestConsumePartitioned(t *testing.T) {
+
+	pool := pond.New(4, 4)
+	defer pool.StopAndWait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	source := &sliceSource{
+		cancel: cancel,
+	}
+	for i := 0; i < 30; i++ {
+		source.messages = append(source.messages, i)
+	}
+
+	var mutex sync.Mutex
+	lastByPartition := make(map[int]int)
+	outOfOrder := false
+	err := pond.ConsumePartitioned[int, int](ctx, pool, source, func(msg int) int {
+		return msg % 3
+	}, func(ctx context.Context, msg int) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if last, ok := lastByPartition[msg%3]; ok && last > msg {
+			outOfOrder = true
+		}
+		lastByPartition[msg%3] = msg
+		return nil
+	})
+
+	assertEqual(t, nil, err)
+	assertEqual(t, false, outOfOrder)
+	assertEqual(t, 30, len(source.acked))
+}
+
+func TestConsumePartitionedWithVetoedMessages(t *testing.T) {
+
+	pool := pond.New(1, 1, pond.SubmitHooks(func(info *pond.TaskInfo) error {
+		return errors.New("vetoed")
+	}))
+	defer pool.StopAndWait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	source := &sliceSource{
+		messages: []int{1, 2, 3, 4, 5},
+		cancel:   cancel,
+	}
+
+	// Vetoed messages are nacked and give their slot back, so consumption does not stall
+	err := pond.ConsumePartitioned[int, int](ctx, pool, source, func(msg int) int {
+		return msg % 2
+	}, func(ctx context.Context, msg int) error {
+		return nil
+	})
+
+	assertEqual(t, nil, err)
+	assertEqual(t, 0, len(source.acked))
+	assertEqual(t, 5, len(source.nacked))
+}
@@ -0,0 +1,49 @@
+package pond
+
+import (
+	"context"
+)
+
+// valuesContext is a context that exposes the values of another context but none of its deadline or cancellation
+type valuesContext struct {
+	context.Context
+	values context.Context
+	// Keys whose values are exposed, or nil to expose them all
+	keys []interface{}
+}
+
+// Value returns the value associated with key in the captured context, if key is one of the captured keys
+func (c valuesContext) Value(key interface{}) interface{} {
+	if c.keys == nil {
+		return c.values.Value(key)
+	}
+
+	for _, k := range c.keys {
+		if k == key {
+			return c.values.Value(key)
+		}
+	}
+	return c.Context.Value(key)
+}
+
+// SubmitWithValues sends a context-aware task to this worker pool for execution, propagating values from ctx
+// (typically the submitting request's context) to the task's context, so request IDs, auth principals and similar
+// request-scoped values survive the hop to the worker goroutine.
+// If keys are given, only the values stored under them are propagated; otherwise all values are.
+// The deadline and cancellation of ctx are not propagated, so the task still runs if ctx is cancelled while it is queued.
+// The task's context is only canceled if the pool is aborted (see Abort).
+func (p *WorkerPool) SubmitWithValues(ctx context.Context, task func(ctx context.Context), keys ...interface{}) {
+	if ctx == nil {
+		panic("a non-nil context needs to be specified when using SubmitWithValues")
+	}
+
+	values := valuesContext{
+		Context: p.taskContext,
+		values:  ctx,
+	}
+	if len(keys) > 0 {
+		values.keys = keys
+	}
+
+	p.submit(newContextTask(values, task), true)
+}
@@ -0,0 +1,63 @@
+package pond_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+type contextKey string
+
+func TestSubmitWithValues(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	ctx := context.WithValue(context.Background(), contextKey("requestID"), "abc")
+	ctx = context.WithValue(ctx, contextKey("principal"), "alice")
+	ctx, cancel := context.WithCancel(ctx)
+
+	// Cancel the submitter's context before the task runs
+	cancel()
+
+	var requestID, principal interface{}
+	var taskErr error
+	pool.SubmitWithValues(ctx, func(ctx context.Context) {
+		requestID = ctx.Value(contextKey("requestID"))
+		principal = ctx.Value(contextKey("principal"))
+		taskErr = ctx.Err()
+	})
+
+	var selectedRequestID, selectedPrincipal interface{}
+	var hasInfo bool
+	pool.SubmitWithValues(ctx, func(ctx context.Context) {
+		selectedRequestID = ctx.Value(contextKey("requestID"))
+		selectedPrincipal = ctx.Value(contextKey("principal"))
+		_, hasInfo = pond.FromContext(ctx)
+	}, contextKey("requestID"))
+
+	pool.StopAndWait()
+
+	assertEqual(t, "abc", requestID)
+	assertEqual(t, "alice", principal)
+	assertEqual(t, nil, taskErr)
+	assertEqual(t, "abc", selectedRequestID)
+	assertEqual(t, nil, selectedPrincipal)
+	assertEqual(t, true, hasInfo)
+}
+
+func TestSubmitWithValuesWithNilContext(t *testing.T) {
+
+	pool := pond.New(1, 1)
+	defer pool.StopAndWait()
+
+	var thrownPanic interface{}
+	func() {
+		defer func() {
+			thrownPanic = recover()
+		}()
+		pool.SubmitWithValues(nil, func(ctx context.Context) {})
+	}()
+
+	assertEqual(t, "a non-nil context needs to be specified when using SubmitWithValues", thrownPanic)
+}
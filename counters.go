@@ -0,0 +1,18 @@
+package pond
+
+// cacheLinePad is the size the hot counters of a pool are padded to, so that each of them has its own cache line
+// and cores updating one of them don't invalidate the others (false sharing). It covers both the 64-byte lines of
+// x86 CPUs, which prefetch adjacent lines in pairs, and the 128-byte lines of some arm64 CPUs.
+const cacheLinePad = 128
+
+// paddedInt32 is an int32 counter that occupies a whole cache line. It must be updated atomically.
+type paddedInt32 struct {
+	value int32
+	_     [cacheLinePad - 4]byte
+}
+
+// paddedUint64 is a uint64 counter that occupies a whole cache line. It must be updated atomically.
+type paddedUint64 struct {
+	value uint64
+	_     [cacheLinePad - 8]byte
+}
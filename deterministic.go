@@ -0,0 +1,94 @@
+package pond
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Clock tells the current time. Pools read the time from the clock given to Deterministic, if any, to timestamp
+// tasks and measure their queue waits and durations. Timers, such as the idle timeout, still use the system clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// ManualClock is a clock that only moves when it's advanced, e.g. to get reproducible task timestamps and durations
+type ManualClock struct {
+	now   time.Time
+	mutex sync.Mutex
+}
+
+// NewManualClock creates a manual clock set to the given time
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{
+		now: now,
+	}
+}
+
+// Now returns the current time of the clock
+func (c *ManualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// Advance moves the clock forward by the given duration
+func (c *ManualClock) Advance(duration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(duration)
+}
+
+// Deterministic puts a pool in deterministic mode, to reproduce ordering-sensitive bugs: the pool runs a single
+// worker that is started upfront and executes tasks one at a time in submission order (FIFO), so tasks submitted
+// from a single goroutine always run in the same order. Randomized behaviors, such as the idle timeout jitter
+// (see IdleJitter), draw from a source seeded with the given seed, and the pool reads the time from the given clock
+// (the system clock if it's nil). This mode overrides the size, queue order and bursts (see Burst) of the pool.
+func Deterministic(seed int64, clock Clock) Option {
+	return func(pool *WorkerPool) {
+		pool.deterministic = &deterministicMode{
+			seed:   seed,
+			clock:  clock,
+			random: rand.New(rand.NewSource(seed)),
+		}
+	}
+}
+
+// deterministicMode holds the settings of deterministic mode
+type deterministicMode struct {
+	seed  int64
+	clock Clock
+	// Source of randomness, only used by the purger goroutine
+	random *rand.Rand
+}
+
+// normalize enforces the settings of deterministic mode on the given pool
+func (d *deterministicMode) normalize(p *WorkerPool) {
+	p.maxWorkers = 1
+	p.minWorkers = 1
+	p.queueOrder = FIFO
+}
+
+// now returns the current time, as told by the pool's clock
+func (p *WorkerPool) now() time.Time {
+	if p.deterministic == nil || p.deterministic.clock == nil {
+		return time.Now()
+	}
+	return p.deterministic.clock.Now()
+}
+
+// int63n returns a random number in [0, n), drawn from the seeded source in deterministic mode.
+// It must only be called by the purger goroutine.
+func (p *WorkerPool) int63n(n int64) int64 {
+	if p.deterministic == nil {
+		return rand.Int63n(n)
+	}
+	return p.deterministic.random.Int63n(n)
+}
+
+// Deterministic returns true if the pool runs in deterministic mode (see Deterministic)
+func (p *WorkerPool) Deterministic() bool {
+	return p.deterministic != nil
+}
@@ -0,0 +1,57 @@
+package pond_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestDeterministicRunsTasksInOrder(t *testing.T) {
+
+	pool := pond.New(10, 100, pond.Deterministic(1, nil), pond.QueueOrder(pond.LIFO))
+
+	assertEqual(t, 1, pool.MaxWorkers())
+	assertEqual(t, 1, pool.RunningWorkers())
+
+	order := make([]int, 0, 50)
+	for i := 0; i < 50; i++ {
+		i := i
+		pool.Submit(func() {
+			order = append(order, i)
+		})
+	}
+	pool.StopAndWait()
+
+	for i, task := range order {
+		assertEqual(t, i, task)
+	}
+	assertEqual(t, 50, len(order))
+	assertEqual(t, true, pool.Options().Deterministic)
+}
+
+func TestDeterministicUsesClock(t *testing.T) {
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := pond.NewManualClock(start)
+	finished := make(chan pond.TaskInfo, 1)
+	pool := pond.New(1, 10, pond.Deterministic(1, clock), pond.Events(pond.EventListener{
+		OnTaskFinished: func(info pond.TaskInfo, panic interface{}) {
+			finished <- info
+		},
+	}))
+	defer pool.StopAndWait()
+
+	var info pond.TaskInfo
+
+	pool.SubmitContext(func(ctx context.Context) {
+		info, _ = pond.FromContext(ctx)
+		clock.Advance(time.Second)
+	})
+
+	done := <-finished
+	assertEqual(t, start, info.SubmittedAt)
+	assertEqual(t, start, info.StartedAt)
+	assertEqual(t, time.Second, done.Duration)
+}
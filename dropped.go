@@ -0,0 +1,112 @@
+package pond
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+var (
+	// ErrTaskDropped is reported in place of the result of a task that was dropped without being executed
+	// (see DropReason), e.g. by SubmitTask
+	ErrTaskDropped = errors.New("task was dropped without being executed")
+	// ErrTaskExpired is reported in place of the result of a task that was discarded because its deadline passed
+	// or it exceeded the maximum queue age before it started (see MaxQueueAge)
+	ErrTaskExpired = errors.New("task expired before it could be executed")
+	// ErrTaskCancelled is reported in place of the result of a task that was removed from the queue by CancelWhere
+	ErrTaskCancelled = errors.New("task was cancelled before it could be executed")
+)
+
+// DropReason describes why a task was dropped without being executed
+type DropReason int
+
+const (
+	// DropQueueFull means the task was rejected because the queue was full (see TrySubmit)
+	DropQueueFull DropReason = iota
+	// DropExpired means the task was discarded because its deadline passed or it exceeded the maximum queue age
+	DropExpired
+	// DropStopped means the task was rejected because the pool was stopped, or it was still waiting in the queue
+	// when the pool was stopped without waiting for queued tasks
+	DropStopped
+	// DropOverflow means the task was discarded because too many fire-and-forget tasks were outstanding
+	// (see SubmitAndForget)
+	DropOverflow
+	// DropVetoed means the submission of the task was vetoed by a submit hook (see SubmitHooks)
+	DropVetoed
+	// DropCancelled means the task was removed from the queue by CancelWhere
+	DropCancelled
+)
+
+func (r DropReason) String() string {
+	switch r {
+	case DropQueueFull:
+		return "queue full"
+	case DropExpired:
+		return "expired"
+	case DropStopped:
+		return "stopped"
+	case DropOverflow:
+		return "overflow"
+	case DropVetoed:
+		return "vetoed"
+	case DropCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// err returns the error reported to the helpers waiting for a task dropped for this reason, e.g. a TaskGroup
+func (r DropReason) err() error {
+	switch r {
+	case DropExpired:
+		return ErrTaskExpired
+	case DropCancelled:
+		return ErrTaskCancelled
+	case DropStopped:
+		return ErrSubmitOnStoppedPool
+	default:
+		return ErrTaskDropped
+	}
+}
+
+// DroppedTaskHandler allows to set a function that is invoked whenever the pool drops a task without executing it,
+// along with the reason and the metadata of the task, so that losses can be counted and logged
+func DroppedTaskHandler(handler func(reason DropReason, info TaskInfo)) Option {
+	return func(pool *WorkerPool) {
+		pool.droppedTaskHandler = handler
+	}
+}
+
+// dropTask notifies the dropped task handler, if any
+func (p *WorkerPool) dropTask(reason DropReason, info TaskInfo) {
+	if p.droppedTaskHandler != nil {
+		p.droppedTaskHandler(reason, info)
+	}
+}
+
+// closeQueue closes the task queue and discards the tasks still waiting in it, which are reported to
+// the dropped task handler. It returns the number of discarded tasks.
+func (p *WorkerPool) closeQueue() int {
+	discarded := p.tasks.Close()
+
+	for _, task := range discarded {
+		p.discardQueued(task, DropStopped)
+	}
+	if len(discarded) > 0 {
+		p.updatePressure()
+	}
+
+	return len(discarded)
+}
+
+// discardQueued settles a task that was removed from the queue without being executed, reporting it to the
+// dropped task handler with the given reason
+func (p *WorkerPool) discardQueued(task *queuedTask, reason DropReason) {
+	atomic.AddUint64(&p.waitingTaskCount.value, ^uint64(0))
+	p.lanes.dequeue(task.info.Lane)
+	p.submitters.release(task.info.Submitter)
+	task.discard(reason.err())
+	p.dropTask(reason, task.info)
+	p.settleTask(task)
+	p.tasksWaitGroup.Done()
+}
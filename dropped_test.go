@@ -0,0 +1,241 @@
+package pond_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+// droppedTasks records the tasks reported to a dropped task handler
+type droppedTasks struct {
+	byReason map[pond.DropReason]int
+	mutex    sync.Mutex
+}
+
+func (d *droppedTasks) handler(reason pond.DropReason, info pond.TaskInfo) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.byReason == nil {
+		d.byReason = make(map[pond.DropReason]int)
+	}
+	d.byReason[reason]++
+}
+
+func (d *droppedTasks) count(reason pond.DropReason) int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.byReason[reason]
+}
+
+func TestDroppedTaskHandler(t *testing.T) {
+
+	dropped := &droppedTasks{}
+	pool := pond.New(1, 1, pond.DroppedTaskHandler(dropped.handler))
+
+	// Occupy the worker and fill the queue
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	pool.SubmitWithDeadline(func() {}, time.Now().Add(time.Millisecond))
+
+	// Queue is full
+	assertEqual(t, false, pool.TrySubmit(func() {}))
+	assertEqual(t, 1, dropped.count(pond.DropQueueFull))
+
+	// Let the queued task expire
+	time.Sleep(5 * time.Millisecond)
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, 1, dropped.count(pond.DropExpired))
+
+	// Pool is stopped
+	assertEqual(t, false, pool.TrySubmit(func() {}))
+	assertEqual(t, 1, dropped.count(pond.DropStopped))
+}
+
+func TestDroppedTaskHandlerOnStop(t *testing.T) {
+
+	dropped := &droppedTasks{}
+	pool := pond.New(1, 10, pond.DroppedTaskHandler(dropped.handler))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	for i := 0; i < 5; i++ {
+		pool.Submit(func() {})
+	}
+
+	// Stop without waiting for queued tasks
+	pool.StopAndWaitFor(10 * time.Millisecond)
+	close(release)
+
+	assertEqual(t, 5, dropped.count(pond.DropStopped))
+	assertEqual(t, uint64(0), pool.WaitingTasks())
+}
+
+func TestDropReasonString(t *testing.T) {
+	assertEqual(t, "queue full", pond.DropQueueFull.String())
+	assertEqual(t, "expired", pond.DropExpired.String())
+	assertEqual(t, "stopped", pond.DropStopped.String())
+	assertEqual(t, "unknown", pond.DropReason(-1).String())
+}
+
+// assertReturns fails the test if the given function doesn't return within a second
+func assertReturns(t *testing.T, fn func()) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("function did not return")
+	}
+}
+
+// expireNext submits a task with the given function while the only worker of the pool is busy and lets it grow
+// older than the maximum queue age of the pool before the worker is released
+func expireNext(pool *pond.WorkerPool, submit func()) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	submit()
+	time.Sleep(5 * time.Millisecond)
+	close(release)
+}
+
+func TestExpiredTasksCompleteTheirWaiters(t *testing.T) {
+
+	t.Run("TaskGroup", func(t *testing.T) {
+		pool := pond.New(1, 10, pond.MaxQueueAge(time.Millisecond))
+		defer pool.StopAndWait()
+
+		group := pool.Group()
+		expireNext(pool, func() {
+			group.Submit(func() {})
+		})
+
+		assertReturns(t, group.Wait)
+		assertEqual(t, uint64(1), pool.ExpiredTasks())
+	})
+
+	t.Run("TaskGroupWithContext", func(t *testing.T) {
+		pool := pond.New(1, 10, pond.MaxQueueAge(time.Millisecond))
+		defer pool.StopAndWait()
+
+		group, _ := pool.GroupContext(context.Background())
+		expireNext(pool, func() {
+			group.Submit(func() error {
+				return nil
+			})
+		})
+
+		var err error
+		assertReturns(t, func() {
+			err = group.Wait()
+		})
+		assertEqual(t, pond.ErrTaskExpired, err)
+	})
+
+	t.Run("SubmitAndWait", func(t *testing.T) {
+		pool := pond.New(1, 10, pond.MaxQueueAge(time.Millisecond))
+		defer pool.StopAndWait()
+
+		returned := make(chan struct{})
+		expireNext(pool, func() {
+			go func() {
+				defer close(returned)
+				pool.SubmitAndWait(func() {})
+			}()
+			for pool.WaitingTasks() == 0 {
+				time.Sleep(time.Millisecond)
+			}
+		})
+
+		assertReturns(t, func() {
+			<-returned
+		})
+		assertEqual(t, uint64(1), pool.ExpiredTasks())
+	})
+}
+
+func TestStopDiscardingCompletesWaiters(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	group := pool.Group()
+	group.Submit(func() {})
+
+	groupCtx, _ := pool.GroupContext(context.Background())
+	groupCtx.Submit(func() error {
+		return nil
+	})
+
+	future := pond.SubmitFuture(pool, func() (int, error) {
+		return 1, nil
+	})
+
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		pool.SubmitAndWait(func() {})
+	}()
+	for pool.WaitingTasks() < 4 {
+		time.Sleep(time.Millisecond)
+	}
+
+	discarded := make(chan int)
+	go func() {
+		discarded <- pool.StopDiscarding()
+	}()
+	for !pool.Stopped() {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	assertEqual(t, 4, <-discarded)
+
+	assertReturns(t, group.Wait)
+	var err error
+	assertReturns(t, func() {
+		err = groupCtx.Wait()
+	})
+	assertEqual(t, pond.ErrSubmitOnStoppedPool, err)
+	assertReturns(t, func() {
+		_, err = future.Wait()
+	})
+	assertEqual(t, pond.ErrSubmitOnStoppedPool, err)
+	assertReturns(t, func() {
+		<-returned
+	})
+}
@@ -0,0 +1,68 @@
+package pond
+
+import (
+	"context"
+	"sync"
+)
+
+// ErrGroup is a drop-in replacement for golang.org/x/sync/errgroup.Group that executes
+// its functions on a worker pool instead of spawning a goroutine per call
+type ErrGroup struct {
+	pool      *WorkerPool
+	cancel    context.CancelFunc
+	waitGroup sync.WaitGroup
+	errOnce   sync.Once
+	err       error
+}
+
+// NewErrGroup creates an error group bound to the given pool and an associated Context derived from ctx.
+//
+// As with errgroup.WithContext, the derived Context is canceled the first time a function passed to Go
+// returns a non-nil error or the first time Wait returns, whichever occurs first.
+func NewErrGroup(ctx context.Context, pool *WorkerPool) (*ErrGroup, context.Context) {
+
+	if ctx == nil {
+		panic("a non-nil context needs to be specified when using NewErrGroup")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &ErrGroup{
+		pool:   pool,
+		cancel: cancel,
+	}, ctx
+}
+
+// Go submits the given function to the worker pool.
+//
+// The first call to return a non-nil error cancels the group's context; its error will be returned by Wait.
+func (g *ErrGroup) Go(f func() error) {
+	g.waitGroup.Add(1)
+
+	g.pool.submitOrReject(func() {
+		defer g.waitGroup.Done()
+
+		if err := f(); err != nil {
+			g.fail(err)
+		}
+	}, func(err error) {
+		g.fail(err)
+		g.waitGroup.Done()
+	})
+}
+
+// fail records the first error returned by a function and cancels the group's context
+func (g *ErrGroup) fail(err error) {
+	g.errOnce.Do(func() {
+		g.err = err
+		g.cancel()
+	})
+}
+
+// Wait blocks until all function calls from the Go method have returned, then returns the first
+// non-nil error (if any) from them.
+func (g *ErrGroup) Wait() error {
+	g.waitGroup.Wait()
+	g.cancel()
+
+	return g.err
+}
@@ -0,0 +1,79 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestErrGroup(t *testing.T) {
+
+	pool := pond.New(3, 10)
+	defer pool.StopAndWait()
+
+	group, ctx := pond.NewErrGroup(context.Background(), pool)
+
+	var doneCount int32
+	for i := 0; i < 10; i++ {
+		group.Go(func() error {
+			atomic.AddInt32(&doneCount, 1)
+			return nil
+		})
+	}
+
+	err := group.Wait()
+
+	assertEqual(t, nil, err)
+	assertEqual(t, int32(10), atomic.LoadInt32(&doneCount))
+	assertEqual(t, context.Canceled, ctx.Err())
+}
+
+func TestErrGroupWithError(t *testing.T) {
+
+	pool := pond.New(2, 10)
+	defer pool.StopAndWait()
+
+	group, ctx := pond.NewErrGroup(context.Background(), pool)
+
+	expectedErr := errors.New("something went wrong")
+	var doneCount int32
+	group.Go(func() error {
+		return expectedErr
+	})
+	for i := 0; i < 5; i++ {
+		group.Go(func() error {
+			// Unlike TaskGroupWithContext, Wait returns only after every function has returned
+			select {
+			case <-ctx.Done():
+			case <-time.After(1 * time.Second):
+			}
+			atomic.AddInt32(&doneCount, 1)
+			return nil
+		})
+	}
+
+	err := group.Wait()
+
+	assertEqual(t, expectedErr, err)
+	assertEqual(t, int32(5), atomic.LoadInt32(&doneCount))
+}
+
+func TestErrGroupWithNilContext(t *testing.T) {
+
+	pool := pond.New(1, 1)
+	defer pool.StopAndWait()
+
+	var thrownPanic interface{}
+	func() {
+		defer func() {
+			thrownPanic = recover()
+		}()
+		pond.NewErrGroup(nil, pool)
+	}()
+
+	assertEqual(t, "a non-nil context needs to be specified when using NewErrGroup", thrownPanic)
+}
@@ -0,0 +1,59 @@
+package pond
+
+import (
+	"runtime/metrics"
+	"time"
+)
+
+// EventListener holds callbacks that are invoked as a worker pool processes tasks, e.g. to feed custom
+// monitoring or auditing. All callbacks are optional and are invoked synchronously by the worker goroutine
+// that triggered them, so they should return quickly.
+type EventListener struct {
+	// OnTaskFinished is invoked after a task returns or panics (in which case panic holds the recovered value),
+	// with its metadata, including how long it ran and, if MeasureAllocations is enabled, how much memory it allocated
+	OnTaskFinished func(info TaskInfo, panic interface{})
+	// OnPanicHandlerFailed is invoked when the panic handler itself panics (with handlerPanic) while handling the
+	// panic of a task. The worker survives such failures, which are also logged to stderr.
+	OnPanicHandlerFailed func(info TaskInfo, panic interface{}, handlerPanic interface{})
+	// OnLatencyReported is invoked when a task reports the latency and outcome of a call to a downstream dependency
+	// with ReportLatency, e.g. to feed a circuit breaker. It's invoked by the goroutine that reported it.
+	OnLatencyReported func(info TaskInfo, latency time.Duration, err error)
+	// OnWorkerBusy is invoked when a worker picks up a task to execute, and OnWorkerIdle when it's done with it
+	// and goes back to waiting for the next one, along with the ID of the worker (see WorkerID). Each OnWorkerBusy
+	// call is followed by an OnWorkerIdle call for the same worker, which allows to track the utilization of the pool
+	// in real time, e.g. to feed an external autoscaler. Tasks run in place of a yielding task (see Yield) don't
+	// trigger these callbacks, since the worker stays busy.
+	OnWorkerBusy func(workerID uint64)
+	OnWorkerIdle func(workerID uint64)
+}
+
+// Events allows to set the listener that is notified about the activity of a worker pool
+func Events(listener EventListener) Option {
+	return func(pool *WorkerPool) {
+		pool.events = listener
+	}
+}
+
+// MeasureAllocations makes a worker pool measure the memory allocated while each task runs, which is reported
+// in TaskInfo.AllocatedBytes to event listeners and accumulated in LabelStats. The measurement is best-effort:
+// it's based on the process-wide allocation counter, so it includes allocations made by other goroutines
+// running at the same time.
+func MeasureAllocations() Option {
+	return func(pool *WorkerPool) {
+		pool.measureAllocations = true
+	}
+}
+
+// allocatedBytesMetric is the runtime metric holding the cumulative number of bytes allocated on the heap
+const allocatedBytesMetric = "/gc/heap/allocs:bytes"
+
+// allocatedBytes returns the cumulative number of bytes allocated on the heap by the process
+func allocatedBytes() uint64 {
+	sample := []metrics.Sample{{Name: allocatedBytesMetric}}
+	metrics.Read(sample)
+
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
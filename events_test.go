@@ -0,0 +1,111 @@
+package pond_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+var allocationSink [][]byte
+
+func TestEventsOnTaskFinished(t *testing.T) {
+
+	var mutex sync.Mutex
+	infos := make(map[string]pond.TaskInfo)
+	panics := make(map[string]interface{})
+	pool := pond.New(1, 10,
+		pond.PanicHandler(func(interface{}) {}),
+		pond.MeasureAllocations(),
+		pond.Events(pond.EventListener{
+			OnTaskFinished: func(info pond.TaskInfo, panic interface{}) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				infos[info.Label] = info
+				panics[info.Label] = panic
+			},
+		}),
+	)
+
+	pool.SubmitLabeled("sleep", func() {
+		time.Sleep(10 * time.Millisecond)
+	})
+	pool.SubmitLabeled("alloc", func() {
+		allocationSink = append(allocationSink, make([]byte, 1<<20))
+	})
+	pool.SubmitLabeled("panic", func() {
+		panic("boom")
+	})
+
+	pool.StopAndWait()
+
+	assertEqual(t, 3, len(infos))
+	assertEqual(t, true, infos["sleep"].Duration >= 10*time.Millisecond)
+	assertEqual(t, false, infos["sleep"].StartedAt.Before(infos["sleep"].SubmittedAt))
+	assertEqual(t, true, infos["alloc"].AllocatedBytes >= 1<<20)
+	assertEqual(t, nil, panics["alloc"])
+	assertEqual(t, "boom", panics["panic"])
+
+	stats := pool.LabelStats()
+	assertEqual(t, true, stats["alloc"].AllocatedBytes >= 1<<20)
+}
+
+func TestEventsOnPanicHandlerFailed(t *testing.T) {
+
+	var taskPanic, handlerPanic interface{}
+	pool := pond.New(1, 10, pond.PanicHandler(func(p interface{}) {
+		panic("handler failed")
+	}), pond.Events(pond.EventListener{
+		OnPanicHandlerFailed: func(info pond.TaskInfo, panic interface{}, hp interface{}) {
+			taskPanic = panic
+			handlerPanic = hp
+		},
+	}))
+
+	pool.Submit(func() {
+		panic("task failed")
+	})
+
+	// The worker survives and keeps processing tasks
+	pool.SubmitAndWait(func() {})
+	pool.StopAndWait()
+
+	assertEqual(t, "task failed", taskPanic)
+	assertEqual(t, "handler failed", handlerPanic)
+	assertEqual(t, uint64(1), pool.FailedTasks())
+	assertEqual(t, uint64(1), pool.SuccessfulTasks())
+}
+
+func TestEventsOnWorkerBusyAndIdle(t *testing.T) {
+
+	var mutex sync.Mutex
+	busy := make(map[uint64]int)
+	var transitions []string
+	pool := pond.New(2, 10, pond.Events(pond.EventListener{
+		OnWorkerBusy: func(workerID uint64) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			busy[workerID]++
+			transitions = append(transitions, "busy")
+		},
+		OnWorkerIdle: func(workerID uint64) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			busy[workerID]--
+			transitions = append(transitions, "idle")
+		},
+	}))
+
+	for i := 0; i < 5; i++ {
+		pool.Submit(func() {
+			time.Sleep(time.Millisecond)
+		})
+	}
+	pool.StopAndWait()
+
+	assertEqual(t, 10, len(transitions))
+	for _, count := range busy {
+		assertEqual(t, 0, count)
+	}
+}
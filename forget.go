@@ -0,0 +1,91 @@
+package pond
+
+import "context"
+
+// OverflowPolicy defines what SubmitAndForget does when the maximum number of outstanding fire-and-forget tasks is reached
+type OverflowPolicy int
+
+const (
+	// DiscardOverflow discards the task, reporting it to the DroppedTaskHandler
+	DiscardOverflow OverflowPolicy = iota
+	// BlockOnOverflow waits until an outstanding fire-and-forget task completes
+	BlockOnOverflow
+)
+
+// FireAndForget allows to change the maximum number of fire-and-forget tasks (see SubmitAndForget) that can be
+// outstanding (queued or running) at any given time, and what happens to the ones submitted beyond that limit.
+// By default, or if maxOutstanding is 0, at most maxWorkers such tasks can be outstanding, and by default overflowing
+// ones are discarded.
+func FireAndForget(maxOutstanding int, overflow OverflowPolicy) Option {
+	return func(pool *WorkerPool) {
+		pool.forget = &forgetLimiter{
+			maxOutstanding: maxOutstanding,
+			overflow:       overflow,
+		}
+	}
+}
+
+// forgetLimiter bounds the number of outstanding fire-and-forget tasks
+type forgetLimiter struct {
+	maxOutstanding int
+	overflow       OverflowPolicy
+	semaphore      *semaphore
+}
+
+// normalize makes sure the limit is consistent, defaulting to the given maximum number of workers
+func (l *forgetLimiter) normalize(maxWorkers int) {
+	if l.maxOutstanding < 0 {
+		l.maxOutstanding = 0
+	}
+	limit := l.maxOutstanding
+	if limit == 0 {
+		limit = maxWorkers
+	}
+	if l.overflow != BlockOnOverflow {
+		l.overflow = DiscardOverflow
+	}
+	l.semaphore = newSemaphore(int64(limit))
+}
+
+// acquire reserves a slot for a fire-and-forget task, returning false if the task must be discarded
+func (l *forgetLimiter) acquire() bool {
+	if l.overflow == BlockOnOverflow {
+		l.semaphore.Acquire(context.Background(), 1)
+		return true
+	}
+	return l.semaphore.TryAcquire(1)
+}
+
+// SubmitAndForget sends a side task (e.g. logging or telemetry) to this worker pool for execution. Unlike Submit,
+// it never waits for room in the queue, and the number of such tasks that can be outstanding is bounded (see
+// FireAndForget), so they can't crowd out primary work. It returns false if the task was discarded, either because
+// of the overflow policy, because the queue is full or because the pool was stopped.
+func (p *WorkerPool) SubmitAndForget(task func()) bool {
+	if task == nil {
+		return false
+	}
+
+	queued := newQueuedTask(nil)
+	if !p.forget.acquire() {
+		p.dropTask(DropOverflow, queued.info)
+		return false
+	}
+
+	release := func() {
+		p.forget.semaphore.Release(1)
+	}
+	queued.run = func() {
+		defer release()
+
+		task()
+	}
+	queued.onDiscard = func(error) {
+		release()
+	}
+
+	if !p.submit(queued, false) {
+		release()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package pond_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestSubmitAndForget(t *testing.T) {
+
+	var dropped int32
+	pool := pond.New(4, 100, pond.FireAndForget(2, pond.DiscardOverflow), pond.DroppedTaskHandler(func(reason pond.DropReason, info pond.TaskInfo) {
+		if reason == pond.DropOverflow {
+			atomic.AddInt32(&dropped, 1)
+		}
+	}))
+
+	release := make(chan struct{})
+	var completed int32
+	task := func() {
+		<-release
+		atomic.AddInt32(&completed, 1)
+	}
+
+	assertEqual(t, true, pool.SubmitAndForget(task))
+	assertEqual(t, true, pool.SubmitAndForget(task))
+
+	// The limit of outstanding fire-and-forget tasks is reached
+	assertEqual(t, false, pool.SubmitAndForget(task))
+	assertEqual(t, int32(1), atomic.LoadInt32(&dropped))
+
+	// Primary work is not affected
+	pool.Submit(task)
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, int32(3), atomic.LoadInt32(&completed))
+	assertEqual(t, false, pool.SubmitAndForget(task))
+}
+
+func TestSubmitAndForgetBlockOnOverflow(t *testing.T) {
+
+	pool := pond.New(4, 100, pond.FireAndForget(1, pond.BlockOnOverflow))
+
+	var completed int32
+	for i := 0; i < 10; i++ {
+		assertEqual(t, true, pool.SubmitAndForget(func() {
+			atomic.AddInt32(&completed, 1)
+		}))
+	}
+
+	pool.StopAndWait()
+
+	assertEqual(t, int32(10), atomic.LoadInt32(&completed))
+}
+
+func TestSubmitAndForgetReleasesExpiredTasks(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MaxQueueAge(time.Millisecond), pond.FireAndForget(1, pond.DiscardOverflow))
+
+	// Occupy the worker until the fire-and-forget task expires
+	release := make(chan struct{})
+	pool.Submit(func() {
+		<-release
+	})
+	assertEqual(t, true, pool.SubmitAndForget(func() {}))
+	time.Sleep(5 * time.Millisecond)
+	close(release)
+	pool.SubmitAndWait(func() {})
+
+	// The slot of the expired task was released
+	assertEqual(t, uint64(1), pool.ExpiredTasks())
+	assertEqual(t, true, pool.SubmitAndForget(func() {}))
+
+	pool.StopAndWait()
+}
@@ -0,0 +1,13 @@
+package pond
+
+// SubmitFront sends a task to this worker pool for execution, just like Submit, but queues it ahead of the tasks
+// already waiting, e.g. to retry a task immediately or to run an urgent job. Tasks submitted this way are dequeued
+// first, the last one first, whatever the queue order. In two-lane mode (see Lanes), the task is queued at the front
+// of the interactive lane, so it's still subject to the lane schedule. If the queue is full, it waits for room ahead
+// of the other blocked submitters.
+func (p *WorkerPool) SubmitFront(task func()) {
+	queued := newQueuedTask(task)
+	queued.front = true
+
+	p.submit(queued, true)
+}
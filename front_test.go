@@ -0,0 +1,112 @@
+package pond_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestSubmitFront(t *testing.T) {
+
+	orders := []pond.Order{pond.FIFO, pond.LIFO, pond.EarliestDeadlineFirst}
+	for _, order := range orders {
+		pool := pond.New(1, 10, pond.MinWorkers(1), pond.QueueOrder(order))
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+		pool.Submit(func() {
+			close(started)
+			<-release
+		})
+		<-started
+
+		var executed strings.Builder
+		var mutex sync.Mutex
+		record := func(name string) func() {
+			return func() {
+				mutex.Lock()
+				executed.WriteString(name)
+				mutex.Unlock()
+			}
+		}
+		pool.Submit(record("a"))
+		pool.SubmitFront(record("b"))
+		pool.SubmitFront(record("c"))
+
+		close(release)
+		pool.StopAndWait()
+
+		assertEqual(t, "cb", executed.String()[:2])
+	}
+}
+
+func TestSubmitFrontWithLanes(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MinWorkers(1), pond.Lanes(0))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var executed strings.Builder
+	var mutex sync.Mutex
+	record := func(name string) func() {
+		return func() {
+			mutex.Lock()
+			executed.WriteString(name)
+			mutex.Unlock()
+		}
+	}
+	pool.SubmitBatch(record("x"))
+	pool.Submit(record("a"))
+	pool.SubmitFront(record("b"))
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, "bax", executed.String())
+}
+
+func TestSubmitFrontWaitsAheadOfSubmitters(t *testing.T) {
+
+	pool := pond.New(1, 0, pond.MinWorkers(1))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var executed strings.Builder
+	var mutex sync.Mutex
+	record := func(name string) func() {
+		return func() {
+			mutex.Lock()
+			executed.WriteString(name)
+			mutex.Unlock()
+		}
+	}
+
+	go pool.Submit(record("a"))
+	for pool.WaitingTasks() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	go pool.SubmitFront(record("b"))
+	for pool.WaitingTasks() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, "ba", executed.String())
+}
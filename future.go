@@ -0,0 +1,125 @@
+package pond
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error reported in place of the result of a task that panicked, e.g. by a Future or a ResultGroup
+type PanicError struct {
+	// Value is the value the task panicked with
+	Value interface{}
+	// Stack is the stack trace of the goroutine at the time of the panic
+	Stack []byte
+}
+
+// newPanicError creates a PanicError holding the given panic value and the current stack trace.
+// It must be called from the deferred function that recovered the panic for the stack trace to be relevant.
+func newPanicError(value interface{}) *PanicError {
+	return &PanicError{
+		Value: value,
+		Stack: debug.Stack(),
+	}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", e.Value)
+}
+
+// Unwrap returns the value the task panicked with if it's an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Future represents the pending result of a task submitted with SubmitFuture
+type Future[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+	info  TaskInfo
+}
+
+// SubmitFuture sends a task that returns a value to the worker pool for execution and returns a Future that
+// holds its result once it completes. If the task panics, the panic is reported by the Future as a PanicError
+// (and handled by the pool's panic handler as well), so callers awaiting the result are not left hanging.
+// Likewise, if the task is dropped without being executed, the Future reports the error of the reason it was
+// dropped for, e.g. ErrTaskExpired, ErrTaskCancelled or ErrSubmitOnStoppedPool (see DropReason).
+func SubmitFuture[T any](pool *WorkerPool, task func() (T, error)) *Future[T] {
+
+	future := &Future[T]{
+		done: make(chan struct{}),
+	}
+
+	queued := newQueuedTask(nil)
+	queued.run = func() {
+		completed := false
+		defer func() {
+			if !completed {
+				// Report the panic as the error of the task and let the pool handle it
+				p := recover()
+				var zero T
+				future.complete(zero, newPanicError(p), queued.info)
+				panic(p)
+			}
+		}()
+
+		value, err := task()
+		completed = true
+		future.complete(value, err, queued.info)
+	}
+	queued.onReject = func(err error) {
+		var zero T
+		future.complete(zero, err, queued.info)
+	}
+	queued.onDiscard = queued.onReject
+	pool.submit(queued, true)
+
+	return future
+}
+
+// Done returns a channel that is closed once the task has completed
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// complete records the outcome of the task and wakes up the callers waiting for it
+func (f *Future[T]) complete(value T, err error, info TaskInfo) {
+	f.value, f.err, f.info = value, err, info
+	close(f.done)
+}
+
+// Result waits for the task to complete and returns its outcome along with its metadata
+func (f *Future[T]) Result() Result[T] {
+	<-f.done
+	return Result[T]{
+		Value: f.value,
+		Err:   f.err,
+		Info:  f.info,
+	}
+}
+
+// Err waits for the task to complete and returns its error, if any
+func (f *Future[T]) Err() error {
+	<-f.done
+	return f.err
+}
+
+// Wait waits for the task to complete and returns its result
+func (f *Future[T]) Wait() (T, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// WaitContext waits for the task to complete or for ctx to be done, whichever happens first.
+// In the latter case, it returns ctx.Err() and the task keeps running.
+func (f *Future[T]) WaitContext(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,138 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestSubmitFuture(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	future := pond.SubmitFuture(pool, func() (int, error) {
+		return 42, nil
+	})
+
+	value, err := future.Wait()
+	assertEqual(t, 42, value)
+	assertEqual(t, nil, err)
+
+	failing := pond.SubmitFuture(pool, func() (int, error) {
+		return 0, errors.New("failed")
+	})
+
+	_, err = failing.Wait()
+	assertEqual(t, "failed", err.Error())
+
+	pool.StopAndWait()
+}
+
+func TestSubmitFutureWithPanic(t *testing.T) {
+
+	var handledPanic interface{}
+	pool := pond.New(1, 10, pond.PanicHandler(func(p interface{}) {
+		handledPanic = p
+	}))
+
+	future := pond.SubmitFuture(pool, func() (string, error) {
+		panic("boom")
+	})
+
+	value, err := future.Wait()
+	assertEqual(t, "", value)
+
+	var panicErr *pond.PanicError
+	assertEqual(t, true, errors.As(err, &panicErr))
+	assertEqual(t, "boom", panicErr.Value)
+	assertEqual(t, "task panicked: boom", err.Error())
+	assertEqual(t, true, strings.Contains(string(panicErr.Stack), "TestSubmitFutureWithPanic"))
+
+	// The panic is still handled by the pool
+	pool.StopAndWait()
+	assertEqual(t, "boom", handledPanic)
+}
+
+func TestFutureWaitContext(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	release := make(chan struct{})
+	future := pond.SubmitFuture(pool, func() (int, error) {
+		<-release
+		return 1, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := future.WaitContext(ctx)
+	assertEqual(t, context.DeadlineExceeded, err)
+
+	close(release)
+	<-future.Done()
+
+	value, err := future.WaitContext(context.Background())
+	assertEqual(t, 1, value)
+	assertEqual(t, nil, err)
+
+	pool.StopAndWait()
+}
+
+func TestFutureResult(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.Name("futures"))
+	defer pool.StopAndWait()
+
+	result := pond.SubmitFuture(pool, func() (int, error) {
+		return 42, nil
+	}).Result()
+
+	assertEqual(t, 42, result.Value)
+	assertEqual(t, nil, result.Err)
+	assertEqual(t, "futures", result.Info.Pool)
+	assertEqual(t, true, result.Info.ID != 0)
+	assertEqual(t, false, result.Info.StartedAt.IsZero())
+}
+
+func TestSubmitFutureDropped(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MaxQueueAge(time.Millisecond))
+	defer pool.StopAndWait()
+
+	// Occupy the worker until the tasks are cancelled or expire
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	cancelled := pond.SubmitFuture(pool, func() (int, error) {
+		return 1, nil
+	})
+	assertEqual(t, 1, pool.CancelWhere(func(pond.TaskInfo) bool {
+		return true
+	}))
+
+	expired := pond.SubmitFuture(pool, func() (int, error) {
+		return 2, nil
+	})
+	time.Sleep(5 * time.Millisecond)
+	close(release)
+
+	var err error
+	assertReturns(t, func() {
+		_, err = expired.Wait()
+	})
+	assertEqual(t, pond.ErrTaskExpired, err)
+	assertReturns(t, func() {
+		_, err = cancelled.Wait()
+	})
+	assertEqual(t, pond.ErrTaskCancelled, err)
+}
@@ -0,0 +1,87 @@
+package pond
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Gather executes the given functions concurrently on the worker pool and waits for all of them to return.
+// Each function receives a context derived from ctx that is canceled perCallTimeout after the function starts
+// (no timeout is applied if perCallTimeout is not greater than zero), and is expected to return when it's done.
+// Results are returned in the same order as the functions: values[i] and errs[i] hold the outcome of fns[i],
+// so successful calls can be used even if others failed, panicked or timed out. Functions that did not start before ctx
+// was canceled are skipped and their error is the context's error, while the error of functions dropped by the pool
+// is the drop error (see DropReason).
+func Gather[T any](ctx context.Context, pool *WorkerPool, fns []func(ctx context.Context) (T, error), perCallTimeout time.Duration) ([]T, []error) {
+
+	if ctx == nil {
+		panic("a non-nil context needs to be specified when using Gather")
+	}
+
+	results := GatherResults(ctx, pool, fns, perCallTimeout)
+
+	values := make([]T, len(results))
+	errs := make([]error, len(results))
+	for i, result := range results {
+		values[i], errs[i] = result.Value, result.Err
+	}
+
+	return values, errs
+}
+
+// GatherResults is like Gather, but returns the outcome of each function as a Result, along with the metadata
+// of the task that ran it
+func GatherResults[T any](ctx context.Context, pool *WorkerPool, fns []func(ctx context.Context) (T, error), perCallTimeout time.Duration) []Result[T] {
+
+	if ctx == nil {
+		panic("a non-nil context needs to be specified when using GatherResults")
+	}
+
+	results := make([]Result[T], len(fns))
+
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(len(fns))
+
+	for i, fn := range fns {
+		i, fn := i, fn
+		pool.submitWithInfo(func(info TaskInfo) {
+			defer waitGroup.Done()
+
+			results[i].Info = info
+
+			completed := false
+			defer func() {
+				if !completed {
+					// Report the panic as the error of the call and let the pool handle it
+					p := recover()
+					results[i].Err = newPanicError(p)
+					panic(p)
+				}
+			}()
+
+			if err := ctx.Err(); err != nil {
+				results[i].Err = err
+				completed = true
+				return
+			}
+
+			callCtx := ctx
+			if perCallTimeout > 0 {
+				var cancel context.CancelFunc
+				callCtx, cancel = context.WithTimeout(ctx, perCallTimeout)
+				defer cancel()
+			}
+
+			results[i].Value, results[i].Err = fn(callCtx)
+			completed = true
+		}, func(info TaskInfo, err error) {
+			results[i] = Result[T]{Err: err, Info: info}
+			waitGroup.Done()
+		})
+	}
+
+	waitGroup.Wait()
+
+	return results
+}
@@ -0,0 +1,130 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestGather(t *testing.T) {
+
+	pool := pond.New(5, 100)
+	defer pool.StopAndWait()
+
+	sampleErr := errors.New("sample error")
+	values, errs := pond.Gather(context.Background(), pool, []func(context.Context) (string, error){
+		func(ctx context.Context) (string, error) {
+			return "a", nil
+		},
+		func(ctx context.Context) (string, error) {
+			return "", sampleErr
+		},
+		func(ctx context.Context) (string, error) {
+			// Slow call that exceeds its timeout
+			select {
+			case <-time.After(1 * time.Second):
+				return "late", nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		},
+		func(ctx context.Context) (string, error) {
+			return "d", nil
+		},
+	}, 10*time.Millisecond)
+
+	assertEqual(t, 4, len(values))
+	assertEqual(t, "a", values[0])
+	assertEqual(t, nil, errs[0])
+	assertEqual(t, sampleErr, errs[1])
+	assertEqual(t, context.DeadlineExceeded, errs[2])
+	assertEqual(t, "d", values[3])
+	assertEqual(t, nil, errs[3])
+}
+
+func TestGatherWithCancelledContext(t *testing.T) {
+
+	pool := pond.New(1, 100)
+	defer pool.StopAndWait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	_, errs := pond.Gather(ctx, pool, []func(context.Context) (int, error){
+		func(ctx context.Context) (int, error) {
+			called = true
+			return 1, nil
+		},
+	}, 0)
+
+	assertEqual(t, false, called)
+	assertEqual(t, context.Canceled, errs[0])
+}
+
+func TestGatherResults(t *testing.T) {
+
+	pool := pond.New(2, 10)
+	defer pool.StopAndWait()
+
+	errFailed := errors.New("failed")
+	results := pond.GatherResults(context.Background(), pool, []func(ctx context.Context) (string, error){
+		func(ctx context.Context) (string, error) {
+			return "a", nil
+		},
+		func(ctx context.Context) (string, error) {
+			return "", errFailed
+		},
+	}, 0)
+
+	assertEqual(t, 2, len(results))
+	assertEqual(t, "a", results[0].Value)
+	assertEqual(t, nil, results[0].Err)
+	assertEqual(t, errFailed, results[1].Err)
+	assertEqual(t, true, results[0].Info.ID != results[1].Info.ID)
+	assertEqual(t, false, results[1].Info.StartedAt.IsZero())
+}
+
+func TestGatherResultsWithCancelledTasks(t *testing.T) {
+
+	pool := pond.New(1, 10)
+	defer pool.StopAndWait()
+
+	// Occupy the only worker until the calls are cancelled
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	go func() {
+		for pool.WaitingTasks() < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		pool.CancelWhere(func(pond.TaskInfo) bool {
+			return true
+		})
+		close(release)
+	}()
+
+	fns := []func(ctx context.Context) (int, error){
+		func(ctx context.Context) (int, error) {
+			return 1, nil
+		},
+		func(ctx context.Context) (int, error) {
+			return 2, nil
+		},
+	}
+
+	var results []pond.Result[int]
+	assertReturns(t, func() {
+		results = pond.GatherResults(context.Background(), pool, fns, 0)
+	})
+	assertEqual(t, 2, len(results))
+	assertEqual(t, pond.ErrTaskCancelled, results[0].Err)
+	assertEqual(t, pond.ErrTaskCancelled, results[1].Err)
+}
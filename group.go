@@ -2,132 +2,655 @@ package pond
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// TaskGroup represents a group of related tasks
+// pendingTasks tracks the tasks of a group that have not completed yet. Unlike a sync.WaitGroup, it allows
+// tasks to be added while other goroutines are waiting, which makes groups reusable after Wait.
+type pendingTasks struct {
+	count int
+	// Channel closed when count drops to zero, or nil if there are no pending tasks
+	done chan struct{}
+	// Pending tasks of the parent group, which also account for the tasks of this one (see Subgroup)
+	parent *pendingTasks
+	mutex  sync.Mutex
+}
+
+// completedTasks is a closed channel returned by wait when there are no pending tasks
+var completedTasks = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+func (t *pendingTasks) add(n int) {
+	t.mutex.Lock()
+	if t.count == 0 {
+		t.done = make(chan struct{})
+	}
+	t.count += n
+	t.mutex.Unlock()
+
+	if t.parent != nil {
+		t.parent.add(n)
+	}
+}
+
+func (t *pendingTasks) remove(n int) {
+	t.mutex.Lock()
+	if n > t.count {
+		t.mutex.Unlock()
+		panic("pond: more tasks marked as done than added to the group")
+	}
+	t.count -= n
+	if t.count == 0 {
+		close(t.done)
+		t.done = nil
+	}
+	t.mutex.Unlock()
+
+	if t.parent != nil {
+		t.parent.remove(n)
+	}
+}
+
+// wait returns a channel that is closed once all the tasks pending at the time of the call, as well as
+// those added before they complete, have completed
+func (t *pendingTasks) wait() <-chan struct{} {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.count == 0 {
+		return completedTasks
+	}
+	return t.done
+}
+
+// waitUntil waits for the pending tasks to complete (see wait) or for the deadline to pass, whichever happens first.
+// It returns false if the deadline passed while tasks were still pending.
+func (t *pendingTasks) waitUntil(deadline time.Time) bool {
+	done := t.wait()
+	select {
+	case <-done:
+		return true
+	default:
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// TaskGroup represents a group of related tasks. A group can be reused after Wait returns, and Wait can be
+// called concurrently from multiple goroutines, as well as while other goroutines submit tasks to the group.
 type TaskGroup struct {
-	pool      *WorkerPool
-	waitGroup sync.WaitGroup
+	pool    *WorkerPool
+	label   string
+	pending pendingTasks
+	// Limiter spacing out the submission of tasks, if any
+	limiter *rateLimiter
+}
+
+// SetLabel sets the label attached to the tasks submitted to this group from now on (see SubmitLabeled)
+func (g *TaskGroup) SetLabel(label string) {
+	g.label = label
 }
 
 // Submit adds a task to this group and sends it to the worker pool to be executed
 func (g *TaskGroup) Submit(task func()) {
-	g.waitGroup.Add(1)
+	g.limiter.wait(context.Background())
 
-	g.pool.Submit(func() {
-		defer g.waitGroup.Done()
+	g.pending.add(1)
+
+	g.pool.submit(g.newTask(task), true)
+}
+
+// newTask wraps the given task of this group, so it's marked as completed when it returns or if its submission
+// is vetoed (see SubmitHooks)
+func (g *TaskGroup) newTask(task func()) *queuedTask {
+	queued := newQueuedTask(func() {
+		defer g.pending.remove(1)
 
 		task()
 	})
+	queued.info.Label = g.label
+	queued.onReject = func(error) {
+		g.pending.remove(1)
+	}
+	return queued
+}
+
+// SubmitAll adds the given tasks to this group and sends them to the worker pool to be executed,
+// amortizing the cost of submission (see WorkerPool.SubmitAll). Nil tasks are ignored.
+func (g *TaskGroup) SubmitAll(tasks []func()) {
+	if g.limiter != nil {
+		// Tasks are spaced out by the rate limit anyway, submit them one at a time
+		for _, task := range tasks {
+			if task != nil {
+				g.Submit(task)
+			}
+		}
+		return
+	}
+
+	wrapped := make([]func(), 0, len(tasks))
+	for _, task := range tasks {
+		if task == nil {
+			continue
+		}
+		task := task
+		wrapped = append(wrapped, func() {
+			defer g.pending.remove(1)
+
+			task()
+		})
+	}
+	if len(wrapped) == 0 {
+		return
+	}
+
+	reject := func(error) {
+		g.pending.remove(1)
+	}
+	queued := newQueuedTasks(wrapped)
+	for _, task := range queued {
+		task.info.Label = g.label
+		task.onReject = reject
+	}
+
+	g.pending.add(len(queued))
+	g.pool.submitAll(queued)
 }
 
-// Wait waits until all the tasks in this group have completed
+// Add adds n units of work executed outside the pool (e.g. inline on a fast path) to this group, so that Wait
+// also waits for them, just like sync.WaitGroup.Add. Each unit must be marked as completed by calling Done.
+func (g *TaskGroup) Add(n int) {
+	if n <= 0 {
+		return
+	}
+	g.pending.add(n)
+}
+
+// Done marks a unit of work added with Add as completed. It panics if called more times than units were added.
+func (g *TaskGroup) Done() {
+	g.pending.remove(1)
+}
+
+// Subgroup creates a task group whose tasks also count as tasks of this group, so that this group's Wait
+// waits for them too. Subgroups can be nested to mirror hierarchical fan-outs (e.g. per region, then per host),
+// and they inherit the label of this group.
+func (g *TaskGroup) Subgroup() *TaskGroup {
+	subgroup := &TaskGroup{
+		pool:  g.pool,
+		label: g.label,
+	}
+	subgroup.pending.parent = &g.pending
+	return subgroup
+}
+
+// Wait waits until all the tasks in this group have completed, including those submitted while waiting
 func (g *TaskGroup) Wait() {
 
 	// Wait for all tasks to complete
-	g.waitGroup.Wait()
+	<-g.pending.wait()
+}
+
+// GroupOption represents an option that can be passed when creating a task group with context
+type GroupOption func(*TaskGroupWithContext)
+
+// CollectErrors makes a task group retain the errors returned by all of its tasks, including those returned
+// after the first error cancelled the group's context. Each error is wrapped in a TaskError that identifies
+// the task that returned it, and Wait waits for all tasks to complete and returns a GroupError holding them.
+func CollectErrors() GroupOption {
+	return func(group *TaskGroupWithContext) {
+		group.errs.collectAll = true
+	}
+}
+
+// MaxConcurrency makes a task group run at most the given number of its tasks at the same time, independently of
+// the size of the pool, e.g. to bound the load on a downstream service. Submit blocks until one of the running tasks
+// completes or the group's context is canceled, in which case the task is skipped. Detached tasks are not limited.
+func MaxConcurrency(tasks int) GroupOption {
+	return func(group *TaskGroupWithContext) {
+		if tasks > 0 {
+			group.slots = make(chan struct{}, tasks)
+		}
+	}
+}
+
+// KeepContext makes a task group keep its context alive when Wait returns after all its tasks succeeded, so it can
+// be used for follow-up work and the group can be reused. The context is still canceled as soon as a task fails or
+// the parent context is canceled. Cancel must be called once the group is no longer used, to release its context.
+func KeepContext() GroupOption {
+	return func(group *TaskGroupWithContext) {
+		group.keepContext = true
+	}
 }
 
-// TaskGroupWithContext represents a group of related tasks associated to a context
+// IsolateErrors makes a subgroup keep the errors of its tasks to itself (see TaskGroupWithContext.Subgroup).
+// It has no effect on groups that are not subgroups.
+func IsolateErrors() GroupOption {
+	return func(group *TaskGroupWithContext) {
+		group.isolated = true
+	}
+}
+
+// TaskError wraps the error returned by a task of a group, identifying the task that returned it
+type TaskError struct {
+	// Index is the position of the task in the group, in submission order starting at 0
+	Index int
+	// Label is the label the task was submitted with, if any
+	Label string
+	Err   error
+}
+
+func (e *TaskError) Error() string {
+	if e.Label != "" {
+		return fmt.Sprintf("task %d (%s): %v", e.Index, e.Label, e.Err)
+	}
+	return fmt.Sprintf("task %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the error returned by the task
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// GroupError aggregates the errors returned by the tasks of a group created with the CollectErrors option
+type GroupError struct {
+	Errors []error
+}
+
+func (e *GroupError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d tasks failed: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Is returns true if any of the aggregated errors matches target
+func (e *GroupError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the aggregated errors
+func (e *GroupError) Unwrap() []error {
+	return e.Errors
+}
+
+// groupErrors records the errors returned by the tasks of a group
+type groupErrors struct {
+	collectAll bool
+	first      error
+	all        []error
+	mutex      sync.Mutex
+}
+
+// record stores the given error and returns true if it's the first one
+func (e *groupErrors) record(err error) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.collectAll {
+		e.all = append(e.all, err)
+	}
+	if e.first != nil {
+		return false
+	}
+	e.first = err
+	return true
+}
+
+func (e *groupErrors) firstError() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.first
+}
+
+func (e *groupErrors) allErrors() []error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if !e.collectAll {
+		if e.first == nil {
+			return nil
+		}
+		return []error{e.first}
+	}
+	return append([]error(nil), e.all...)
+}
+
+// TaskGroupWithContext represents a group of related tasks associated to a context.
+// Wait can be called multiple times and from multiple goroutines, and always returns the first error.
+// Since the context is cancelled when Wait returns, tasks submitted afterwards are skipped, so a new group
+// must be created for each batch of tasks, unless the group was created with the KeepContext option.
 type TaskGroupWithContext struct {
+	// Index of the last task submitted, placed first so it's 64-bit aligned on 32-bit platforms
+	lastIndex int64
 	TaskGroup
 	ctx    context.Context
 	cancel context.CancelFunc
-
-	errSync struct {
-		once  sync.Once
-		guard sync.RWMutex
-	}
-	err error
+	errs   groupErrors
+	// Parent group, if this is a subgroup, and whether errors are kept from it
+	parent   *TaskGroupWithContext
+	isolated bool
+	// Retry policy applied to the tasks of this group, if any
+	retry *RetryPolicy
+	// Slots held by the running tasks of this group, if their concurrency is limited (see MaxConcurrency)
+	slots chan struct{}
+	// Time by which Wait returns, if any (see GroupWithTimeout)
+	deadline time.Time
+	// Whether the context is kept alive when Wait returns (see KeepContext)
+	keepContext bool
 }
 
 // Submit adds a task to this group and sends it to the worker pool to be executed
 func (g *TaskGroupWithContext) Submit(task func() error) {
-	g.waitGroup.Add(1)
+	g.submit(func(context.Context) error {
+		return task()
+	})
+}
 
-	g.pool.Submit(func() {
-		defer g.waitGroup.Done()
+// SubmitContext adds a context-aware task to this group and sends it to the worker pool to be executed.
+// The context passed to the task is derived from the group's context and carries the task's metadata,
+// which can be retrieved with FromContext.
+func (g *TaskGroupWithContext) SubmitContext(task func(ctx context.Context) error) {
+	g.submit(task)
+}
 
-		// If context has already been cancelled, skip task execution
-		if g.ctx != nil {
-			select {
-			case <-g.ctx.Done():
-				return
-			default:
+// SubmitWithArgs adds a task(args map[string]interface{}) to this group and sends it to the worker pool to be executed
+func (g *TaskGroupWithContext) SubmitWithArgs(task func(args map[string]interface{}) error, args map[string]interface{}) {
+	g.submit(func(context.Context) error {
+		return task(args)
+	})
+}
+
+// SubmitDetached adds a task to this group that runs even if the group's context is canceled, e.g. cleanup or
+// logging work that must not be skipped when a sibling task fails. The task is counted by Wait, but note that
+// Wait returns as soon as the context is canceled unless the group was created with the CollectErrors option.
+func (g *TaskGroupWithContext) SubmitDetached(task func()) {
+	g.pending.add(1)
+
+	queued := newQueuedTask(func() {
+		defer g.pending.remove(1)
+
+		task()
+	})
+	queued.info.Label = g.label
+	queued.onReject = func(err error) {
+		g.fail(err)
+		g.pending.remove(1)
+	}
+	g.pool.submit(queued, true)
+}
+
+// SubmitAll adds the given tasks to this group and sends them to the worker pool to be executed,
+// amortizing the cost of submission (see WorkerPool.SubmitAll). Nil tasks are ignored.
+func (g *TaskGroupWithContext) SubmitAll(tasks []func() error) {
+	if g.limiter != nil || g.slots != nil {
+		// Tasks are spaced out by the rate or concurrency limit anyway, submit them one at a time
+		for _, task := range tasks {
+			if task != nil {
+				g.Submit(task)
 			}
 		}
+		return
+	}
 
-		// don't actually ignore errors
-		err := task()
-		if err != nil {
-			g.errSync.once.Do(func() {
-				g.errSync.guard.Lock()
-				g.err = err
-				g.errSync.guard.Unlock()
-
-				if g.cancel != nil {
-					g.cancel()
-				}
-			})
+	funcs := make([]func() error, 0, len(tasks))
+	for _, task := range tasks {
+		if task != nil {
+			funcs = append(funcs, task)
 		}
-	})
+	}
+	if len(funcs) == 0 {
+		return
+	}
+
+	firstIndex := int(atomic.AddInt64(&g.lastIndex, int64(len(funcs))) - int64(len(funcs)))
+
+	queued := make([]*queuedTask, len(funcs))
+	for i, task := range funcs {
+		task := task
+		queued[i] = g.newAttempt(func(context.Context) error {
+			return task()
+		}, firstIndex+i, g.label, 1, nil, nil)
+	}
+
+	g.pending.add(len(queued))
+	g.pool.submitAll(queued)
 }
 
-// SubmitWithArgs adds a task(args map[string]interface{}) to this group and sends it to the worker pool to be executed
-func (g *TaskGroupWithContext) SubmitWithArgs(task func(args map[string]interface{}) error, args map[string]interface{}) {
-	g.waitGroup.Add(1)
+func (g *TaskGroupWithContext) submit(task func(context.Context) error) {
+	if err := g.limiter.wait(g.ctx); err != nil {
+		// Context was canceled while waiting for the task's turn, skip it
+		return
+	}
+	if !g.acquireSlot() {
+		return
+	}
+
+	g.pending.add(1)
+
+	index := int(atomic.AddInt64(&g.lastIndex, 1) - 1)
+
+	g.submitAttempt(task, index, g.label, 1, nil, nil)
+}
 
-	g.pool.Submit(func() {
-		defer g.waitGroup.Done()
+// submitAttempt sends the given attempt of a task of this group to the worker pool, along with the error
+// returned by the previous attempt and the checkpoint it resumes from, if any
+func (g *TaskGroupWithContext) submitAttempt(task func(context.Context) error, index int, label string, attempt int, lastErr error, checkpoint interface{}) {
+	g.pool.submit(g.newAttempt(task, index, label, attempt, lastErr, checkpoint), true)
+}
+
+// newAttempt wraps the given attempt of a task of this group, so it records its outcome in the group
+func (g *TaskGroupWithContext) newAttempt(task func(context.Context) error, index int, label string, attempt int, lastErr error, checkpoint interface{}) *queuedTask {
+	parent := g.ctx
+	if parent == nil {
+		parent = g.pool.taskContext
+	}
+
+	queued := newQueuedTask(nil)
+	queued.info.Label = label
+	queued.info.Attempt = attempt
+	queued.info.Checkpoint = checkpoint
+	queued.onReject = func(err error) {
+		g.fail(g.wrapError(err, index, label))
+		g.releaseSlot()
+		g.pending.remove(1)
+	}
+
+	queued.run = func() {
+		retrying := false
+		defer func() {
+			if !retrying {
+				g.releaseSlot()
+				g.pending.remove(1)
+			}
+		}()
 
-		// If context has already been cancelled, skip task execution
+		// If context has already been cancelled, skip task execution (keeping the error of the previous attempt)
 		if g.ctx != nil {
 			select {
 			case <-g.ctx.Done():
+				if lastErr != nil {
+					g.fail(g.wrapError(lastErr, index, label))
+				}
 				return
 			default:
 			}
 		}
 
-		// don't actually ignore errors
-		err := task(args)
-		if err != nil {
-			g.errSync.once.Do(func() {
-				g.errSync.guard.Lock()
-				g.err = err
-				g.errSync.guard.Unlock()
+		err := task(withTaskInfo(parent, queued))
+		if err != nil && g.retry.shouldRetry(err, attempt) {
+			if g.retry.ResumeFromCheckpoint {
+				checkpoint = g.pool.lastCheckpoint(queued)
+			}
+			retrying = true
+			go g.retryAttempt(task, index, label, attempt+1, err, checkpoint)
+			return
+		}
 
-				if g.cancel != nil {
-					g.cancel()
-				}
-			})
+		// The error is recorded before the context is cancelled and before the task is marked as completed,
+		// so it's visible to Wait regardless of which of the two events wakes it up
+		if err != nil {
+			g.fail(g.wrapError(err, index, label))
 		}
-	})
+	}
+
+	return queued
+}
+
+// acquireSlot waits for one of the running tasks of this group to complete if their concurrency is limited,
+// returning false if the group's context was canceled meanwhile
+func (g *TaskGroupWithContext) acquireSlot() bool {
+	if g.slots == nil {
+		return true
+	}
+
+	select {
+	case g.slots <- struct{}{}:
+		return true
+	case <-g.ctx.Done():
+		return false
+	}
+}
+
+// releaseSlot frees the slot of a task of this group that completed, if their concurrency is limited
+func (g *TaskGroupWithContext) releaseSlot() {
+	if g.slots != nil {
+		<-g.slots
+	}
+}
+
+// wrapError wraps the error returned by a task in a TaskError if the group collects all errors
+func (g *TaskGroupWithContext) wrapError(err error, index int, label string) error {
+	if g.errs.collectAll {
+		return &TaskError{Index: index, Label: label, Err: err}
+	}
+	return err
+}
+
+// fail records the error returned by a task of this group, canceling the group's context if it's the first one,
+// and propagates it to the parent group unless this subgroup isolates its errors
+func (g *TaskGroupWithContext) fail(err error) {
+	if g.errs.record(err) && g.cancel != nil {
+		g.cancel()
+	}
+	if g.parent != nil && !g.isolated {
+		g.parent.fail(err)
+	}
+}
+
+// Subgroup creates a task group whose tasks also count as tasks of this group, so that this group's Wait
+// waits for them too, and an associated Context derived from this group's context. The subgroup's context is
+// canceled when this group's context is canceled or when one of the subgroup's tasks fails. By default,
+// errors returned by the subgroup's tasks are also recorded by this group and cancel it, as if they had been
+// returned by its own tasks, unless the IsolateErrors option is given.
+func (g *TaskGroupWithContext) Subgroup(options ...GroupOption) (*TaskGroupWithContext, context.Context) {
+
+	ctx, cancel := context.WithCancel(g.ctx)
+	subgroup := &TaskGroupWithContext{
+		TaskGroup: TaskGroup{
+			pool:  g.pool,
+			label: g.label,
+		},
+		ctx:    ctx,
+		cancel: cancel,
+		parent: g,
+	}
+	subgroup.pending.parent = &g.pending
+
+	// Apply all options
+	for _, opt := range options {
+		opt(subgroup)
+	}
+
+	return subgroup, ctx
+}
+
+// Errors returns the errors returned by the tasks of this group so far. Unless the group was created with
+// the CollectErrors option, it contains at most the first error, otherwise it contains a TaskError per failed task.
+func (g *TaskGroupWithContext) Errors() []error {
+	return g.errs.allErrors()
 }
 
 // Wait blocks until either all the tasks submitted to this group have completed,
 // one of them returned a non-nil error or the context associated to this group
-// was canceled.
+// was canceled. If the group was created with the CollectErrors option, it always waits
+// for all tasks to complete and returns a GroupError if any of them failed.
+// If the group was created with GroupWithTimeout, Wait returns once the timeout expires at the latest,
+// with context.DeadlineExceeded if no task failed (or among the errors of the GroupError).
+// The group's context is canceled when Wait returns, unless the group was created with the KeepContext option.
 func (g *TaskGroupWithContext) Wait() error {
+	err := g.wait()
+	if !g.keepContext {
+		g.cancel()
+	}
+	return err
+}
 
-	// Wait for all tasks to complete
-	tasksCompleted := make(chan struct{})
-	go func() {
-		g.waitGroup.Wait()
-		tasksCompleted <- struct{}{}
-	}()
+// Cancel cancels the context of this group, which makes the tasks that did not start yet skip and releases
+// the resources associated with it. It's only needed for groups created with the KeepContext option, since
+// the context of other groups is canceled when Wait returns.
+func (g *TaskGroupWithContext) Cancel() {
+	g.cancel()
+}
+
+// wait waits for the tasks of this group as described by Wait, without canceling its context
+func (g *TaskGroupWithContext) wait() error {
 
+	if g.errs.collectAll {
+		timedOut := false
+		if !g.deadline.IsZero() {
+			timedOut = !g.pending.waitUntil(g.deadline)
+		} else {
+			<-g.pending.wait()
+		}
+
+		errs := g.errs.allErrors()
+		if timedOut {
+			errs = append(errs, context.DeadlineExceeded)
+		}
+		if len(errs) > 0 {
+			return &GroupError{Errors: errs}
+		}
+		return nil
+	}
+
+	// Wait for all tasks to complete
 	select {
-	case <-tasksCompleted:
-		// If context was provided, cancel it to signal all running tasks to stop
-		g.cancel()
+	case <-g.pending.wait():
+		return g.errs.firstError()
+	default:
+	}
+	select {
+	case <-g.pending.wait():
 	case <-g.ctx.Done():
+		err := g.errs.firstError()
+		if err == nil && !g.deadline.IsZero() && errors.Is(g.ctx.Err(), context.DeadlineExceeded) {
+			return context.DeadlineExceeded
+		}
+		return err
 	}
 
-	g.errSync.guard.RLock()
-	err := g.err
-	g.errSync.guard.RUnlock()
-
-	return err
+	return g.errs.firstError()
 }
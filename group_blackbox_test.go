@@ -3,6 +3,7 @@ package pond_test
 import (
 	"context"
 	"errors"
+	"runtime"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -120,3 +121,444 @@ func TestGroupContextWithNilContext(t *testing.T) {
 
 	assertEqual(t, "a non-nil context needs to be specified when using GroupContext", thrownPanic)
 }
+
+func TestGroupReuse(t *testing.T) {
+
+	pool := pond.New(5, 1000)
+	defer pool.StopAndWait()
+
+	group := pool.Group()
+
+	// Wait on an empty group returns immediately
+	group.Wait()
+
+	var doneCount int32
+	for batch := 1; batch <= 3; batch++ {
+		for i := 0; i < 10; i++ {
+			group.Submit(func() {
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&doneCount, 1)
+			})
+		}
+		group.Wait()
+
+		assertEqual(t, int32(batch*10), atomic.LoadInt32(&doneCount))
+	}
+}
+
+func TestGroupConcurrentWait(t *testing.T) {
+
+	pool := pond.New(5, 1000)
+	defer pool.StopAndWait()
+
+	group := pool.Group()
+
+	var doneCount int32
+	release := make(chan struct{})
+	group.Submit(func() {
+		<-release
+		atomic.AddInt32(&doneCount, 1)
+	})
+
+	waiters := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		go func() {
+			group.Wait()
+			waiters <- struct{}{}
+		}()
+	}
+
+	// Submit more tasks while waiting
+	group.Submit(func() {
+		atomic.AddInt32(&doneCount, 1)
+	})
+	close(release)
+
+	for i := 0; i < 3; i++ {
+		<-waiters
+	}
+
+	assertEqual(t, int32(2), atomic.LoadInt32(&doneCount))
+}
+
+func TestGroupContextConcurrentWait(t *testing.T) {
+
+	pool := pond.New(3, 100)
+	defer pool.StopAndWait()
+
+	group, _ := pool.GroupContext(context.Background())
+
+	sampleErr := errors.New("sample error")
+	group.Submit(func() error {
+		return sampleErr
+	})
+
+	errs := make(chan error)
+	for i := 0; i < 3; i++ {
+		go func() {
+			errs <- group.Wait()
+		}()
+	}
+
+	for i := 0; i < 3; i++ {
+		assertEqual(t, sampleErr, <-errs)
+	}
+
+	// Wait is idempotent
+	assertEqual(t, sampleErr, group.Wait())
+}
+
+func TestGroupContextWaitDoesNotLeakGoroutines(t *testing.T) {
+
+	pool := pond.New(1, 100)
+	defer pool.StopAndWait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	group, _ := pool.GroupContext(ctx)
+
+	release := make(chan struct{})
+	defer close(release)
+	group.Submit(func() error {
+		<-release
+		return nil
+	})
+
+	cancel()
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		assertEqual(t, nil, group.Wait())
+	}
+
+	// Waiting on a cancelled group while its tasks are still running must not leave goroutines behind
+	assertEqual(t, true, runtime.NumGoroutine() < before+10)
+}
+
+func TestGroupContextRemainsValidAfterSuccessfulWait(t *testing.T) {
+
+	pool := pond.New(3, 100)
+	defer pool.StopAndWait()
+
+	group, ctx := pool.GroupContext(context.Background(), pond.KeepContext())
+	group.Submit(func() error {
+		return nil
+	})
+
+	assertEqual(t, nil, group.Wait())
+	assertEqual(t, nil, ctx.Err())
+
+	// The group can be reused for follow-up work
+	var done int32
+	group.Submit(func() error {
+		atomic.AddInt32(&done, 1)
+		return nil
+	})
+
+	assertEqual(t, nil, group.Wait())
+	assertEqual(t, int32(1), atomic.LoadInt32(&done))
+
+	group.Cancel()
+	assertEqual(t, context.Canceled, ctx.Err())
+}
+
+func TestGroupContextIsCanceledAfterSuccessfulWait(t *testing.T) {
+
+	pool := pond.New(3, 100)
+	defer pool.StopAndWait()
+
+	group, ctx := pool.GroupContext(context.Background())
+	group.Submit(func() error {
+		return nil
+	})
+
+	assertEqual(t, nil, group.Wait())
+	assertEqual(t, context.Canceled, ctx.Err())
+
+	timed, timedCtx := pool.GroupWithTimeout(time.Minute)
+	timed.Submit(func() error {
+		return nil
+	})
+
+	assertEqual(t, nil, timed.Wait())
+	assertEqual(t, context.Canceled, timedCtx.Err())
+}
+
+func TestGroupContextCollectErrors(t *testing.T) {
+
+	pool := pond.New(3, 100)
+	defer pool.StopAndWait()
+
+	group, _ := pool.GroupContext(context.Background(), pond.CollectErrors())
+
+	err1 := errors.New("error 1")
+	err2 := errors.New("error 2")
+	started := make(chan struct{})
+	release := make(chan struct{})
+	group.Submit(func() error {
+		close(started)
+		<-release
+		return err2
+	})
+	<-started
+	group.SetLabel("fetch")
+	group.Submit(func() error {
+		defer close(release)
+		return err1
+	})
+	group.Submit(func() error {
+		return nil
+	})
+
+	// Wait waits for the task that failed after the context was cancelled
+	err := group.Wait()
+
+	assertEqual(t, "2 tasks failed: task 1 (fetch): error 1; task 0: error 2", err.Error())
+	assertEqual(t, true, errors.Is(err, err1))
+	assertEqual(t, true, errors.Is(err, err2))
+
+	errs := group.Errors()
+	assertEqual(t, 2, len(errs))
+
+	var taskErr *pond.TaskError
+	assertEqual(t, true, errors.As(errs[0], &taskErr))
+	assertEqual(t, 1, taskErr.Index)
+	assertEqual(t, "fetch", taskErr.Label)
+	assertEqual(t, err1, taskErr.Err)
+}
+
+func TestGroupContextErrorsWithoutCollecting(t *testing.T) {
+
+	pool := pond.New(3, 100)
+	defer pool.StopAndWait()
+
+	group, _ := pool.GroupContext(context.Background())
+
+	assertEqual(t, 0, len(group.Errors()))
+
+	sampleErr := errors.New("sample error")
+	group.Submit(func() error {
+		return sampleErr
+	})
+	group.Wait()
+
+	errs := group.Errors()
+	assertEqual(t, 1, len(errs))
+	assertEqual(t, sampleErr, errs[0])
+}
+
+func TestSubgroup(t *testing.T) {
+
+	pool := pond.New(4, 100)
+	defer pool.StopAndWait()
+
+	group := pool.Group()
+
+	var completed int32
+	for region := 0; region < 3; region++ {
+		subgroup := group.Subgroup()
+		for host := 0; host < 5; host++ {
+			subgroup.Submit(func() {
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&completed, 1)
+			})
+		}
+	}
+
+	// Waiting for the parent waits for the tasks of all subgroups
+	group.Wait()
+	assertEqual(t, int32(15), atomic.LoadInt32(&completed))
+}
+
+func TestSubgroupWithContext(t *testing.T) {
+
+	pool := pond.New(4, 100)
+	defer pool.StopAndWait()
+
+	group, ctx := pool.GroupContext(context.Background())
+	subgroup, subCtx := group.Subgroup()
+	isolated, isolatedCtx := group.Subgroup(pond.IsolateErrors())
+
+	isolated.Submit(func() error {
+		return errors.New("isolated failure")
+	})
+	assertEqual(t, "isolated failure", isolated.Wait().Error())
+	assertEqual(t, context.Canceled, isolatedCtx.Err())
+	assertEqual(t, nil, ctx.Err())
+
+	subgroup.Submit(func() error {
+		return errors.New("host failure")
+	})
+
+	// The subgroup's error cancels the parent
+	assertEqual(t, "host failure", group.Wait().Error())
+	assertEqual(t, context.Canceled, subCtx.Err())
+	assertEqual(t, context.Canceled, ctx.Err())
+}
+
+func TestGroupAddAndDone(t *testing.T) {
+
+	pool := pond.New(2, 10)
+	defer pool.StopAndWait()
+
+	group := pool.Group()
+
+	var completed int32
+	group.Submit(func() {
+		atomic.AddInt32(&completed, 1)
+	})
+
+	// Work executed inline is counted as well
+	group.Add(2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			defer group.Done()
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&completed, 1)
+		}()
+	}
+
+	group.Wait()
+	assertEqual(t, int32(3), atomic.LoadInt32(&completed))
+}
+
+func TestGroupDoneWithoutAdd(t *testing.T) {
+
+	pool := pond.New(1, 10)
+	defer pool.StopAndWait()
+
+	group := pool.Group()
+
+	defer func() {
+		assertEqual(t, "pond: more tasks marked as done than added to the group", recover())
+	}()
+	group.Done()
+}
+
+func TestGroupWithRate(t *testing.T) {
+
+	pool := pond.New(10, 100)
+	defer pool.StopAndWait()
+
+	group := pool.GroupWithRate(100)
+
+	start := time.Now()
+	var completed int32
+	for i := 0; i < 6; i++ {
+		group.Submit(func() {
+			atomic.AddInt32(&completed, 1)
+		})
+	}
+	group.Wait()
+
+	// Submissions are spaced by 10ms, so the last one happens at least 50ms after the first one
+	assertEqual(t, int32(6), atomic.LoadInt32(&completed))
+	assertEqual(t, true, time.Since(start) >= 50*time.Millisecond)
+}
+
+func TestGroupContextWithRateLimit(t *testing.T) {
+
+	pool := pond.New(10, 100)
+	defer pool.StopAndWait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Millisecond)
+	defer cancel()
+
+	group, _ := pool.GroupContext(ctx, pond.RateLimit(50))
+
+	var completed int32
+	for i := 0; i < 10; i++ {
+		group.Submit(func() error {
+			atomic.AddInt32(&completed, 1)
+			return nil
+		})
+	}
+
+	// Tasks whose turn came after the context expired are skipped
+	group.Wait()
+	assertEqual(t, context.DeadlineExceeded, ctx.Err())
+	assertEqual(t, true, atomic.LoadInt32(&completed) < 10)
+}
+
+func TestGroupWithTimeout(t *testing.T) {
+
+	pool := pond.New(2, 10)
+	defer pool.StopAndWait()
+
+	group, ctx := pool.GroupWithTimeout(20 * time.Millisecond)
+
+	group.Submit(func() error {
+		return nil
+	})
+	group.Submit(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	start := time.Now()
+	assertEqual(t, context.DeadlineExceeded, group.Wait())
+	assertEqual(t, true, time.Since(start) < time.Second)
+}
+
+func TestGroupWithTimeoutCompletedInTime(t *testing.T) {
+
+	pool := pond.New(2, 10)
+	defer pool.StopAndWait()
+
+	group, _ := pool.GroupWithTimeout(time.Second, pond.CollectErrors())
+
+	group.Submit(func() error {
+		return nil
+	})
+
+	assertEqual(t, nil, group.Wait())
+}
+
+func TestGroupWithTimeoutCollectErrors(t *testing.T) {
+
+	pool := pond.New(2, 10)
+	defer pool.StopAndWait()
+
+	group, ctx := pool.GroupWithTimeout(20*time.Millisecond, pond.CollectErrors())
+
+	started := make(chan struct{})
+	group.Submit(func() error {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	})
+	<-started
+	group.Submit(func() error {
+		return errors.New("failed")
+	})
+
+	// Errors collected so far are returned along with the deadline
+	err := group.Wait()
+	assertEqual(t, true, errors.Is(err, context.DeadlineExceeded))
+	assertEqual(t, context.Canceled, ctx.Err())
+}
+
+func TestGroupSubmitDetached(t *testing.T) {
+
+	pool := pond.New(1, 10)
+	defer pool.StopAndWait()
+
+	group, _ := pool.GroupContext(context.Background(), pond.CollectErrors())
+
+	var skipped, cleanedUp int32
+	group.Submit(func() error {
+		return errors.New("failed")
+	})
+	group.Submit(func() error {
+		atomic.AddInt32(&skipped, 1)
+		return nil
+	})
+	group.SubmitDetached(func() {
+		atomic.AddInt32(&cleanedUp, 1)
+	})
+
+	assertEqual(t, "1 tasks failed: task 0: failed", group.Wait().Error())
+
+	// Regular tasks are skipped once the group is canceled, but detached ones still run
+	assertEqual(t, int32(0), atomic.LoadInt32(&skipped))
+	assertEqual(t, int32(1), atomic.LoadInt32(&cleanedUp))
+}
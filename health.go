@@ -0,0 +1,193 @@
+package pond
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRejectionWindow defines the default window over which the rejection rate is computed when not
+	// explicitly specified in HealthCriteria
+	defaultRejectionWindow = 1 * time.Minute
+)
+
+var (
+	// ErrUnhealthy is wrapped by the errors returned by Healthy when a pool doesn't meet its health criteria
+	ErrUnhealthy = errors.New("worker pool is unhealthy")
+)
+
+// HealthCriteria defines the conditions under which a worker pool is considered unhealthy (see Healthy).
+// Criteria left at their zero value are not checked.
+type HealthCriteria struct {
+	// MaxSaturation is how long the queue can remain full before the pool is considered unhealthy
+	MaxSaturation time.Duration
+	// MaxTaskDuration is how long a task can run before the worker executing it is considered stuck
+	MaxTaskDuration time.Duration
+	// MaxRejectionRate is the maximum fraction (between 0 and 1) of submissions that can be rejected
+	// because the queue is full (e.g. by TrySubmit) within the rejection window
+	MaxRejectionRate float64
+	// RejectionWindow is the period over which the rejection rate is computed. Defaults to 1 minute.
+	RejectionWindow time.Duration
+}
+
+// HealthCheck allows to change the criteria used by Healthy to determine whether a worker pool is healthy
+func HealthCheck(criteria HealthCriteria) Option {
+	return func(pool *WorkerPool) {
+		pool.health = &healthMonitor{
+			criteria: criteria,
+		}
+	}
+}
+
+// healthMonitor tracks the conditions checked by the health criteria
+type healthMonitor struct {
+	criteria       HealthCriteria
+	saturatedSince time.Time
+	running        map[uint64]time.Time
+	windowStart    time.Time
+	submissions    uint64
+	rejections     uint64
+	// Submissions and rejections of the previous window
+	lastSubmissions uint64
+	lastRejections  uint64
+	mutex           sync.Mutex
+}
+
+// normalize makes sure the criteria are consistent
+func (m *healthMonitor) normalize() {
+	if m.criteria.RejectionWindow <= 0 {
+		m.criteria.RejectionWindow = defaultRejectionWindow
+	}
+	if m.criteria.MaxTaskDuration > 0 {
+		m.running = make(map[uint64]time.Time)
+	}
+}
+
+// updateSaturation records whether the queue is full at the given time
+func (m *healthMonitor) updateSaturation(saturated bool, now time.Time) {
+	if m == nil || m.criteria.MaxSaturation <= 0 {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !saturated {
+		m.saturatedSince = time.Time{}
+	} else if m.saturatedSince.IsZero() {
+		m.saturatedSince = now
+	}
+}
+
+// taskStarted records that a worker started executing the given task
+func (m *healthMonitor) taskStarted(id uint64, now time.Time) {
+	if m == nil || m.running == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.running[id] = now
+}
+
+// taskFinished records that a worker finished executing the given task
+func (m *healthMonitor) taskFinished(id uint64) {
+	if m == nil || m.running == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.running, id)
+}
+
+// recordSubmission records a task submission, and whether it was rejected because the queue was full
+func (m *healthMonitor) recordSubmission(rejected bool, now time.Time) {
+	if m == nil || m.criteria.MaxRejectionRate <= 0 {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.rotate(now)
+	m.submissions++
+	if rejected {
+		m.rejections++
+	}
+}
+
+// rotate starts a new rejection window if the current one is over. Must be called with the mutex held.
+func (m *healthMonitor) rotate(now time.Time) {
+	elapsed := now.Sub(m.windowStart)
+	if elapsed < m.criteria.RejectionWindow {
+		return
+	}
+
+	if elapsed < 2*m.criteria.RejectionWindow {
+		m.lastSubmissions, m.lastRejections = m.submissions, m.rejections
+	} else {
+		// No submissions were recorded during the previous window
+		m.lastSubmissions, m.lastRejections = 0, 0
+	}
+	m.submissions, m.rejections = 0, 0
+	m.windowStart = now
+}
+
+// check returns an error describing the first criterion that isn't met at the given time
+func (m *healthMonitor) check(now time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.criteria.MaxSaturation > 0 && !m.saturatedSince.IsZero() {
+		if saturation := now.Sub(m.saturatedSince); saturation > m.criteria.MaxSaturation {
+			return fmt.Errorf("%w: queue has been full for %v", ErrUnhealthy, saturation)
+		}
+	}
+
+	if m.criteria.MaxTaskDuration > 0 {
+		stuck := 0
+		for _, startedAt := range m.running {
+			if now.Sub(startedAt) > m.criteria.MaxTaskDuration {
+				stuck++
+			}
+		}
+		if stuck > 0 {
+			return fmt.Errorf("%w: %d workers have been running the same task for more than %v", ErrUnhealthy, stuck, m.criteria.MaxTaskDuration)
+		}
+	}
+
+	if m.criteria.MaxRejectionRate > 0 {
+		// The rate is computed over the previous and the current window
+		m.rotate(now)
+		submissions := m.lastSubmissions + m.submissions
+		rejections := m.lastRejections + m.rejections
+		if submissions > 0 {
+			if rate := float64(rejections) / float64(submissions); rate > m.criteria.MaxRejectionRate {
+				return fmt.Errorf("%w: %.0f%% of submissions were rejected", ErrUnhealthy, rate*100)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Healthy returns nil if this pool meets the criteria configured via the HealthCheck option, or an error
+// wrapping ErrUnhealthy that describes the first criterion that isn't met. It's suitable for readiness probes.
+// A stopped pool is always unhealthy, while a pool without health criteria is healthy as long as it's running.
+func (p *WorkerPool) Healthy() error {
+	if p.Stopped() {
+		return fmt.Errorf("%w: pool has been stopped", ErrUnhealthy)
+	}
+	if p.health == nil {
+		return nil
+	}
+
+	p.updatePressure()
+
+	return p.health.check(p.now())
+}
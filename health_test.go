@@ -0,0 +1,91 @@
+package pond_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestHealthyWithoutCriteria(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	assertEqual(t, nil, pool.Healthy())
+
+	pool.StopAndWait()
+
+	assertEqual(t, true, errors.Is(pool.Healthy(), pond.ErrUnhealthy))
+}
+
+func TestHealthySaturation(t *testing.T) {
+
+	pool := pond.New(1, 1, pond.HealthCheck(pond.HealthCriteria{
+		MaxSaturation: 10 * time.Millisecond,
+	}))
+
+	release := make(chan struct{})
+	pool.Submit(func() {
+		<-release
+	})
+	pool.Submit(func() {})
+
+	assertEqual(t, nil, pool.Healthy())
+
+	time.Sleep(20 * time.Millisecond)
+
+	assertEqual(t, true, errors.Is(pool.Healthy(), pond.ErrUnhealthy))
+
+	close(release)
+	pool.StopAndWait()
+}
+
+func TestHealthyStuckWorkers(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.HealthCheck(pond.HealthCriteria{
+		MaxTaskDuration: 10 * time.Millisecond,
+	}))
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	pool.Submit(func() {
+		<-release
+		close(done)
+	})
+
+	time.Sleep(20 * time.Millisecond)
+
+	err := pool.Healthy()
+	assertEqual(t, true, errors.Is(err, pond.ErrUnhealthy))
+	assertEqual(t, "worker pool is unhealthy: 1 workers have been running the same task for more than 10ms", err.Error())
+
+	close(release)
+	<-done
+	pool.StopAndWait()
+}
+
+func TestHealthyRejectionRate(t *testing.T) {
+
+	pool := pond.New(1, 0, pond.HealthCheck(pond.HealthCriteria{
+		MaxRejectionRate: 0.5,
+	}))
+
+	release := make(chan struct{})
+	pool.Submit(func() {
+		<-release
+	})
+
+	assertEqual(t, nil, pool.Healthy())
+
+	for i := 0; i < 3; i++ {
+		assertEqual(t, false, pool.TrySubmit(func() {}))
+	}
+
+	err := pool.Healthy()
+	assertEqual(t, true, errors.Is(err, pond.ErrUnhealthy))
+	assertEqual(t, "worker pool is unhealthy: 75% of submissions were rejected", err.Error())
+
+	close(release)
+	pool.StopAndWait()
+}
@@ -0,0 +1,74 @@
+package pond
+
+// SubmitHook is invoked before a task is queued (see SubmitHooks). It receives the metadata of the task and can
+// change its Label, Lane and Deadline, e.g. to reroute it or apply defaults, or veto the submission by returning
+// an error. Changes to other fields are ignored.
+type SubmitHook func(info *TaskInfo) error
+
+// SubmitHooks adds hooks that are invoked, in order, on the submitting goroutine before each task is queued,
+// e.g. to enforce admission control or tagging policies. Unlike code wrapping the tasks themselves, hooks run
+// before the task takes room in the queue. The first hook that returns an error vetoes the submission: the task
+// is dropped and reported to the dropped task handler (see DroppedTaskHandler) with DropVetoed, submission methods
+// that report failures return false or the hook's error (e.g. TrySubmit and SubmitFrom), and the tasks of groups
+// fail with the hook's error. Other submission methods, such as Submit, drop the task silently.
+func SubmitHooks(hooks ...SubmitHook) Option {
+	return func(pool *WorkerPool) {
+		pool.submitHooks = append(pool.submitHooks, hooks...)
+	}
+}
+
+// runSubmitHooks invokes the submit hooks on the given task, applying the changes they make to its metadata.
+// If a hook vetoes the submission, the task is rejected and reported as dropped, and the hook's error is returned.
+func (p *WorkerPool) runSubmitHooks(task *queuedTask) error {
+	if len(p.submitHooks) == 0 || task.internal {
+		return nil
+	}
+
+	info := task.info
+	if err := p.callSubmitHooks(&info); err != nil {
+		p.submitters.release(task.info.Submitter)
+		p.dropTask(DropVetoed, task.info)
+		task.reject(err)
+		return err
+	}
+
+	task.info.Label = info.Label
+	task.info.Deadline = info.Deadline
+	if info.Lane == InteractiveLane || info.Lane == BatchLane {
+		task.info.Lane = info.Lane
+	}
+	return nil
+}
+
+// callSubmitHooks invokes the submit hooks on the given task metadata, returning the error of the first one that vetoes it
+func (p *WorkerPool) callSubmitHooks(info *TaskInfo) error {
+	for _, hook := range p.submitHooks {
+		if err := hook(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// submitOrReject submits the given task, waiting for room in the queue if needed. If its submission is vetoed
+// by a submit hook, or it's dropped without being executed (e.g. because it expired), reject is invoked with the
+// hook's error or the reason's error instead, so helpers waiting for the task don't hang.
+func (p *WorkerPool) submitOrReject(task func(), reject func(err error)) {
+	queued := newQueuedTask(task)
+	queued.onReject = reject
+
+	p.submit(queued, true)
+}
+
+// submitWithInfo is like submitOrReject, but passes the metadata of the task to both functions
+func (p *WorkerPool) submitWithInfo(task func(info TaskInfo), reject func(info TaskInfo, err error)) {
+	queued := newQueuedTask(nil)
+	queued.run = func() {
+		task(queued.info)
+	}
+	queued.onReject = func(err error) {
+		reject(queued.info, err)
+	}
+
+	p.submit(queued, true)
+}
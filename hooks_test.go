@@ -0,0 +1,91 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestSubmitHooksChangeTasks(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.Lanes(0), pond.SubmitHooks(
+		func(info *pond.TaskInfo) error {
+			if info.Label == "" {
+				info.Label = "default"
+			}
+			return nil
+		},
+		func(info *pond.TaskInfo) error {
+			if info.Label == "report" {
+				info.Lane = pond.BatchLane
+			}
+			// Changes to other fields are ignored
+			info.ID = 0
+			return nil
+		},
+	))
+
+	var label string
+	var id uint64
+	pool.SubmitContext(func(ctx context.Context) {
+		info, _ := pond.FromContext(ctx)
+		label, id = info.Label, info.ID
+	})
+	pool.SubmitLabeled("report", func() {})
+	pool.StopAndWait()
+
+	assertEqual(t, "default", label)
+	assertEqual(t, true, id != 0)
+	assertEqual(t, uint64(1), pool.LaneStats(pond.BatchLane).Submitted)
+	assertEqual(t, uint64(1), pool.LabelStats()["report"].Successful)
+}
+
+func TestSubmitHooksVeto(t *testing.T) {
+
+	errVetoed := errors.New("vetoed")
+	var dropped int32
+	pool := pond.New(1, 10,
+		pond.SubmitHooks(func(info *pond.TaskInfo) error {
+			if info.Label == "blocked" || info.Submitter == "blocked" {
+				return errVetoed
+			}
+			return nil
+		}),
+		pond.DroppedTaskHandler(func(reason pond.DropReason, info pond.TaskInfo) {
+			if reason == pond.DropVetoed {
+				atomic.AddInt32(&dropped, 1)
+			}
+		}))
+	defer pool.StopAndWait()
+
+	var executed int32
+	task := func() {
+		atomic.AddInt32(&executed, 1)
+	}
+
+	pool.SubmitLabeled("blocked", task)
+	assertEqual(t, errVetoed, pool.SubmitFrom("blocked", task))
+	assertEqual(t, nil, pool.SubmitFrom("allowed", task))
+
+	// Groups report the veto instead of waiting forever
+	group := pool.Group()
+	group.SetLabel("blocked")
+	group.Submit(task)
+	group.Wait()
+
+	ctxGroup, _ := pool.GroupContext(context.Background())
+	ctxGroup.SetLabel("blocked")
+	ctxGroup.Submit(func() error {
+		return nil
+	})
+	assertEqual(t, errVetoed, ctxGroup.Wait())
+
+	pool.SubmitAndWait(task)
+
+	assertEqual(t, int32(4), atomic.LoadInt32(&dropped))
+	assertEqual(t, int32(2), atomic.LoadInt32(&executed))
+	assertEqual(t, "vetoed", pond.DropVetoed.String())
+}
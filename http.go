@@ -0,0 +1,62 @@
+package pond
+
+import (
+	"net/http"
+)
+
+// LimitHandler returns an http.Handler that executes each request's handler as a task of the given pool,
+// limiting the number of requests served concurrently to the pool's maximum number of workers.
+// Requests that cannot be queued because the pool is saturated (or stopped), and queued requests that are dropped
+// before they are served (e.g. because they expired, see MaxQueueAge), are rejected with 503 (Service Unavailable)
+// and a Retry-After header.
+func LimitHandler(pool *WorkerPool, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		done := make(chan struct{})
+		var panicked bool
+
+		queued := newQueuedTask(func() {
+			defer close(done)
+
+			defer func() {
+				if p := recover(); p != nil {
+					panicked = true
+
+					// Propagate the panic so it is reported by the pool's panic handler
+					panic(p)
+				}
+			}()
+
+			// Client went away while the request was queued, skip it
+			if r.Context().Err() != nil {
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+		queued.onDiscard = func(error) {
+			defer close(done)
+
+			serviceUnavailable(w)
+		}
+
+		if !pool.submit(queued, false) {
+			serviceUnavailable(w)
+			return
+		}
+
+		// The response writer must not be used once this function returns, so always wait for the task
+		<-done
+
+		if panicked {
+			// Abort the response, the panic was already reported by the pool
+			panic(http.ErrAbortHandler)
+		}
+	})
+}
+
+// serviceUnavailable rejects a request that could not be served by the pool
+func serviceUnavailable(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
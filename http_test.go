@@ -0,0 +1,108 @@
+package pond_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestLimitHandler(t *testing.T) {
+
+	pool := pond.New(1, 0)
+	defer pool.StopAndWait()
+
+	handler := pond.LimitHandler(pool, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assertEqual(t, http.StatusTeapot, rec.Code)
+	assertEqual(t, uint64(1), pool.SuccessfulTasks())
+}
+
+func TestLimitHandlerWhenSaturated(t *testing.T) {
+
+	pool := pond.New(1, 0)
+	defer pool.StopAndWait()
+
+	// Occupy the only worker
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	defer close(release)
+
+	handler := pond.LimitHandler(pool, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assertEqual(t, http.StatusServiceUnavailable, rec.Code)
+	assertEqual(t, "1", rec.Header().Get("Retry-After"))
+}
+
+func TestLimitHandlerWithPanic(t *testing.T) {
+
+	pool := pond.New(1, 0, pond.PanicHandler(func(interface{}) {}))
+	defer pool.StopAndWait()
+
+	handler := pond.LimitHandler(pool, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("handler failed")
+	}))
+
+	var thrownPanic interface{}
+	func() {
+		defer func() {
+			thrownPanic = recover()
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+
+	assertEqual(t, http.ErrAbortHandler, thrownPanic)
+	assertEqual(t, uint64(1), pool.FailedTasks())
+}
+
+func TestLimitHandlerWithExpiredRequest(t *testing.T) {
+
+	pool := pond.New(1, 1, pond.MaxQueueAge(time.Millisecond))
+	defer pool.StopAndWait()
+
+	// Occupy the only worker until the request expires
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	handler := pond.LimitHandler(pool, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	go func() {
+		for pool.WaitingTasks() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(5 * time.Millisecond)
+		close(release)
+	}()
+	assertReturns(t, func() {
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+
+	assertEqual(t, http.StatusServiceUnavailable, rec.Code)
+	assertEqual(t, "1", rec.Header().Get("Retry-After"))
+	assertEqual(t, uint64(1), pool.ExpiredTasks())
+}
@@ -0,0 +1,22 @@
+//go:build !pond_debug
+
+package pond
+
+// invariants holds the state needed to check the internal invariants of a pool, which are only checked
+// in builds with the pond_debug tag (see invariants_debug.go)
+type invariants struct{}
+
+// taskInvariants holds the state needed to check the internal invariants of a task
+type taskInvariants struct{}
+
+// checkInvariants panics if the counters of the pool are inconsistent, in builds with the pond_debug tag
+func (p *WorkerPool) checkInvariants() {}
+
+// checkWorkerLimit panics if more workers than the given maximum are running, in builds with the pond_debug tag
+func (p *WorkerPool) checkWorkerLimit(maxWorkers int) {}
+
+// settleTask records that the given task was executed or dropped after being accepted, in builds with the pond_debug tag
+func (p *WorkerPool) settleTask(task *queuedTask) {}
+
+// checkSettled panics if a task accepted by the stopped pool was neither executed nor dropped, in builds with the pond_debug tag
+func (p *WorkerPool) checkSettled() {}
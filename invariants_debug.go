@@ -0,0 +1,83 @@
+//go:build pond_debug
+
+package pond
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// invariants holds the state needed to check the internal invariants of a pool
+type invariants struct {
+	// Number of accepted tasks that were executed or dropped
+	settled uint64
+}
+
+// taskInvariants holds the state needed to check the internal invariants of a task
+type taskInvariants struct {
+	// Set to 1 once the task is executed or dropped
+	settled int32
+}
+
+// violated panics to report a broken invariant
+func (p *WorkerPool) violated(format string, args ...interface{}) {
+	panic(fmt.Sprintf("pond: invariant violated in pool %q: %s", p.name, fmt.Sprintf(format, args...)))
+}
+
+// checkInvariants panics if the counters of the pool are inconsistent
+func (p *WorkerPool) checkInvariants() {
+
+	// Unsigned counters that are decremented would wrap around if they went negative
+	if waiting := atomic.LoadUint64(&p.waitingTaskCount.value); int64(waiting) < 0 {
+		p.violated("waiting task count is negative (%d)", int64(waiting))
+	}
+	settled := atomic.LoadUint64(&p.invariants.settled)
+	if submitted := atomic.LoadUint64(&p.submittedTaskCount.value); int64(submitted) < 0 {
+		p.violated("submitted task count is negative (%d)", int64(submitted))
+	} else if settled > submitted {
+		p.violated("%d tasks were executed or dropped but only %d were submitted", settled, submitted)
+	}
+
+	// Worker counts are reset when the pool stops, while workers are still exiting
+	if p.Stopped() {
+		return
+	}
+	if workers := p.RunningWorkers(); workers < 0 {
+		p.violated("running worker count is negative (%d)", workers)
+	}
+	if idle := p.IdleWorkers(); idle < 0 {
+		p.violated("idle worker count is negative (%d)", idle)
+	}
+}
+
+// checkWorkerLimit panics if more workers than the given maximum are running
+func (p *WorkerPool) checkWorkerLimit(maxWorkers int) {
+	if workers := p.RunningWorkers(); workers > maxWorkers {
+		p.violated("%d workers are running but the maximum is %d", workers, maxWorkers)
+	}
+	p.checkInvariants()
+}
+
+// settleTask records that the given task was executed or dropped after being accepted
+func (p *WorkerPool) settleTask(task *queuedTask) {
+	if !atomic.CompareAndSwapInt32(&task.invariants.settled, 0, 1) {
+		p.violated("task %d was executed or dropped twice", task.info.ID)
+	}
+	atomic.AddUint64(&p.invariants.settled, 1)
+	p.checkInvariants()
+}
+
+// checkSettled panics if a task accepted by the stopped pool was neither executed nor dropped
+func (p *WorkerPool) checkSettled() {
+	// Submitters that were waiting for room in the queue when it was closed may still be reverting the accounting
+	// of their task
+	p.tasksWaitGroup.Wait()
+
+	settled, submitted := atomic.LoadUint64(&p.invariants.settled), p.SubmittedTasks()
+	if settled != submitted {
+		p.violated("%d tasks were submitted but %d were executed or dropped", submitted, settled)
+	}
+	if waiting := p.WaitingTasks(); waiting != 0 {
+		p.violated("%d tasks are still waiting after the pool stopped", waiting)
+	}
+}
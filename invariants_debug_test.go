@@ -0,0 +1,47 @@
+//go:build pond_debug
+
+package pond
+
+import (
+	"strings"
+	"testing"
+)
+
+// violation runs f and returns the invariant violation it panicked with, if any
+func violation(f func()) (message string) {
+	defer func() {
+		message, _ = recover().(string)
+	}()
+	f()
+	return
+}
+
+func TestInvariantViolationPanics(t *testing.T) {
+
+	pool := New(1, 1)
+
+	// Settling a task that was never submitted breaks the task accounting
+	message := violation(func() {
+		pool.settleTask(newQueuedTask(func() {}))
+	})
+	assertEqual(t, true, strings.HasPrefix(message, "pond: invariant violated"))
+
+	// Account for the task so the pool can stop
+	pool.submittedTaskCount.value = 1
+	pool.StopAndWait()
+}
+
+func TestTaskSettledTwicePanics(t *testing.T) {
+
+	pool := New(1, 1)
+	pool.submittedTaskCount.value = 1
+
+	task := newQueuedTask(func() {})
+	message := violation(func() {
+		pool.settleTask(task)
+		pool.settleTask(task)
+	})
+	assertEqual(t, true, strings.Contains(message, "executed or dropped twice"))
+
+	pool.StopAndWait()
+}
@@ -0,0 +1,143 @@
+package pond
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// KeyedExecutor runs tasks on a worker pool so that tasks sharing the same key are executed serially,
+// in submission order, while tasks with different keys run in parallel
+type KeyedExecutor[K comparable] struct {
+	// Number of tasks that panicked, placed first so it's 64-bit aligned on 32-bit platforms
+	failedTaskCount uint64
+	pool            *WorkerPool
+	pending         map[K][]keyedTask
+	mutex           sync.Mutex
+	waitGroup       sync.WaitGroup
+}
+
+// keyedTask is a task waiting to be executed by a KeyedExecutor, along with the function that is invoked instead
+// if its submission is vetoed (see SubmitHooks), if any
+type keyedTask struct {
+	run    func()
+	reject func(err error)
+}
+
+// NewKeyedExecutor creates a keyed executor that runs its tasks on the given worker pool
+func NewKeyedExecutor[K comparable](pool *WorkerPool) *KeyedExecutor[K] {
+	return &KeyedExecutor[K]{
+		pool:    pool,
+		pending: make(map[K][]keyedTask),
+	}
+}
+
+// Submit sends a task to be executed once all tasks previously submitted with the same key have completed.
+// If the pool drops the task that runs the tasks of a key (e.g. because it expired or the pool was stopped), the tasks
+// waiting for that key are skipped too.
+func (e *KeyedExecutor[K]) Submit(key K, task func()) {
+	e.submitOrReject(key, task, nil)
+}
+
+// submitOrReject is like Submit, but invokes reject instead of the task if its submission is vetoed
+// (see SubmitHooks), so callers can release the resources the task would have released
+func (e *KeyedExecutor[K]) submitOrReject(key K, task func(), reject func(err error)) {
+	if task == nil {
+		return
+	}
+
+	e.waitGroup.Add(1)
+
+	e.mutex.Lock()
+	if queue, running := e.pending[key]; running {
+		// Another task with the same key is running, it will pick this one up when done
+		e.pending[key] = append(queue, keyedTask{run: task, reject: reject})
+		e.mutex.Unlock()
+		return
+	}
+	e.pending[key] = nil
+	e.mutex.Unlock()
+
+	e.start(key, keyedTask{run: task, reject: reject})
+}
+
+// start submits a task that runs the given task and then all the tasks queued under the same key. If its submission
+// is vetoed (see SubmitHooks), the task is rejected and the next one is submitted instead. If it's dropped without
+// being executed (e.g. because it expired), the task and all the ones queued under the same key are rejected, so the
+// key is released.
+func (e *KeyedExecutor[K]) start(key K, task keyedTask) {
+	queued := newQueuedTask(nil)
+	queued.run = func() {
+		e.run(key, task, queued.info)
+	}
+	queued.onReject = func(err error) {
+		if task.reject != nil {
+			task.reject(err)
+		}
+		e.waitGroup.Done()
+		if next, ok := e.next(key); ok {
+			e.start(key, next)
+		}
+	}
+	queued.onDiscard = func(err error) {
+		for ok := true; ok; task, ok = e.next(key) {
+			if task.reject != nil {
+				task.reject(err)
+			}
+			e.waitGroup.Done()
+		}
+	}
+	e.pool.submit(queued, true)
+}
+
+// Wait waits until all the tasks submitted to this executor have completed
+func (e *KeyedExecutor[K]) Wait() {
+	e.waitGroup.Wait()
+}
+
+// FailedTasks returns the number of tasks of this executor that completed with panic. They are not counted by the
+// pool's FailedTasks, since the pool task that runs the tasks of a key recovers their panics and carries on.
+func (e *KeyedExecutor[K]) FailedTasks() uint64 {
+	return atomic.LoadUint64(&e.failedTaskCount)
+}
+
+// run executes the given task and then all the tasks queued under the same key, one after the other.
+// They share the metadata of the pool task that runs them.
+func (e *KeyedExecutor[K]) run(key K, task keyedTask, info TaskInfo) {
+	for ok := true; ok; task, ok = e.next(key) {
+		e.execute(task.run, info)
+	}
+}
+
+// execute runs a single task, reporting panics to the pool's panic handler (and counting the task as failed)
+// so the remaining tasks of the same key are still executed
+func (e *KeyedExecutor[K]) execute(task func(), info TaskInfo) {
+
+	defer func() {
+		if panic := recover(); panic != nil {
+			atomic.AddUint64(&e.failedTaskCount, 1)
+			e.pool.handlePanic(panic, info)
+		}
+		e.waitGroup.Done()
+	}()
+
+	task()
+}
+
+// next dequeues the following task for the given key, or returns false if there is none left
+func (e *KeyedExecutor[K]) next(key K) (keyedTask, bool) {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	queue := e.pending[key]
+	if len(queue) == 0 {
+		delete(e.pending, key)
+		return keyedTask{}, false
+	}
+
+	task := queue[0]
+	queue[0] = keyedTask{}
+	e.pending[key] = queue[1:]
+
+	return task, true
+}
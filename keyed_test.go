@@ -0,0 +1,115 @@
+package pond_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestKeyedExecutorPreservesOrderPerKey(t *testing.T) {
+
+	pool := pond.New(4, 100)
+	defer pool.StopAndWait()
+
+	executor := pond.NewKeyedExecutor[string](pool)
+
+	var mutex sync.Mutex
+	results := make(map[string][]int)
+	for i := 0; i < 20; i++ {
+		n := i
+		key := "even"
+		if n%2 == 1 {
+			key = "odd"
+		}
+		executor.Submit(key, func() {
+			time.Sleep(100 * time.Microsecond)
+			mutex.Lock()
+			results[key] = append(results[key], n)
+			mutex.Unlock()
+		})
+	}
+
+	executor.Wait()
+
+	assertEqual(t, 10, len(results["even"]))
+	assertEqual(t, 10, len(results["odd"]))
+	for _, values := range results {
+		for i := 1; i < len(values); i++ {
+			if values[i] < values[i-1] {
+				t.Fatalf("Tasks executed out of order: %v", values)
+			}
+		}
+	}
+}
+
+func TestKeyedExecutorWithPanic(t *testing.T) {
+
+	var panicCount int32
+	var panicInfo pond.TaskInfo
+	pool := pond.New(1, 10, pond.TaskPanicHandler(func(panic interface{}, info pond.TaskInfo) {
+		atomic.AddInt32(&panicCount, 1)
+		panicInfo = info
+	}))
+
+	executor := pond.NewKeyedExecutor[int](pool)
+
+	var doneCount int32
+	executor.Submit(1, func() {
+		panic("boom")
+	})
+	executor.Submit(1, func() {
+		atomic.AddInt32(&doneCount, 1)
+	})
+	executor.Submit(1, nil)
+
+	executor.Wait()
+
+	assertEqual(t, int32(1), atomic.LoadInt32(&panicCount))
+	assertEqual(t, int32(1), atomic.LoadInt32(&doneCount))
+	assertEqual(t, true, panicInfo.ID > 0)
+	assertEqual(t, uint64(1), executor.FailedTasks())
+
+	// The pool task that ran the tasks of the key recovered the panic
+	pool.StopAndWait()
+	assertEqual(t, uint64(0), pool.FailedTasks())
+	assertEqual(t, pool.SubmittedTasks(), pool.CompletedTasks())
+}
+
+func TestKeyedExecutorWithExpiredTasks(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MaxQueueAge(time.Millisecond))
+	defer pool.StopAndWait()
+
+	executor := pond.NewKeyedExecutor[string](pool)
+
+	// Occupy the worker until the tasks of the key expire
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var ran int32
+	for i := 0; i < 3; i++ {
+		executor.Submit("a", func() {
+			atomic.AddInt32(&ran, 1)
+		})
+	}
+	time.Sleep(5 * time.Millisecond)
+	close(release)
+
+	assertReturns(t, executor.Wait)
+	assertEqual(t, int32(0), atomic.LoadInt32(&ran))
+
+	// The key was released
+	executor.Submit("a", func() {
+		atomic.AddInt32(&ran, 1)
+	})
+	assertReturns(t, executor.Wait)
+	assertEqual(t, int32(1), atomic.LoadInt32(&ran))
+}
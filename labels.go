@@ -0,0 +1,199 @@
+package pond
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMaxLabels defines the default maximum number of distinct labels tracked when not explicitly specified
+	// via the MaxLabels() option
+	defaultMaxLabels = 100
+
+	// OverflowLabel is the label under which tasks are accounted once the maximum number of distinct labels is reached
+	OverflowLabel = "__overflow__"
+)
+
+// DefaultDurationBuckets are the upper bounds of the buckets of task duration histograms
+var DefaultDurationBuckets = []time.Duration{
+	1 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// MaxLabels allows to change the maximum number of distinct labels for which statistics are kept.
+// Tasks with labels beyond that limit are accounted under OverflowLabel.
+func MaxLabels(maxLabels int) Option {
+	return func(pool *WorkerPool) {
+		pool.labels.maxLabels = maxLabels
+	}
+}
+
+// DurationHistogram is a histogram of durations, such as task execution times or queue waits
+type DurationHistogram struct {
+	// Bounds are the upper bounds of the buckets, in increasing order
+	Bounds []time.Duration `json:"bounds"`
+	// Counts holds the number of observations that fell in each bucket. It has one more element than Bounds,
+	// which counts the observations greater than the last bound.
+	Counts []uint64 `json:"counts"`
+	// Count is the total number of observations
+	Count uint64 `json:"count"`
+	// Sum is the sum of all observed durations
+	Sum time.Duration `json:"sum"`
+}
+
+// newDurationHistogram creates an empty histogram with the given bucket bounds
+func newDurationHistogram(bounds []time.Duration) DurationHistogram {
+	return DurationHistogram{
+		Bounds: bounds,
+		Counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// durationBucket returns the index of the bucket the given duration falls in
+func durationBucket(bounds []time.Duration, duration time.Duration) int {
+	i := 0
+	for i < len(bounds) && duration > bounds[i] {
+		i++
+	}
+	return i
+}
+
+// observe records a duration in the histogram
+func (h *DurationHistogram) observe(duration time.Duration) {
+	h.Counts[durationBucket(h.Bounds, duration)]++
+	h.Count++
+	h.Sum += duration
+}
+
+// merge adds the observations of the given histogram, which must have the same bounds, to this one
+func (h *DurationHistogram) merge(other DurationHistogram) {
+	if h.Counts == nil {
+		*h = newDurationHistogram(other.Bounds)
+	}
+	for i, count := range other.Counts {
+		h.Counts[i] += count
+	}
+	h.Count += other.Count
+	h.Sum += other.Sum
+}
+
+// Quantile returns an upper estimate of the given quantile (between 0 and 1), which is the upper bound
+// of the bucket the quantile falls in. Quantiles beyond the last bound are reported as the last bound,
+// and 0 is returned if the histogram is empty.
+func (h DurationHistogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 || len(h.Bounds) == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(q * float64(h.Count)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	var seen uint64
+	for i, bound := range h.Bounds {
+		seen += h.Counts[i]
+		if seen >= rank {
+			return bound
+		}
+	}
+	return h.Bounds[len(h.Bounds)-1]
+}
+
+// LabelStats holds the counters of the tasks sharing a label
+type LabelStats struct {
+	Successful uint64 `json:"successful"`
+	Failed     uint64 `json:"failed"`
+	Expired    uint64 `json:"expired"`
+	// AllocatedBytes is the memory allocated by the tasks, if the pool measures allocations (see MeasureAllocations)
+	AllocatedBytes uint64 `json:"allocatedBytes"`
+	// Durations tracks how long the tasks took to execute, whether they succeeded or failed
+	Durations DurationHistogram `json:"durations"`
+}
+
+// labelMetrics keeps statistics for labeled tasks, up to a maximum number of distinct labels
+type labelMetrics struct {
+	maxLabels int
+	byLabel   map[string]*LabelStats
+	mutex     sync.Mutex
+}
+
+func newLabelMetrics() *labelMetrics {
+	return &labelMetrics{
+		maxLabels: defaultMaxLabels,
+		byLabel:   make(map[string]*LabelStats),
+	}
+}
+
+// get returns the statistics for the given label, creating them if necessary. Must be called with the mutex held.
+func (m *labelMetrics) get(label string) *LabelStats {
+	stats, ok := m.byLabel[label]
+	if ok {
+		return stats
+	}
+
+	if len(m.byLabel) >= m.maxLabels {
+		label = OverflowLabel
+		if stats, ok = m.byLabel[label]; ok {
+			return stats
+		}
+	}
+
+	stats = &LabelStats{
+		Durations: newDurationHistogram(DefaultDurationBuckets),
+	}
+	m.byLabel[label] = stats
+	return stats
+}
+
+// record updates the statistics of the label of the given task with its outcome
+func (m *labelMetrics) record(info TaskInfo, outcome taskOutcome) {
+	if info.Label == "" {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stats := m.get(info.Label)
+	switch outcome {
+	case taskSucceeded:
+		stats.Successful++
+		stats.Durations.observe(info.Duration)
+		stats.AllocatedBytes += info.AllocatedBytes
+	case taskFailed:
+		stats.Failed++
+		stats.Durations.observe(info.Duration)
+		stats.AllocatedBytes += info.AllocatedBytes
+	case taskExpired:
+		stats.Expired++
+	}
+}
+
+// snapshot returns a copy of the statistics of all labels
+func (m *labelMetrics) snapshot() map[string]LabelStats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	snapshot := make(map[string]LabelStats, len(m.byLabel))
+	for label, stats := range m.byLabel {
+		copied := *stats
+		copied.Durations.Counts = append([]uint64(nil), stats.Durations.Counts...)
+		snapshot[label] = copied
+	}
+	return snapshot
+}
+
+// LabelStats returns the statistics of labeled tasks, broken out by label. Unlabeled tasks are only
+// accounted in the pool-level counters.
+func (p *WorkerPool) LabelStats() map[string]LabelStats {
+	return p.labels.snapshot()
+}
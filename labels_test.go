@@ -0,0 +1,57 @@
+package pond_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestLabelStats(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.PanicHandler(func(interface{}) {}))
+
+	for i := 0; i < 3; i++ {
+		pool.SubmitLabeled("sync", func() {
+			time.Sleep(2 * time.Millisecond)
+		})
+	}
+	pool.SubmitLabeled("reports", func() {
+		panic("boom")
+	})
+	pool.SubmitWithDeadline(func() {}, time.Now().Add(-1*time.Second))
+	pool.Submit(func() {})
+
+	pool.StopAndWait()
+
+	stats := pool.LabelStats()
+	assertEqual(t, 2, len(stats))
+
+	sync := stats["sync"]
+	assertEqual(t, uint64(3), sync.Successful)
+	assertEqual(t, uint64(3), sync.Durations.Count)
+	assertEqual(t, uint64(0), sync.Durations.Counts[0])
+	assertEqual(t, true, sync.Durations.Sum >= 6*time.Millisecond)
+	assertEqual(t, len(pond.DefaultDurationBuckets)+1, len(sync.Durations.Counts))
+
+	reports := stats["reports"]
+	assertEqual(t, uint64(1), reports.Failed)
+	assertEqual(t, uint64(1), reports.Durations.Count)
+}
+
+func TestLabelStatsWithCardinalityCap(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MaxLabels(2))
+
+	for _, label := range []string{"a", "b", "c", "d", "a"} {
+		pool.SubmitLabeled(label, func() {})
+	}
+
+	pool.StopAndWait()
+
+	stats := pool.LabelStats()
+	assertEqual(t, 3, len(stats))
+	assertEqual(t, uint64(2), stats["a"].Successful)
+	assertEqual(t, uint64(1), stats["b"].Successful)
+	assertEqual(t, uint64(2), stats[pond.OverflowLabel].Successful)
+}
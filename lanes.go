@@ -0,0 +1,238 @@
+package pond
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// Lane identifies the lane of a task in two-lane mode (see Lanes)
+type Lane int
+
+const (
+	// InteractiveLane is the lane of latency-sensitive tasks. It's the lane of tasks submitted with Submit and
+	// the other submission methods, except SubmitBatch.
+	InteractiveLane Lane = iota
+	// BatchLane is the lane of throughput-oriented tasks submitted with SubmitBatch
+	BatchLane
+)
+
+// String returns the name of the lane
+func (l Lane) String() string {
+	switch l {
+	case InteractiveLane:
+		return "interactive"
+	case BatchLane:
+		return "batch"
+	}
+	return fmt.Sprintf("Lane(%d)", int(l))
+}
+
+// Lanes enables two-lane mode, in which queued interactive tasks are dequeued before batch tasks (see SubmitBatch).
+// If interactivePerBatch is greater than zero, one batch task is dequeued after that many interactive tasks
+// whenever both lanes have tasks waiting, so batch tasks are not starved. Otherwise, interactive tasks have
+// strict priority. Within each lane, tasks are dequeued in the pool's queue order.
+func Lanes(interactivePerBatch int) Option {
+	return func(pool *WorkerPool) {
+		pool.lanes = &laneMetrics{
+			interactivePerBatch: interactivePerBatch,
+		}
+	}
+}
+
+// LaneStats holds the counters of the tasks of a lane
+type LaneStats struct {
+	// Submitted is the number of tasks submitted to the lane
+	Submitted uint64 `json:"submitted"`
+	// Waiting is the number of tasks of the lane waiting to start
+	Waiting uint64 `json:"waiting"`
+	// Completed is the number of tasks of the lane that ran, whether they succeeded or failed
+	Completed uint64 `json:"completed"`
+}
+
+// laneMetrics holds the settings and counters of two-lane mode
+// The counters are placed first so they are 64-bit aligned on 32-bit platforms.
+type laneMetrics struct {
+	submitted           [2]uint64
+	waiting             [2]uint64
+	completed           [2]uint64
+	interactivePerBatch int
+}
+
+// submit records a task submitted to the given lane
+func (m *laneMetrics) submit(lane Lane) {
+	if m == nil {
+		return
+	}
+	atomic.AddUint64(&m.submitted[lane], 1)
+	atomic.AddUint64(&m.waiting[lane], 1)
+}
+
+// unsubmit reverts the submission of a task that was not accepted by the pool
+func (m *laneMetrics) unsubmit(lane Lane) {
+	if m == nil {
+		return
+	}
+	atomic.AddUint64(&m.submitted[lane], ^uint64(0))
+	atomic.AddUint64(&m.waiting[lane], ^uint64(0))
+}
+
+// dequeue records a task of the given lane that left the queue, either to run or because it was dropped
+func (m *laneMetrics) dequeue(lane Lane) {
+	if m == nil {
+		return
+	}
+	atomic.AddUint64(&m.waiting[lane], ^uint64(0))
+}
+
+// complete records a task of the given lane that ran
+func (m *laneMetrics) complete(lane Lane) {
+	if m == nil {
+		return
+	}
+	atomic.AddUint64(&m.completed[lane], 1)
+}
+
+// LaneStats returns the counters of the given lane. They are only tracked in two-lane mode (see Lanes).
+func (p *WorkerPool) LaneStats(lane Lane) LaneStats {
+	if p.lanes == nil || lane < InteractiveLane || lane > BatchLane {
+		return LaneStats{}
+	}
+	return LaneStats{
+		Submitted: atomic.LoadUint64(&p.lanes.submitted[lane]),
+		Waiting:   atomic.LoadUint64(&p.lanes.waiting[lane]),
+		Completed: atomic.LoadUint64(&p.lanes.completed[lane]),
+	}
+}
+
+// SubmitBatch sends a throughput-oriented task to this worker pool for execution, just like Submit.
+// In two-lane mode (see Lanes), it's queued in the batch lane, behind interactive tasks.
+func (p *WorkerPool) SubmitBatch(task func()) {
+	queued := newQueuedTask(task)
+	queued.info.Lane = BatchLane
+
+	p.submit(queued, true)
+}
+
+// SubmitBatchContext sends a context-aware task to this worker pool for execution, just like SubmitContext.
+// In two-lane mode (see Lanes), it's queued in the batch lane, behind interactive tasks.
+func (p *WorkerPool) SubmitBatchContext(task func(ctx context.Context)) {
+	queued := newContextTask(p.taskContext, task)
+	queued.info.Lane = BatchLane
+
+	p.submit(queued, true)
+}
+
+// laneBuffer is a task buffer with an interactive and a batch lane, each one holding a buffer in the pool's order
+// (weighted by label if the pool has label weights).
+// If maxBatch is greater than zero, it's a gated buffer that lets at most maxBatch batch tasks run at the same time.
+type laneBuffer struct {
+	lanes               [2]taskBuffer
+	interactivePerBatch int
+	// Number of interactive tasks dequeued in a row while batch tasks were waiting
+	streak int
+	// Maximum number of batch tasks that can run at the same time, if limited, and the number of running ones
+	maxBatch     int
+	runningBatch int
+}
+
+// newLaneBuffer creates a two-lane task buffer, creating the buffer of each lane with newBuffer
+func newLaneBuffer(newBuffer func() taskBuffer, interactivePerBatch int, maxBatch int) taskBuffer {
+	buffer := &laneBuffer{
+		lanes:               [2]taskBuffer{newBuffer(), newBuffer()},
+		interactivePerBatch: interactivePerBatch,
+		maxBatch:            maxBatch,
+	}
+	if maxBatch > 0 {
+		return &gatedLaneBuffer{buffer}
+	}
+	return buffer
+}
+
+func (b *laneBuffer) Push(task *queuedTask) {
+	b.lanes[task.info.Lane].Push(task)
+}
+
+func (b *laneBuffer) PushFront(task *queuedTask) {
+	b.lanes[task.info.Lane].PushFront(task)
+}
+
+func (b *laneBuffer) Pop() *queuedTask {
+	interactive, batch := b.lanes[InteractiveLane], b.lanes[BatchLane]
+
+	if batch.Len() == 0 || (b.maxBatch > 0 && b.runningBatch >= b.maxBatch && interactive.Len() > 0) {
+		b.streak = 0
+		return interactive.Pop()
+	}
+	if interactive.Len() == 0 || (b.interactivePerBatch > 0 && b.streak >= b.interactivePerBatch) {
+		b.streak = 0
+		if b.maxBatch > 0 {
+			b.runningBatch++
+		}
+		return batch.Pop()
+	}
+
+	b.streak++
+	return interactive.Pop()
+}
+
+// laneTaskBuffer is a task buffer that can dequeue tasks from a specific lane
+type laneTaskBuffer interface {
+	PopLane(lane Lane) *queuedTask
+}
+
+// PopLane dequeues the next task of the given lane, or returns nil if the lane is empty.
+// Batch tasks are not dequeued this way if the buffer limits them, so they can't exceed the limit.
+func (b *laneBuffer) PopLane(lane Lane) *queuedTask {
+	if b.lanes[lane].Len() == 0 || (lane == BatchLane && b.maxBatch > 0) {
+		return nil
+	}
+	return b.lanes[lane].Pop()
+}
+
+func (b *laneBuffer) Len() int {
+	return b.lanes[InteractiveLane].Len() + b.lanes[BatchLane].Len()
+}
+
+func (b *laneBuffer) Remove(match func(task *queuedTask) bool) []*queuedTask {
+	return append(b.lanes[InteractiveLane].Remove(match), b.lanes[BatchLane].Remove(match)...)
+}
+
+func (b *laneBuffer) Each(visit func(task *queuedTask) bool) bool {
+	return b.lanes[InteractiveLane].Each(visit) && b.lanes[BatchLane].Each(visit)
+}
+
+// gatedLaneBuffer is a lane buffer that limits the number of batch tasks running at the same time
+type gatedLaneBuffer struct {
+	*laneBuffer
+}
+
+func (b *gatedLaneBuffer) Ready() bool {
+	return b.lanes[InteractiveLane].Len() > 0 || (b.lanes[BatchLane].Len() > 0 && b.runningBatch < b.maxBatch)
+}
+
+func (b *gatedLaneBuffer) Admit(task *queuedTask) bool {
+	if task.info.Lane != BatchLane {
+		return true
+	}
+	if b.runningBatch >= b.maxBatch {
+		return false
+	}
+	b.runningBatch++
+	return true
+}
+
+func (b *gatedLaneBuffer) Release(task *queuedTask) {
+	if task.info.Lane == BatchLane {
+		b.runningBatch--
+	}
+}
+
+// ReserveWorkers makes a pool in two-lane mode (see Lanes) keep the given number of workers available exclusively
+// for interactive tasks, by letting at most maxWorkers minus that number of batch tasks run at the same time.
+// This way, interactive tasks never wait behind a full set of long batch tasks.
+func ReserveWorkers(workers int) Option {
+	return func(pool *WorkerPool) {
+		pool.reservedWorkers = workers
+	}
+}
@@ -0,0 +1,108 @@
+package pond_test
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+// runLanes occupies the only worker of the pool, queues 3 batch tasks followed by 3 interactive ones
+// and returns the order in which they ran
+func runLanes(pool *pond.WorkerPool) string {
+	release := make(chan struct{})
+	pool.Submit(func() {
+		<-release
+	})
+
+	var order []string
+	for i := 0; i < 3; i++ {
+		pool.SubmitBatch(func() {
+			order = append(order, "B")
+		})
+	}
+	for i := 0; i < 3; i++ {
+		pool.Submit(func() {
+			order = append(order, "I")
+		})
+	}
+
+	close(release)
+	pool.StopAndWait()
+
+	return strings.Join(order, "")
+}
+
+func TestLanesWithStrictPriority(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.Lanes(0))
+
+	assertEqual(t, "IIIBBB", runLanes(pool))
+
+	assertEqual(t, pond.LaneStats{Submitted: 4, Completed: 4}, pool.LaneStats(pond.InteractiveLane))
+	assertEqual(t, pond.LaneStats{Submitted: 3, Completed: 3}, pool.LaneStats(pond.BatchLane))
+}
+
+func TestLanesWithRatio(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.Lanes(2))
+
+	assertEqual(t, "IIBIBB", runLanes(pool))
+}
+
+func TestWithoutLanes(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	assertEqual(t, "BBBIII", runLanes(pool))
+	assertEqual(t, pond.LaneStats{}, pool.LaneStats(pond.BatchLane))
+}
+
+func TestLaneString(t *testing.T) {
+	assertEqual(t, "interactive", pond.InteractiveLane.String())
+	assertEqual(t, "batch", pond.BatchLane.String())
+	assertEqual(t, "Lane(5)", pond.Lane(5).String())
+}
+
+func TestReserveWorkers(t *testing.T) {
+
+	pool := pond.New(3, 10, pond.Lanes(0), pond.ReserveWorkers(1))
+
+	release := make(chan struct{})
+	var running int32
+	for i := 0; i < 5; i++ {
+		pool.SubmitBatch(func() {
+			atomic.AddInt32(&running, 1)
+			<-release
+		})
+	}
+
+	// Only 2 batch tasks run, the last worker is reserved for interactive tasks
+	for atomic.LoadInt32(&running) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	interactiveDone := make(chan struct{})
+	pool.Submit(func() {
+		close(interactiveDone)
+	})
+	<-interactiveDone
+
+	assertEqual(t, int32(2), atomic.LoadInt32(&running))
+	assertEqual(t, uint64(3), pool.LaneStats(pond.BatchLane).Waiting)
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, int32(5), atomic.LoadInt32(&running))
+	assertEqual(t, uint64(5), pool.LaneStats(pond.BatchLane).Completed)
+}
+
+func TestReserveWorkersRequiresLanes(t *testing.T) {
+
+	_, err := pond.NewWithOptions(3, 10, pond.ReserveWorkers(1))
+
+	assertEqual(t, "invalid worker pool configuration: reserved workers require lanes", err.Error())
+}
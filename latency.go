@@ -0,0 +1,29 @@
+package pond
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// ReportLatency reports the latency and outcome (err is nil on success) of a call made by the running task the given
+// context was passed to (see SubmitContext) to a downstream dependency. Reported samples are consumed by the adaptive
+// concurrency controller (see AdaptiveLimit), which then ignores the duration of the task itself, and by the event
+// listener (see EventListener.OnLatencyReported), e.g. to feed a circuit breaker. This way, tasks that do more than
+// calling the dependency, or call it several times, measure exactly what the controller must react to.
+// It has no effect if ctx doesn't belong to a running task.
+func ReportLatency(ctx context.Context, latency time.Duration, err error) {
+	task := taskFromContext(ctx)
+	if task == nil || task.pool == nil {
+		return
+	}
+	p := task.pool
+
+	atomic.StoreInt32(&task.latencyReported, 1)
+	p.adaptive.observe(latency, err != nil, p.now())
+
+	if p.events.OnLatencyReported != nil {
+		info, _ := FromContext(ctx)
+		p.events.OnLatencyReported(info, latency, err)
+	}
+}
@@ -0,0 +1,57 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestReportLatency(t *testing.T) {
+
+	var reportedInfo pond.TaskInfo
+	var reportedLatency time.Duration
+	var reportedErr error
+	pool := pond.New(10, 100, pond.AdaptiveLimit(pond.AdaptiveConcurrency{
+		LatencyThreshold: time.Millisecond,
+		BackoffRatio:     0.5,
+	}), pond.Events(pond.EventListener{
+		OnLatencyReported: func(info pond.TaskInfo, latency time.Duration, err error) {
+			reportedInfo, reportedLatency, reportedErr = info, latency, err
+		},
+	}))
+
+	// The task returns immediately, but its downstream call was slow
+	pool.SubmitContext(func(ctx context.Context) {
+		pond.ReportLatency(ctx, 50*time.Millisecond, errors.New("timeout"))
+	})
+	pool.StopAndWait()
+
+	assertEqual(t, 5, pool.ConcurrencyLimit())
+	assertEqual(t, true, reportedInfo.ID > 0)
+	assertEqual(t, 50*time.Millisecond, reportedLatency)
+	assertEqual(t, "timeout", reportedErr.Error())
+}
+
+func TestReportLatencyReplacesTaskDuration(t *testing.T) {
+
+	pool := pond.New(10, 100, pond.AdaptiveLimit(pond.AdaptiveConcurrency{
+		LatencyThreshold: time.Millisecond,
+	}))
+
+	// The task is slow, but its downstream call was fast
+	pool.SubmitContext(func(ctx context.Context) {
+		time.Sleep(5 * time.Millisecond)
+		pond.ReportLatency(ctx, 100*time.Microsecond, nil)
+	})
+	pool.StopAndWait()
+
+	assertEqual(t, 10, pool.ConcurrencyLimit())
+}
+
+func TestReportLatencyOutsideOfTask(t *testing.T) {
+
+	pond.ReportLatency(context.Background(), time.Second, nil)
+}
@@ -0,0 +1,83 @@
+package pond
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ProcessLines reads the given reader line by line and calls fn for each line on the worker pool, so lines are
+// processed concurrently. Reading pauses whenever the pool can't accept more tasks, which bounds memory usage
+// regardless of the size of the input. Lines are passed to fn without their end-of-line marker, and fn may retain them.
+// The first error returned by fn stops the reading and cancels the context passed to the remaining calls.
+// ProcessLines waits for all dispatched lines to be processed and returns that error, prefixed by its line number
+// (starting at 1), the error encountered while reading (e.g. bufio.ErrTooLong for lines longer than
+// bufio.MaxScanTokenSize), or the context's error if ctx was canceled.
+func ProcessLines(ctx context.Context, pool *WorkerPool, r io.Reader, fn func(ctx context.Context, line []byte) error) error {
+
+	if ctx == nil {
+		panic("a non-nil context needs to be specified when using ProcessLines")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var waitGroup sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for ctx.Err() == nil && scanner.Scan() {
+		lineNumber++
+		n := lineNumber
+		line := append([]byte(nil), scanner.Bytes()...)
+
+		waitGroup.Add(1)
+		pool.submitOrReject(func() {
+			defer waitGroup.Done()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			completed := false
+			defer func() {
+				if !completed {
+					// Report the panic as the error of the line and let the pool handle it
+					p := recover()
+					fail(fmt.Errorf("line %d: %w", n, newPanicError(p)))
+					panic(p)
+				}
+			}()
+
+			if err := fn(ctx, line); err != nil {
+				fail(fmt.Errorf("line %d: %w", n, err))
+			}
+			completed = true
+		}, func(err error) {
+			fail(fmt.Errorf("line %d: %w", n, err))
+			waitGroup.Done()
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		fail(err)
+	}
+
+	waitGroup.Wait()
+
+	errOnce.Do(func() {
+		// No line failed, report the cancellation of the parent context (if any)
+		firstErr = ctx.Err()
+	})
+	return firstErr
+}
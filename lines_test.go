@@ -0,0 +1,63 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestProcessLines(t *testing.T) {
+
+	pool := pond.New(3, 10)
+	defer pool.StopAndWait()
+
+	input := strings.Repeat("12345\n", 1000)
+
+	var total int64
+	err := pond.ProcessLines(context.Background(), pool, strings.NewReader(input), func(ctx context.Context, line []byte) error {
+		atomic.AddInt64(&total, int64(len(line)))
+		return nil
+	})
+
+	assertEqual(t, nil, err)
+	assertEqual(t, int64(5000), atomic.LoadInt64(&total))
+}
+
+func TestProcessLinesWithError(t *testing.T) {
+
+	pool := pond.New(1, 0)
+	defer pool.StopAndWait()
+
+	sampleErr := errors.New("sample error")
+	var processed int32
+	err := pond.ProcessLines(context.Background(), pool, strings.NewReader("a\nb\nc\nd\ne\n"), func(ctx context.Context, line []byte) error {
+		atomic.AddInt32(&processed, 1)
+		if string(line) == "b" {
+			return sampleErr
+		}
+		return nil
+	})
+
+	assertEqual(t, true, errors.Is(err, sampleErr))
+	assertEqual(t, "line 2: sample error", err.Error())
+	assertEqual(t, true, atomic.LoadInt32(&processed) < 5)
+}
+
+func TestProcessLinesWithCancelledContext(t *testing.T) {
+
+	pool := pond.New(1, 10)
+	defer pool.StopAndWait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := pond.ProcessLines(ctx, pool, strings.NewReader("a\nb\n"), func(ctx context.Context, line []byte) error {
+		return nil
+	})
+
+	assertEqual(t, context.Canceled, err)
+}
@@ -0,0 +1,106 @@
+package pond
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SubmitLinked sends a context-aware task to this worker pool for execution, linking its context to ctx
+// (typically the context of the HTTP request that submitted it). The context passed to the task is canceled
+// as soon as either ctx is done or the pool shuts down: it's aborted (see Abort) or stopped without waiting
+// for running tasks (see Stop, StopDiscarding and StopAndWaitFor), or its parent context is canceled (see Context).
+// Its error and deadline are those of the cancellation source, and it carries the values of ctx, as well as
+// the task's metadata (see FromContext). Unlike SubmitWithValues, the task still runs if ctx is done while it's
+// queued, so it should check the context's error before starting expensive work.
+func (p *WorkerPool) SubmitLinked(ctx context.Context, task func(ctx context.Context)) {
+	if ctx == nil {
+		panic("a non-nil context needs to be specified when using SubmitLinked")
+	}
+
+	queued := newQueuedTask(nil)
+	if task != nil {
+		queued.run = func() {
+			linked := p.link(ctx)
+			defer linked.cancel(context.Canceled)
+
+			task(withTaskInfo(linked, queued))
+		}
+	}
+
+	p.submit(queued, true)
+}
+
+// linkedContext is the context of a task submitted with SubmitLinked. It's canceled when either the submitter's
+// context is done or the pool shuts down, and when the task returns.
+type linkedContext struct {
+	submitter context.Context
+	pool      *WorkerPool
+	done      chan struct{}
+	err       error
+	mutex     sync.Mutex
+}
+
+// link creates a context linked to the given submitter context and to the shutdown of this pool.
+// It must be canceled once the task returns, to release the goroutine watching the cancellation sources.
+func (p *WorkerPool) link(submitter context.Context) *linkedContext {
+	c := &linkedContext{
+		submitter: submitter,
+		pool:      p,
+		done:      make(chan struct{}),
+	}
+
+	go func() {
+		select {
+		case <-submitter.Done():
+			c.cancel(submitter.Err())
+		case <-p.taskContext.Done():
+			c.cancel(p.taskContext.Err())
+		case <-p.context.Done():
+			c.cancel(p.context.Err())
+		case <-c.done:
+		}
+	}()
+
+	return c
+}
+
+// cancel closes the done channel and records the given error, unless the context was already canceled
+func (c *linkedContext) cancel(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+}
+
+// Deadline returns the earliest of the deadlines of the submitter's context and of the pool's base context
+func (c *linkedContext) Deadline() (time.Time, bool) {
+	deadline, ok := c.submitter.Deadline()
+	if base, baseOk := c.pool.taskContext.Deadline(); baseOk && (!ok || base.Before(deadline)) {
+		deadline, ok = base, true
+	}
+	return deadline, ok
+}
+
+func (c *linkedContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *linkedContext) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.err
+}
+
+// Value returns the value associated with key in the submitter's context, or else in the pool's base context
+func (c *linkedContext) Value(key interface{}) interface{} {
+	if value := c.submitter.Value(key); value != nil {
+		return value
+	}
+	return c.pool.taskContext.Value(key)
+}
@@ -0,0 +1,110 @@
+package pond_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestSubmitLinkedCanceledBySubmitter(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey("requestID"), "abc"))
+
+	started := make(chan struct{})
+	var requestID interface{}
+	var taskErr error
+	var hasInfo bool
+	pool.SubmitLinked(ctx, func(ctx context.Context) {
+		close(started)
+		requestID = ctx.Value(contextKey("requestID"))
+		_, hasInfo = pond.FromContext(ctx)
+
+		<-ctx.Done()
+		taskErr = ctx.Err()
+	})
+
+	<-started
+	cancel()
+	pool.StopAndWait()
+
+	assertEqual(t, "abc", requestID)
+	assertEqual(t, true, hasInfo)
+	assertEqual(t, context.Canceled, taskErr)
+}
+
+func TestSubmitLinkedDeadline(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	expected, _ := ctx.Deadline()
+
+	var deadline time.Time
+	var taskErr error
+	pool.SubmitLinked(ctx, func(ctx context.Context) {
+		deadline, _ = ctx.Deadline()
+
+		<-ctx.Done()
+		taskErr = ctx.Err()
+	})
+
+	pool.StopAndWait()
+
+	assertEqual(t, expected, deadline)
+	assertEqual(t, context.DeadlineExceeded, taskErr)
+}
+
+func TestSubmitLinkedCanceledOnShutdown(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	started := make(chan struct{})
+	var taskErr error
+	pool.SubmitLinked(context.Background(), func(ctx context.Context) {
+		close(started)
+
+		<-ctx.Done()
+		taskErr = ctx.Err()
+	})
+
+	<-started
+	pool.StopDiscarding()
+
+	assertEqual(t, context.Canceled, taskErr)
+}
+
+func TestSubmitLinkedCanceledOnReturn(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	var taskCtx context.Context
+	pool.SubmitLinked(context.Background(), func(ctx context.Context) {
+		taskCtx = ctx
+	})
+
+	pool.StopAndWait()
+
+	<-taskCtx.Done()
+	assertEqual(t, context.Canceled, taskCtx.Err())
+}
+
+func TestSubmitLinkedWithNilContext(t *testing.T) {
+
+	pool := pond.New(1, 1)
+	defer pool.StopAndWait()
+
+	var thrownPanic interface{}
+	func() {
+		defer func() {
+			thrownPanic = recover()
+		}()
+		pool.SubmitLinked(nil, func(ctx context.Context) {})
+	}()
+
+	assertEqual(t, "a non-nil context needs to be specified when using SubmitLinked", thrownPanic)
+}
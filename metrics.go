@@ -0,0 +1,112 @@
+package pond
+
+import (
+	"time"
+)
+
+const (
+	// defaultMetricsInterval defines the default interval at which metrics are flushed when not explicitly specified
+	// via the Metrics() option
+	defaultMetricsInterval = 10 * time.Second
+)
+
+// MetricsSink receives the telemetry of a worker pool, e.g. to export it to a monitoring system.
+// Implementations must be safe for concurrent use, since task durations are reported by the workers
+// as tasks complete.
+type MetricsSink interface {
+	// Count adds the given delta to a counter
+	Count(name string, delta int64, tags []string)
+	// Gauge sets the current value of a gauge
+	Gauge(name string, value float64, tags []string)
+	// Timing records a duration
+	Timing(name string, duration time.Duration, tags []string)
+	// Flush sends all buffered metrics. It's invoked on every flush interval and when the pool stops.
+	Flush() error
+}
+
+// Metrics allows to export the telemetry of a worker pool to the given sink.
+// Task counters and worker/queue gauges are emitted every interval (10 seconds if interval is not greater than zero),
+// right before the sink is flushed, while the duration of each task is emitted as soon as it completes.
+// All metrics are tagged with "pool:<name>" if the pool has a name and with "<key>:<value>" for each of its tags
+// (see Tags), and task durations with "label:<label>" if the task has a label.
+func Metrics(sink MetricsSink, interval time.Duration) Option {
+	return func(pool *WorkerPool) {
+		pool.metrics = &metricsReporter{
+			sink:     sink,
+			interval: interval,
+		}
+	}
+}
+
+// metricsReporter emits the telemetry of a worker pool to a sink
+type metricsReporter struct {
+	sink     MetricsSink
+	interval time.Duration
+	tags     []string
+	// last holds the counters emitted on the previous flush, to emit the deltas since then
+	last Stats
+}
+
+func (m *metricsReporter) normalize(name string, tags map[string]string) {
+	if m.interval <= 0 {
+		m.interval = defaultMetricsInterval
+	}
+	m.tags = nil
+	if name != "" {
+		m.tags = []string{"pool:" + name}
+	}
+	m.tags = append(m.tags, sortedTags(tags, ":")...)
+	if len(m.tags) == 0 {
+		m.tags = nil
+	}
+}
+
+// observe emits the duration of a task (if metrics are enabled)
+func (m *metricsReporter) observe(label string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	tags := m.tags
+	if label != "" {
+		tags = append(tags[:len(tags):len(tags)], "label:"+label)
+	}
+	m.sink.Timing("tasks.duration", duration, tags)
+}
+
+// report emits the counters and gauges in the given stats and flushes the sink
+func (m *metricsReporter) report(stats Stats) {
+	m.sink.Count("tasks.submitted", int64(stats.SubmittedTasks-m.last.SubmittedTasks), m.tags)
+	m.sink.Count("tasks.successful", int64(stats.SuccessfulTasks-m.last.SuccessfulTasks), m.tags)
+	m.sink.Count("tasks.failed", int64(stats.FailedTasks-m.last.FailedTasks), m.tags)
+	m.sink.Count("tasks.expired", int64(stats.ExpiredTasks-m.last.ExpiredTasks), m.tags)
+	m.sink.Gauge("workers.running", float64(stats.RunningWorkers), m.tags)
+	m.sink.Gauge("workers.idle", float64(stats.IdleWorkers), m.tags)
+	m.sink.Gauge("tasks.waiting", float64(stats.WaitingTasks), m.tags)
+	m.sink.Gauge("queue.length", float64(stats.QueueLen), m.tags)
+	m.sink.Gauge("queue.oldest_age", stats.OldestWaiting.Seconds(), m.tags)
+	m.sink.Gauge("saturation", stats.Saturation, m.tags)
+	m.last = stats
+
+	// Errors are ignored, as metrics are sent on a best-effort basis
+	m.sink.Flush()
+}
+
+// reportMetrics represents the work done by the metrics goroutine
+func (p *WorkerPool) reportMetrics() {
+	defer p.workersWaitGroup.Done()
+
+	ticker := time.NewTicker(p.metrics.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.metrics.report(p.StatsSnapshot())
+		case <-p.context.Done():
+			// Emit the final values before exiting
+			p.metrics.report(p.StatsSnapshot())
+			return
+		}
+	}
+}
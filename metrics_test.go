@@ -0,0 +1,113 @@
+package pond_test
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+type recordingSink struct {
+	mutex   sync.Mutex
+	metrics []string
+	flushes int
+}
+
+func (s *recordingSink) record(format string, args ...interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.metrics = append(s.metrics, fmt.Sprintf(format, args...))
+}
+
+func (s *recordingSink) Count(name string, delta int64, tags []string) {
+	s.record("%s:%d|c|%s", name, delta, strings.Join(tags, ","))
+}
+
+func (s *recordingSink) Gauge(name string, value float64, tags []string) {
+	s.record("%s:%v|g|%s", name, value, strings.Join(tags, ","))
+}
+
+func (s *recordingSink) Timing(name string, duration time.Duration, tags []string) {
+	s.record("%s|ms|%s", name, strings.Join(tags, ","))
+}
+
+func (s *recordingSink) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.flushes++
+	return nil
+}
+
+func (s *recordingSink) contains(metric string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, m := range s.metrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMetrics(t *testing.T) {
+
+	sink := &recordingSink{}
+	pool := pond.New(1, 10, pond.Name("mailer"), pond.Metrics(sink, 1*time.Hour))
+
+	pool.SubmitLabeled("send", func() {})
+	pool.Submit(func() {})
+
+	pool.StopAndWait()
+
+	assertEqual(t, 1, sink.flushes)
+	assertEqual(t, true, sink.contains("tasks.duration|ms|pool:mailer,label:send"))
+	assertEqual(t, true, sink.contains("tasks.duration|ms|pool:mailer"))
+	assertEqual(t, true, sink.contains("tasks.submitted:2|c|pool:mailer"))
+	assertEqual(t, true, sink.contains("tasks.successful:2|c|pool:mailer"))
+	assertEqual(t, true, sink.contains("tasks.failed:0|c|pool:mailer"))
+	assertEqual(t, true, sink.contains("tasks.waiting:0|g|pool:mailer"))
+	assertEqual(t, true, sink.contains("queue.oldest_age:0|g|pool:mailer"))
+	assertEqual(t, true, sink.contains("saturation:0|g|pool:mailer"))
+}
+
+func TestMetricsEmitsDeltas(t *testing.T) {
+
+	sink := &recordingSink{}
+	pool := pond.New(1, 10, pond.Metrics(sink, 10*time.Millisecond))
+
+	pool.SubmitAndWait(func() {})
+	time.Sleep(50 * time.Millisecond)
+	pool.SubmitAndWait(func() {})
+
+	pool.StopAndWait()
+
+	assertEqual(t, true, sink.flushes > 1)
+	assertEqual(t, true, sink.contains("tasks.submitted:1|c|"))
+	assertEqual(t, false, sink.contains("tasks.submitted:2|c|"))
+}
+
+func TestStatsDSink(t *testing.T) {
+
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assertEqual(t, nil, err)
+	defer server.Close()
+
+	sink, err := pond.NewStatsDSink(server.LocalAddr().String(), "app.")
+	assertEqual(t, nil, err)
+
+	sink.Count("tasks.submitted", 3, []string{"pool:mailer"})
+	sink.Gauge("workers.running", 2, nil)
+	sink.Timing("tasks.duration", 1500*time.Microsecond, nil)
+	assertEqual(t, nil, sink.Close())
+
+	buffer := make([]byte, 2048)
+	server.SetReadDeadline(time.Now().Add(1 * time.Second))
+	n, _, err := server.ReadFrom(buffer)
+	assertEqual(t, nil, err)
+
+	assertEqual(t, "app.tasks.submitted:3|c|#pool:mailer\napp.workers.running:2|g\napp.tasks.duration:1.5|ms", string(buffer[:n]))
+}
@@ -0,0 +1,58 @@
+package pond
+
+import (
+	"context"
+	"runtime"
+)
+
+// PinWorkers locks each worker of a pool to an OS thread bound to the given CPUs, to improve cache locality for
+// CPU-bound workloads (e.g. numeric kernels working on shared data). It's a hint: binding threads to CPUs is only
+// supported on Linux, and elsewhere workers are only locked to their thread. Since each worker then needs its own
+// thread, this is only worth it with few, long-lived workers (see MinWorkers and KeepWarm).
+func PinWorkers(cpus ...int) Option {
+	return func(pool *WorkerPool) {
+		pool.cpus = append([]int(nil), cpus...)
+	}
+}
+
+// PinShards makes each shard of a sharded pool (see NewSharded) pin its workers to its own subset of the CPUs
+// available to the process (see PinWorkers), splitting them in contiguous blocks of equal size, or assigning them
+// in turn if there are more shards than CPUs. It has no effect on pools that are not shards.
+func PinShards() Option {
+	return func(pool *WorkerPool) {
+		pool.pinShards = true
+	}
+}
+
+// shardCPUs returns the CPUs assigned to the shard with the given index when the given number of CPUs is split
+// across the given number of shards
+func shardCPUs(index, shards, cpus int) []int {
+	if shards > cpus {
+		return []int{index % cpus}
+	}
+
+	first, last := index*cpus/shards, (index+1)*cpus/shards
+	assigned := make([]int, 0, last-first)
+	for cpu := first; cpu < last; cpu++ {
+		assigned = append(assigned, cpu)
+	}
+	return assigned
+}
+
+// assignShardCPUs pins the workers of this pool to its share of the CPUs, if it's a shard configured with PinShards
+func (p *WorkerPool) assignShardCPUs() {
+	if p.pinShards && p.shards > 0 && len(p.cpus) == 0 {
+		p.cpus = shardCPUs(p.shardIndex, p.shards, runtime.NumCPU())
+	}
+}
+
+// pinnedWorker runs a worker locked to its OS thread, which is bound to the CPUs of this pool. The thread is never
+// unlocked, so the runtime discards it along with its CPU affinity when the worker exits.
+func (p *WorkerPool) pinnedWorker(ctx context.Context, firstTask *queuedTask) {
+	runtime.LockOSThread()
+
+	// Pinning is a best-effort optimization, so the worker runs anyway if the affinity can't be set
+	_ = setThreadAffinity(p.cpus)
+
+	worker(ctx, &p.workersWaitGroup, firstTask, p.tasks, p.executeWorkerTask)
+}
@@ -0,0 +1,42 @@
+package pond
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShardCPUs(t *testing.T) {
+
+	cases := []struct {
+		index, shards, cpus int
+		expected            string
+	}{
+		{0, 4, 8, "[0 1]"},
+		{3, 4, 8, "[6 7]"},
+		{0, 3, 8, "[0 1]"},
+		{1, 3, 8, "[2 3 4]"},
+		{2, 3, 8, "[5 6 7]"},
+		{0, 1, 4, "[0 1 2 3]"},
+		{5, 8, 4, "[1]"},
+	}
+
+	for _, c := range cases {
+		assertEqual(t, c.expected, fmt.Sprint(shardCPUs(c.index, c.shards, c.cpus)))
+	}
+}
+
+func TestPinWorkers(t *testing.T) {
+
+	_, err := NewWithOptions(1, 1, PinWorkers(-1))
+	assertEqual(t, "invalid worker pool configuration: CPUs to pin workers to must not be negative, got -1", err.Error())
+
+	pool := New(1, 1, PinWorkers(-1, 0))
+	assertEqual(t, "[0]", fmt.Sprint(pool.Options().PinnedCPUs))
+
+	done := make(chan struct{})
+	pool.Submit(func() {
+		close(done)
+	})
+	<-done
+	pool.StopAndWait()
+}
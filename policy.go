@@ -0,0 +1,127 @@
+package pond
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRejectedByPolicy is wrapped by the errors returned when an admission policy rejects a task (see Admission)
+	ErrRejectedByPolicy = errors.New("task rejected by admission policy")
+)
+
+// AdmissionPolicy decides whether a task can be submitted to a pool, returning an error to reject it.
+// Policies are declared by composing the ones provided (see AllOf and AnyOf) and enforced with Admission.
+type AdmissionPolicy func(pool *WorkerPool, info TaskInfo) error
+
+// Admission makes a pool enforce the given admission policy on every submission, with a submit hook (see SubmitHooks),
+// so tasks it rejects are vetoed
+func Admission(policy AdmissionPolicy) Option {
+	return func(pool *WorkerPool) {
+		SubmitHooks(func(info *TaskInfo) error {
+			return policy(pool, *info)
+		})(pool)
+	}
+}
+
+// AllOf returns a policy that admits a task if all the given policies admit it, evaluating them in order
+func AllOf(policies ...AdmissionPolicy) AdmissionPolicy {
+	return func(pool *WorkerPool, info TaskInfo) error {
+		for _, policy := range policies {
+			if err := policy(pool, info); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// AnyOf returns a policy that admits a task if any of the given policies admits it, evaluating them in order.
+// If none does, the error of the first one is returned.
+func AnyOf(policies ...AdmissionPolicy) AdmissionPolicy {
+	return func(pool *WorkerPool, info TaskInfo) error {
+		var first error
+		for _, policy := range policies {
+			err := policy(pool, info)
+			if err == nil {
+				return nil
+			}
+			if first == nil {
+				first = err
+			}
+		}
+		return first
+	}
+}
+
+// RateUnder returns a policy that admits at most perSecond tasks per second, allowing bursts of up to perSecond tasks
+// (at least one). Tasks rejected by another policy combined with AllOf before it don't count towards the rate.
+func RateUnder(perSecond float64) AdmissionPolicy {
+	bucket := &tokenBucket{
+		rate:     perSecond,
+		capacity: math.Max(perSecond, 1),
+	}
+	bucket.tokens = bucket.capacity
+
+	return func(pool *WorkerPool, info TaskInfo) error {
+		if !bucket.take(pool.now()) {
+			return fmt.Errorf("%w: more than %v tasks per second", ErrRejectedByPolicy, perSecond)
+		}
+		return nil
+	}
+}
+
+// QueueBelow returns a policy that admits tasks while less than the given number of tasks are waiting in the pool
+func QueueBelow(waiting int) AdmissionPolicy {
+	return func(pool *WorkerPool, info TaskInfo) error {
+		if pool.WaitingTasks() >= uint64(waiting) {
+			return fmt.Errorf("%w: %d tasks or more are waiting", ErrRejectedByPolicy, waiting)
+		}
+		return nil
+	}
+}
+
+// TenantBelow returns a policy that admits the tasks of a tenant (see SubmitTenant) while it has less than the given
+// number of tasks running or waiting. Unlike the tenant's quota (see TenantQuota), which only bounds its queue depth,
+// it can be combined with other policies, e.g. to always admit the tasks of small tenants while the queue is short.
+// Tasks that don't belong to a tenant are admitted.
+func TenantBelow(outstanding int) AdmissionPolicy {
+	return func(pool *WorkerPool, info TaskInfo) error {
+		if info.Submitter == "" {
+			return nil
+		}
+		if stats := pool.TenantStats(info.Submitter); stats.Running+stats.Waiting >= outstanding {
+			return fmt.Errorf("%w: tenant %q has %d tasks or more outstanding", ErrRejectedByPolicy, info.Submitter, outstanding)
+		}
+		return nil
+	}
+}
+
+// tokenBucket is a token bucket refilled continuously at a given rate, up to its capacity
+type tokenBucket struct {
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+	mutex    sync.Mutex
+}
+
+// take takes a token from the bucket, returning false if it's empty
+func (b *tokenBucket) take(now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,100 @@
+package pond_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestAdmissionQueueBelow(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MinWorkers(1), pond.Admission(pond.QueueBelow(2)))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	// Two tasks can wait, the third one is rejected
+	assertEqual(t, nil, pool.SubmitFrom("a", func() {}))
+	assertEqual(t, nil, pool.SubmitFrom("a", func() {}))
+	err := pool.SubmitFrom("a", func() {})
+	assertEqual(t, true, errors.Is(err, pond.ErrRejectedByPolicy))
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, uint64(3), pool.CompletedTasks())
+}
+
+func TestAdmissionRateUnder(t *testing.T) {
+
+	clock := pond.NewManualClock(time.Unix(0, 0))
+	pool := pond.New(1, 10, pond.Deterministic(1, clock), pond.Admission(pond.RateUnder(2)))
+	defer pool.StopAndWait()
+
+	// The burst is exhausted after 2 tasks
+	assertEqual(t, nil, pool.SubmitFrom("a", func() {}))
+	assertEqual(t, nil, pool.SubmitFrom("a", func() {}))
+	assertEqual(t, true, errors.Is(pool.SubmitFrom("a", func() {}), pond.ErrRejectedByPolicy))
+
+	// A token is added every 500ms
+	clock.Advance(500 * time.Millisecond)
+	assertEqual(t, nil, pool.SubmitFrom("a", func() {}))
+	assertEqual(t, true, errors.Is(pool.SubmitFrom("a", func() {}), pond.ErrRejectedByPolicy))
+}
+
+func TestAdmissionComposition(t *testing.T) {
+
+	errMaintenance := errors.New("maintenance")
+	maintenance := func(pool *pond.WorkerPool, info pond.TaskInfo) error {
+		if info.Submitter == "maintenance" {
+			return nil
+		}
+		return errMaintenance
+	}
+	never := func(pool *pond.WorkerPool, info pond.TaskInfo) error {
+		return pond.ErrRejectedByPolicy
+	}
+
+	pool := pond.New(1, 10, pond.Admission(pond.AllOf(
+		pond.QueueBelow(100),
+		pond.AnyOf(maintenance, never),
+	)))
+	defer pool.StopAndWait()
+
+	assertEqual(t, nil, pool.SubmitFrom("maintenance", func() {}))
+	assertEqual(t, true, errors.Is(pool.SubmitFrom("b", func() {}), errMaintenance))
+}
+
+func TestAdmissionTenantBelow(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.Admission(pond.TenantBelow(2)))
+	pool.SetTenantQuota("noisy", pond.TenantQuota{})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	assertEqual(t, nil, pool.SubmitTenant("noisy", func() {
+		close(started)
+		<-release
+	}))
+	<-started
+
+	// One task running and one waiting
+	assertEqual(t, nil, pool.SubmitTenant("noisy", func() {}))
+	assertEqual(t, true, errors.Is(pool.SubmitTenant("noisy", func() {}), pond.ErrRejectedByPolicy))
+
+	// Other tenants and tasks without tenant are not affected
+	assertEqual(t, nil, pool.SubmitTenant("quiet", func() {}))
+	pool.Submit(func() {})
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, uint64(2), pool.TenantStats("noisy").Successful)
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"runtime/debug"
+	"runtime/pprof"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -14,16 +17,33 @@ const (
 	// defaultIdleTimeout defines the default idle timeout to use when not explicitly specified
 	// via the IdleTimeout() option
 	defaultIdleTimeout = 5 * time.Second
+
+	// Labels attached to worker goroutines for profiling purposes
+	poolProfilerLabel   = "pond.pool"
+	workerProfilerLabel = "pond.worker"
+	taskProfilerLabel   = "pond.task"
 )
 
 var (
 	// ErrSubmitOnStoppedPool is thrown when attempting to submit a task to a pool that has been stopped
 	ErrSubmitOnStoppedPool = errors.New("worker pool has been stopped and is no longer accepting tasks")
+
+	// ErrInvalidConfig is wrapped by the errors returned by NewWithOptions when the configuration is invalid
+	ErrInvalidConfig = errors.New("invalid worker pool configuration")
 )
 
 // defaultPanicHandler is the default panic handler
-func defaultPanicHandler(panic interface{}) {
-	fmt.Printf("Worker exits from a panic: %v\nStack trace: %s\n", panic, string(debug.Stack()))
+func defaultPanicHandler(panic interface{}, info TaskInfo) {
+	var submittedFrom string
+	if info.SubmittedFrom != "" {
+		submittedFrom = fmt.Sprintf("Submitted from: %s\n", info.SubmittedFrom)
+	}
+	pool := describePool(info.Pool, info.Tags)
+	if info.Label != "" {
+		fmt.Printf("Worker exits from a panic in task %q%s: %v\nStack trace: %s\n%s", info.Label, pool, panic, string(debug.Stack()), submittedFrom)
+		return
+	}
+	fmt.Printf("Worker exits from a panic%s: %v\nStack trace: %s\n%s", pool, panic, string(debug.Stack()), submittedFrom)
 }
 
 // ResizingStrategy represents a pool resizing strategy
@@ -34,6 +54,20 @@ type ResizingStrategy interface {
 // Option represents an option that can be passed when instantiating a worker pool to customize it
 type Option func(*WorkerPool)
 
+// MaxWorkers allows to change the maximum number of workers of a worker pool, overriding the value passed to New
+func MaxWorkers(maxWorkers int) Option {
+	return func(pool *WorkerPool) {
+		pool.maxWorkers = maxWorkers
+	}
+}
+
+// MaxCapacity allows to change the size of the queue of a worker pool, overriding the value passed to New
+func MaxCapacity(maxCapacity int) Option {
+	return func(pool *WorkerPool) {
+		pool.maxCapacity = maxCapacity
+	}
+}
+
 // IdleTimeout allows to change the idle timeout for a worker pool
 func IdleTimeout(idleTimeout time.Duration) Option {
 	return func(pool *WorkerPool) {
@@ -41,6 +75,22 @@ func IdleTimeout(idleTimeout time.Duration) Option {
 	}
 }
 
+// IdleJitter randomizes the interval at which idle workers are retired by up to the given fraction (between 0 and 1)
+// of the idle timeout, so that capacity decays gradually rather than at fixed intervals
+func IdleJitter(jitter float64) Option {
+	return func(pool *WorkerPool) {
+		pool.idleJitter = jitter
+	}
+}
+
+// KeepWarm allows to change the number of workers that are never retired once started. Unlike MinWorkers,
+// these workers are not started upfront, so the pool only keeps warm the capacity it actually needed.
+func KeepWarm(workers int) Option {
+	return func(pool *WorkerPool) {
+		pool.keepWarm = workers
+	}
+}
+
 // MinWorkers allows to change the minimum number of workers of a worker pool
 func MinWorkers(minWorkers int) Option {
 	return func(pool *WorkerPool) {
@@ -55,13 +105,59 @@ func Strategy(strategy ResizingStrategy) Option {
 	}
 }
 
+// MaxQueueAge allows to change the maximum amount of time a task can wait in the queue. Tasks that waited longer
+// are discarded instead of executed, and reported to the expired task handler. A value of 0 (the default) means no limit.
+func MaxQueueAge(maxQueueAge time.Duration) Option {
+	return func(pool *WorkerPool) {
+		pool.maxQueueAge = maxQueueAge
+	}
+}
+
+// ExpiredTaskHandler allows to change the function invoked when a task is discarded because its deadline
+// passed or it exceeded the maximum queue age while it was waiting in the queue
+func ExpiredTaskHandler(expiredTaskHandler func(TaskInfo)) Option {
+	return func(pool *WorkerPool) {
+		pool.expiredTaskHandler = expiredTaskHandler
+	}
+}
+
 // PanicHandler allows to change the panic handler function of a worker pool
 func PanicHandler(panicHandler func(interface{})) Option {
+	return func(pool *WorkerPool) {
+		if panicHandler == nil {
+			pool.panicHandler = nil
+			return
+		}
+		pool.panicHandler = func(panic interface{}, _ TaskInfo) {
+			panicHandler(panic)
+		}
+	}
+}
+
+// TaskPanicHandler allows to change the panic handler function of a worker pool to one that also receives
+// the metadata (e.g. the label) of the task that panicked
+func TaskPanicHandler(panicHandler func(interface{}, TaskInfo)) Option {
 	return func(pool *WorkerPool) {
 		pool.panicHandler = panicHandler
 	}
 }
 
+// Name allows to change the name of a worker pool, which identifies its workers in goroutine profiles
+func Name(name string) Option {
+	return func(pool *WorkerPool) {
+		pool.name = name
+	}
+}
+
+// BaseContext configures the context from which the contexts passed to context-aware tasks (see SubmitContext and
+// SubmitWithValues) are derived, so that canceling it asks all in-flight tasks to return, e.g. during shutdown.
+// Unlike Context, canceling it doesn't stop the pool.
+func BaseContext(ctx context.Context) Option {
+	return func(pool *WorkerPool) {
+		pool.baseContext = ctx
+	}
+}
+
 // Context configures a parent context on a worker pool to stop all workers when it is cancelled
 func Context(parentCtx context.Context) Option {
 	return func(pool *WorkerPool) {
@@ -71,37 +167,122 @@ func Context(parentCtx context.Context) Option {
 
 // WorkerPool models a pool of workers
 type WorkerPool struct {
+	// Hot atomic counters, updated by every task. They are padded to avoid false sharing, and placed first
+	// so they are 64-bit aligned on 32-bit platforms.
+	workerCount         paddedInt32
+	idleWorkerCount     paddedInt32
+	waitingTaskCount    paddedUint64
+	submittedTaskCount  paddedUint64
+	successfulTaskCount paddedUint64
+	failedTaskCount     paddedUint64
+	// Other atomic counter, placed right after the padded ones so it is 64-bit aligned as well
+	expiredTaskCount uint64
+	// State of the invariant checks, which only exists in builds with the pond_debug tag
+	invariants invariants
 	// Configurable settings
-	maxWorkers    int
-	maxCapacity   int
-	minWorkers    int
-	idleTimeout   time.Duration
-	strategy      ResizingStrategy
-	panicHandler  func(interface{})
-	context       context.Context
-	contextCancel context.CancelFunc
+	name               string
+	tags               map[string]string
+	submitHooks        []SubmitHook
+	maxWorkers         int
+	maxCapacity        int
+	minWorkers         int
+	idleTimeout        time.Duration
+	idleJitter         float64
+	callSiteDepth      int
+	keepWarm           int
+	reservedWorkers    int
+	cpus               []int
+	pinShards          bool
+	shardIndex         int
+	shards             int
+	strategy           ResizingStrategy
+	panicHandler       func(interface{}, TaskInfo)
+	queueOrder         Order
+	labelWeights       map[string]int
+	maxQueueAge        time.Duration
+	expiredTaskHandler func(TaskInfo)
+	droppedTaskHandler func(DropReason, TaskInfo)
+	reportInterval     time.Duration
+	reporter           func(Stats)
+	events             EventListener
+	measureAllocations bool
+	context            context.Context
+	contextCancel      context.CancelFunc
+	baseContext        context.Context
+	taskContext        context.Context
+	taskContextCancel  context.CancelFunc
 	// Atomic counters
-	workerCount         int32
-	idleWorkerCount     int32
-	waitingTaskCount    uint64
-	submittedTaskCount  uint64
-	successfulTaskCount uint64
-	failedTaskCount     uint64
+	burstWorkers int32
 	// Private properties
-	tasks            chan func()
-	tasksCloseOnce   sync.Once
+	tasks            *taskQueue
 	workersWaitGroup sync.WaitGroup
+	workerIDs        workerIDs
 	tasksWaitGroup   sync.WaitGroup
 	mutex            sync.Mutex
 	stopped          int32
+	semaphore        *semaphore
+	pressure         *pressureMonitor
+	labels           *labelMetrics
+	metrics          *metricsReporter
+	queueWait        *waitHistogram
+	health           *healthMonitor
+	submitters       *submitterLimiter
+	scaling          *scalingLimiter
+	burstTimer       *time.Timer
+	forget           *forgetLimiter
+	adaptive         *adaptiveLimiter
+	utilization      *utilizationScaler
+	starvation       *starvationMonitor
+	deterministic    *deterministicMode
+	trace            *traceRecorder
+	lanes            *laneMetrics
+	checkpoints      sync.Map
+	budget           *ConcurrencyBudget
+	tenants          *tenantScheduler
+	taskKeys         taskKeys
 }
 
 // New creates a worker pool with that can scale up to the given maximum number of workers (maxWorkers).
 // The maxCapacity parameter determines the number of tasks that can be submitted to this pool without blocking,
-// because it defines the size of the buffered channel used to receive tasks.
+// because it defines the size of the queue used to hold tasks until a worker picks them up.
 // The options parameter can take a list of functions to customize configuration values on this worker pool.
+// Invalid configuration values are replaced by sensible defaults, use NewWithOptions to have them reported instead.
 func New(maxWorkers, maxCapacity int, options ...Option) *WorkerPool {
 
+	pool := newWorkerPool(maxWorkers, maxCapacity, options)
+
+	// Make sure options are consistent
+	pool.normalize()
+
+	pool.start()
+
+	return pool
+}
+
+// NewWithOptions creates a worker pool just like New, but returns an error wrapping ErrInvalidConfig
+// instead of silently adjusting invalid or conflicting configuration values.
+func NewWithOptions(maxWorkers, maxCapacity int, options ...Option) (*WorkerPool, error) {
+
+	pool := newWorkerPool(maxWorkers, maxCapacity, options)
+
+	if err := pool.validate(); err != nil {
+		if pool.contextCancel != nil {
+			pool.contextCancel()
+		}
+		return nil, err
+	}
+
+	// Fill in the defaults that depend on other options
+	pool.normalize()
+
+	pool.start()
+
+	return pool, nil
+}
+
+// newWorkerPool instantiates a pool and applies the given options to it
+func newWorkerPool(maxWorkers, maxCapacity int, options []Option) *WorkerPool {
+
 	// Instantiate the pool
 	pool := &WorkerPool{
 		maxWorkers:   maxWorkers,
@@ -109,57 +290,331 @@ func New(maxWorkers, maxCapacity int, options ...Option) *WorkerPool {
 		idleTimeout:  defaultIdleTimeout,
 		strategy:     Eager(),
 		panicHandler: defaultPanicHandler,
+		baseContext:  context.Background(),
 	}
+	pool.tenants = newTenantScheduler(pool)
+	pool.labels = newLabelMetrics()
+	pool.queueWait = newWaitHistogram()
 
 	// Apply all options
 	for _, opt := range options {
 		opt(pool)
 	}
 
-	// Make sure options are consistent
-	if pool.maxWorkers <= 0 {
-		pool.maxWorkers = 1
+	return pool
+}
+
+// normalize replaces invalid configuration values by sensible defaults
+func (p *WorkerPool) normalize() {
+	if p.deterministic != nil {
+		p.deterministic.normalize(p)
+	}
+	if p.maxWorkers <= 0 {
+		p.maxWorkers = 1
+	}
+	if p.minWorkers > p.maxWorkers {
+		p.minWorkers = p.maxWorkers
+	}
+	if p.maxCapacity < 0 {
+		p.maxCapacity = 0
+	}
+	if p.idleTimeout <= 0 {
+		p.idleTimeout = defaultIdleTimeout
+	}
+	if p.baseContext == nil {
+		p.baseContext = context.Background()
+	}
+	if p.maxQueueAge < 0 {
+		p.maxQueueAge = 0
+	}
+	if p.idleJitter < 0 {
+		p.idleJitter = 0
+	} else if p.idleJitter > 1 {
+		p.idleJitter = 1
+	}
+	if p.keepWarm < 0 {
+		p.keepWarm = 0
+	}
+	if p.callSiteDepth < 0 {
+		p.callSiteDepth = 0
+	} else if p.callSiteDepth > maxCallSiteDepth {
+		p.callSiteDepth = maxCallSiteDepth
+	}
+	if p.pressure != nil {
+		p.pressure.normalize()
+	}
+	if p.labels.maxLabels < 1 {
+		p.labels.maxLabels = defaultMaxLabels
+	}
+	if p.metrics != nil && p.metrics.sink == nil {
+		p.metrics = nil
 	}
-	if pool.minWorkers > pool.maxWorkers {
-		pool.minWorkers = pool.maxWorkers
+	if p.metrics != nil {
+		p.metrics.normalize(p.name, p.tags)
 	}
-	if pool.maxCapacity < 0 {
-		pool.maxCapacity = 0
+	if p.health != nil {
+		p.health.normalize()
 	}
-	if pool.idleTimeout < 0 {
-		pool.idleTimeout = defaultIdleTimeout
+	if p.scaling != nil {
+		p.scaling.normalize()
+	}
+	if p.lanes != nil && p.lanes.interactivePerBatch < 0 {
+		p.lanes.interactivePerBatch = 0
+	}
+	for label, weight := range p.labelWeights {
+		if weight <= 0 {
+			delete(p.labelWeights, label)
+		}
+	}
+	if p.lanes == nil || p.reservedWorkers < 0 {
+		p.reservedWorkers = 0
+	} else if p.reservedWorkers >= p.maxWorkers {
+		p.reservedWorkers = p.maxWorkers - 1
+	}
+	if len(p.cpus) > 0 {
+		cpus := p.cpus[:0]
+		for _, cpu := range p.cpus {
+			if cpu >= 0 {
+				cpus = append(cpus, cpu)
+			}
+		}
+		p.cpus = cpus
+	}
+	if u := p.utilization; u != nil && (u.target <= 0 || u.target > 1 || u.window <= 0) {
+		p.utilization = nil
+	}
+	if s := p.starvation; s != nil && (s.threshold <= 0 || s.handler == nil) {
+		p.starvation = nil
+	}
+	if p.forget == nil {
+		FireAndForget(0, DiscardOverflow)(p)
+	}
+	p.forget.normalize(p.maxWorkers)
+	p.adaptive.normalize(p.maxWorkers)
+	if p.strategy == nil {
+		p.strategy = Eager()
+	}
+	if p.panicHandler == nil {
+		p.panicHandler = defaultPanicHandler
+	}
+	if p.reporter != nil && p.reportInterval <= 0 {
+		p.reporter = nil
+	}
+}
+
+// validate returns an error describing the first invalid or conflicting configuration value
+func (p *WorkerPool) validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, fmt.Sprintf(format, args...))
 	}
 
+	switch {
+	case p.maxWorkers <= 0:
+		return invalid("maxWorkers must be greater than 0, got %d", p.maxWorkers)
+	case p.maxCapacity < 0:
+		return invalid("maxCapacity must not be negative, got %d", p.maxCapacity)
+	case p.minWorkers < 0:
+		return invalid("minWorkers must not be negative, got %d", p.minWorkers)
+	case p.minWorkers > p.maxWorkers:
+		return invalid("minWorkers (%d) must not be greater than maxWorkers (%d)", p.minWorkers, p.maxWorkers)
+	case p.idleTimeout <= 0:
+		return invalid("idle timeout must be greater than 0, got %v", p.idleTimeout)
+	case p.maxQueueAge < 0:
+		return invalid("max queue age must not be negative, got %v", p.maxQueueAge)
+	case p.idleJitter < 0 || p.idleJitter > 1:
+		return invalid("idle jitter must be between 0 and 1, got %v", p.idleJitter)
+	case p.keepWarm < 0:
+		return invalid("keepWarm must not be negative, got %d", p.keepWarm)
+	case p.callSiteDepth < 0 || p.callSiteDepth > maxCallSiteDepth:
+		return invalid("call site depth must be between 0 and %d, got %d", maxCallSiteDepth, p.callSiteDepth)
+	case p.baseContext == nil:
+		return invalid("base context must not be nil")
+	case p.strategy == nil:
+		return invalid("resizing strategy must not be nil")
+	case p.panicHandler == nil:
+		return invalid("panic handler must not be nil")
+	case p.queueOrder < FIFO || p.queueOrder > EarliestDeadlineFirst:
+		return invalid("unknown queue order %v", p.queueOrder)
+	case p.queueOrder != FIFO && p.maxCapacity == 0:
+		return invalid("queue order %v has no effect when maxCapacity is 0, since tasks are never queued", p.queueOrder)
+	case p.labels.maxLabels < 1:
+		return invalid("max labels must be greater than 0, got %d", p.labels.maxLabels)
+	case p.reporter != nil && p.reportInterval <= 0:
+		return invalid("report interval must be greater than 0, got %v", p.reportInterval)
+	case p.tenants.defaultQuota.MaxConcurrency < 0 || p.tenants.defaultQuota.MaxQueueDepth < 0:
+		return invalid("default tenant quota must not be negative")
+	}
+
+	if p.lanes != nil && p.lanes.interactivePerBatch < 0 {
+		return invalid("interactive tasks per batch task must not be negative, got %d", p.lanes.interactivePerBatch)
+	}
+	if p.lanes != nil && p.maxCapacity == 0 {
+		return invalid("lanes have no effect when maxCapacity is 0, since tasks are never queued")
+	}
+	for label, weight := range p.labelWeights {
+		if weight <= 0 {
+			return invalid("weight of label %q must be greater than 0, got %d", label, weight)
+		}
+	}
+	if p.labelWeights != nil && p.maxCapacity == 0 {
+		return invalid("label weights have no effect when maxCapacity is 0, since tasks are never queued")
+	}
+	if p.reservedWorkers != 0 && p.lanes == nil {
+		return invalid("reserved workers require lanes")
+	}
+	if p.reservedWorkers < 0 || (p.reservedWorkers > 0 && p.reservedWorkers >= p.maxWorkers) {
+		return invalid("reserved workers must be between 0 and maxWorkers (%d) excluded, got %d", p.maxWorkers, p.reservedWorkers)
+	}
+	for _, cpu := range p.cpus {
+		if cpu < 0 {
+			return invalid("CPUs to pin workers to must not be negative, got %d", cpu)
+		}
+	}
+	if u := p.utilization; u != nil {
+		if u.target <= 0 || u.target > 1 {
+			return invalid("target utilization must be greater than 0 and at most 1, got %v", u.target)
+		}
+		if u.window <= 0 {
+			return invalid("utilization window must be greater than 0, got %v", u.window)
+		}
+	}
+	if s := p.starvation; s != nil {
+		if s.threshold <= 0 {
+			return invalid("starvation threshold must be greater than 0, got %v", s.threshold)
+		}
+		if s.handler == nil {
+			return invalid("starvation handler must not be nil")
+		}
+	}
+	if a := p.adaptive; a != nil {
+		if a.config.LatencyThreshold <= 0 {
+			return invalid("adaptive concurrency latency threshold must be greater than 0, got %v", a.config.LatencyThreshold)
+		}
+		if a.config.MinLimit < 0 || a.config.InitialLimit < 0 {
+			return invalid("adaptive concurrency limits must not be negative")
+		}
+		if a.config.BackoffRatio < 0 || a.config.BackoffRatio >= 1 {
+			return invalid("adaptive concurrency backoff ratio must be between 0 and 1, got %v", a.config.BackoffRatio)
+		}
+	}
+	if p.forget != nil && p.forget.maxOutstanding < 0 {
+		return invalid("maximum outstanding fire-and-forget tasks must not be negative, got %d", p.forget.maxOutstanding)
+	}
+	if s := p.scaling; s != nil {
+		if s.policy.MaxStartsPerSecond < 0 || s.policy.ScaleDownDelay < 0 {
+			return invalid("scaling policy rate and delay must not be negative")
+		}
+		if s.policy.ScaleDownThreshold < 0 || s.policy.ScaleDownThreshold > 1 {
+			return invalid("scale down threshold must be between 0 and 1, got %v", s.policy.ScaleDownThreshold)
+		}
+	}
+	if p.submitters != nil && p.submitters.maxQueued < 0 {
+		return invalid("max queued tasks per submitter must not be negative, got %d", p.submitters.maxQueued)
+	}
+	if m := p.pressure; m != nil {
+		if m.high <= 0 || m.high > 1 || m.low < 0 || m.low > m.high {
+			return invalid("backpressure watermarks must satisfy 0 <= low <= high <= 1 and high > 0, got low %v and high %v", m.low, m.high)
+		}
+	}
+	if m := p.metrics; m != nil {
+		if m.sink == nil {
+			return invalid("metrics sink must not be nil")
+		}
+		if m.interval < 0 {
+			return invalid("metrics interval must not be negative, got %v", m.interval)
+		}
+	}
+	if m := p.health; m != nil {
+		c := m.criteria
+		if c.MaxSaturation < 0 || c.MaxTaskDuration < 0 || c.RejectionWindow < 0 {
+			return invalid("health criteria durations must not be negative")
+		}
+		if c.MaxRejectionRate < 0 || c.MaxRejectionRate > 1 {
+			return invalid("max rejection rate must be between 0 and 1, got %v", c.MaxRejectionRate)
+		}
+	}
+
+	return nil
+}
+
+// start creates the queue and launches the background goroutines of the pool
+func (p *WorkerPool) start() {
+
 	// Initialize base context (if not already set)
-	if pool.context == nil {
-		Context(context.Background())(pool)
+	if p.context == nil {
+		Context(context.Background())(p)
+	}
+
+	// Initialize the context passed to context-aware tasks, which is canceled along with the base context or by Abort
+	p.taskContext, p.taskContextCancel = context.WithCancel(p.baseContext)
+
+	p.assignShardCPUs()
+
+	// Create tasks queue
+	newBuffer := func() taskBuffer {
+		if p.labelWeights != nil {
+			return newWeightedBuffer(p.queueOrder, p.labelWeights)
+		}
+		return newTaskBuffer(p.queueOrder)
+	}
+	p.tasks = newTaskQueue(p.maxCapacity, p.queueOrder)
+	if p.lanes != nil {
+		maxBatch := 0
+		if p.reservedWorkers > 0 {
+			maxBatch = p.maxWorkers - p.reservedWorkers
+		}
+		p.tasks.buffer = newLaneBuffer(newBuffer, p.lanes.interactivePerBatch, maxBatch)
+	} else {
+		p.tasks.buffer = newBuffer()
 	}
 
-	// Create tasks channel
-	pool.tasks = make(chan func(), pool.maxCapacity)
+	// Create the semaphore that tracks the concurrency budget shared by tasks and Acquire callers
+	p.semaphore = newSemaphore(int64(p.maxWorkers))
 
 	// Start purger goroutine
-	pool.workersWaitGroup.Add(1)
-	go pool.purge()
+	p.workersWaitGroup.Add(1)
+	go p.purge()
+
+	// Start metrics goroutine
+	if p.metrics != nil {
+		p.workersWaitGroup.Add(1)
+		go p.reportMetrics()
+	}
+
+	// Start the goroutine that checks for starving tasks
+	if p.starvation != nil {
+		p.workersWaitGroup.Add(1)
+		go p.watchStarvation()
+	}
+
+	// Start the goroutine that shrinks the pool to its target utilization
+	if p.utilization != nil {
+		p.workersWaitGroup.Add(1)
+		go p.scaleToUtilization()
+	}
+
+	// Start reporter goroutine
+	if p.reporter != nil {
+		p.workersWaitGroup.Add(1)
+		go p.report()
+	}
 
 	// Start minWorkers workers
-	if pool.minWorkers > 0 {
-		for i := 0; i < pool.minWorkers; i++ {
-			pool.maybeStartWorker(nil)
+	if p.minWorkers > 0 {
+		for i := 0; i < p.minWorkers; i++ {
+			p.maybeStartWorker(nil)
 		}
 	}
-
-	return pool
 }
 
 // RunningWorkers returns the current number of running workers
 func (p *WorkerPool) RunningWorkers() int {
-	return int(atomic.LoadInt32(&p.workerCount))
+	return int(atomic.LoadInt32(&p.workerCount.value))
 }
 
 // IdleWorkers returns the current number of idle workers
 func (p *WorkerPool) IdleWorkers() int {
-	return int(atomic.LoadInt32(&p.idleWorkerCount))
+	return int(atomic.LoadInt32(&p.idleWorkerCount.value))
 }
 
 // MinWorkers returns the minimum number of worker goroutines
@@ -175,7 +630,29 @@ func (p *WorkerPool) MaxWorkers() int {
 // MaxCapacity returns the maximum number of tasks that can be waiting in the queue
 // at any given time (queue size)
 func (p *WorkerPool) MaxCapacity() int {
-	return p.maxCapacity
+	return p.tasks.Cap()
+}
+
+// QueueCap returns the current capacity of the queue. It's equivalent to MaxCapacity.
+func (p *WorkerPool) QueueCap() int {
+	return p.tasks.Cap()
+}
+
+// QueueLen returns the number of tasks currently held in the queue. Unlike WaitingTasks, it does not include
+// tasks that submitters are blocked trying to enqueue.
+func (p *WorkerPool) QueueLen() int {
+	return p.tasks.Len()
+}
+
+// ResizeQueue changes the capacity of the queue without restarting the pool. Growing it unblocks waiting submitters;
+// shrinking it keeps the tasks already queued and makes new ones wait until there is room for them.
+func (p *WorkerPool) ResizeQueue(capacity int) {
+	if capacity < 0 {
+		capacity = 0
+	}
+
+	p.tasks.Resize(capacity)
+	p.updatePressure()
 }
 
 // Strategy returns the configured pool resizing strategy
@@ -185,23 +662,48 @@ func (p *WorkerPool) Strategy() ResizingStrategy {
 
 // SubmittedTasks returns the total number of tasks submitted since the pool was created
 func (p *WorkerPool) SubmittedTasks() uint64 {
-	return atomic.LoadUint64(&p.submittedTaskCount)
+	return atomic.LoadUint64(&p.submittedTaskCount.value)
 }
 
 // WaitingTasks returns the current number of tasks in the queue that are waiting to be executed
 func (p *WorkerPool) WaitingTasks() uint64 {
-	return atomic.LoadUint64(&p.waitingTaskCount)
+	return atomic.LoadUint64(&p.waitingTaskCount.value)
+}
+
+// OldestWaiting returns how long the oldest task waiting to start (in the queue or in the queue of a tenant, see
+// SubmitTenant) has been waiting, or 0 if no task is waiting. Unlike the number of waiting tasks, it tells how far
+// behind the pool is, which makes it the best indicator of overload. It scans the queue while holding its lock.
+func (p *WorkerPool) OldestWaiting() time.Duration {
+	oldest := p.tenants.oldestSubmission()
+
+	p.tasks.Each(func(task *queuedTask) bool {
+		if oldest.IsZero() || task.info.SubmittedAt.Before(oldest) {
+			oldest = task.info.SubmittedAt
+		}
+		return true
+	})
+
+	if oldest.IsZero() {
+		return 0
+	}
+	return p.now().Sub(oldest)
 }
 
 // SuccessfulTasks returns the total number of tasks that have successfully completed their exection
 // since the pool was created
 func (p *WorkerPool) SuccessfulTasks() uint64 {
-	return atomic.LoadUint64(&p.successfulTaskCount)
+	return atomic.LoadUint64(&p.successfulTaskCount.value)
 }
 
 // FailedTasks returns the total number of tasks that completed with panic since the pool was created
 func (p *WorkerPool) FailedTasks() uint64 {
-	return atomic.LoadUint64(&p.failedTaskCount)
+	return atomic.LoadUint64(&p.failedTaskCount.value)
+}
+
+// ExpiredTasks returns the total number of tasks that were discarded because their deadline passed
+// or they exceeded the maximum queue age before they started executing
+func (p *WorkerPool) ExpiredTasks() uint64 {
+	return atomic.LoadUint64(&p.expiredTaskCount)
 }
 
 // CompletedTasks returns the total number of tasks that have completed their exection either successfully
@@ -210,6 +712,11 @@ func (p *WorkerPool) CompletedTasks() uint64 {
 	return p.SuccessfulTasks() + p.FailedTasks()
 }
 
+// Name returns the name of this pool
+func (p *WorkerPool) Name() string {
+	return p.name
+}
+
 // Stopped returns true if the pool has been stopped and is no longer accepting tasks, and false otherwise.
 func (p *WorkerPool) Stopped() bool {
 	return atomic.LoadInt32(&p.stopped) == 1
@@ -218,22 +725,34 @@ func (p *WorkerPool) Stopped() bool {
 // Submit sends a task to this worker pool for execution. If the queue is full,
 // it will wait until the task is dispatched to a worker goroutine.
 func (p *WorkerPool) Submit(task func()) {
-	p.submit(task, true)
+	p.submit(newQueuedTask(task), true)
 }
 
 // TrySubmit attempts to send a task to this worker pool for execution. If the queue is full,
 // it will not wait for a worker to become idle. It returns true if it was able to dispatch
 // the task and false otherwise.
 func (p *WorkerPool) TrySubmit(task func()) bool {
-	return p.submit(task, false)
+	return p.submit(newQueuedTask(task), false)
 }
 
-func (p *WorkerPool) submit(task func(), mustSubmit bool) (submitted bool) {
-	if task == nil {
+// submit sends the given task to the pool, returning false if it was not accepted (see trySubmit)
+func (p *WorkerPool) submit(task *queuedTask, mustSubmit bool) bool {
+	submitted, _ := p.trySubmit(task, mustSubmit)
+	return submitted
+}
+
+// trySubmit sends the given task to the pool. If the queue is full, it waits for room only if mustSubmit is true.
+// If the task is not accepted, it returns false along with the error of the submit hook that vetoed it, if any.
+// It panics if mustSubmit is true and the pool is stopped.
+func (p *WorkerPool) trySubmit(task *queuedTask, mustSubmit bool) (submitted bool, err error) {
+	if task.run == nil {
 		return
 	}
 
 	if p.Stopped() {
+		p.submitters.release(task.info.Submitter)
+		p.dropTask(DropStopped, task.info)
+
 		// Pool is stopped and caller must submit the task
 		if mustSubmit {
 			panic(ErrSubmitOnStoppedPool)
@@ -241,43 +760,99 @@ func (p *WorkerPool) submit(task func(), mustSubmit bool) (submitted bool) {
 		return
 	}
 
+	if p.callSiteDepth > 0 && task.info.SubmittedFrom == "" {
+		task.info.SubmittedFrom = captureCallSite(p.callSiteDepth)
+	}
+
+	task.info.Pool, task.info.Tags = p.name, p.tags
+	if p.deterministic != nil {
+		task.info.SubmittedAt = p.now()
+	}
+
+	if err = p.runSubmitHooks(task); err != nil {
+		return
+	}
+
 	// Increment submitted and waiting task counters as soon as we receive a task
-	atomic.AddUint64(&p.submittedTaskCount, 1)
-	atomic.AddUint64(&p.waitingTaskCount, 1)
+	atomic.AddUint64(&p.submittedTaskCount.value, 1)
+	atomic.AddUint64(&p.waitingTaskCount.value, 1)
+	p.lanes.submit(task.info.Lane)
 	p.tasksWaitGroup.Add(1)
 
 	defer func() {
 		if !submitted {
-			// Task was not sumitted to the pool, decrement submitted and waiting task counters
-			atomic.AddUint64(&p.submittedTaskCount, ^uint64(0))
-			atomic.AddUint64(&p.waitingTaskCount, ^uint64(0))
-			p.tasksWaitGroup.Done()
+			p.unsubmit(task)
 		}
+		p.health.recordSubmission(!submitted, p.now())
+		p.updatePressure()
 	}()
 
-	// Start a worker as long as we haven't reached the limit
-	if submitted = p.maybeStartWorker(task); submitted {
-		return
-	}
-
-	if !mustSubmit {
-		// Attempt to dispatch to an idle worker without blocking
-		select {
-		case p.tasks <- task:
-			submitted = true
-			return
-		default:
-			// Channel is full and can't wait for an idle worker, so need to exit
+	// Start a worker as long as we haven't reached the limit, and the task is allowed to run (see ReserveWorkers)
+	if p.tasks.Admit(task) {
+		if submitted = p.maybeStartWorker(task); submitted {
 			return
 		}
+		p.tasks.Release(task)
 	}
 
-	// Submit the task to the tasks channel and wait for it to be picked up by a worker
-	p.tasks <- task
-	submitted = true
+	// Submit the task to the queue. If the queue is full, wait for a worker to make room only if the caller must submit it.
+	if submitted = p.tasks.Push(task, mustSubmit); !submitted && mustSubmit {
+		// Queue was closed while waiting, which means the pool was stopped
+		panic(ErrSubmitOnStoppedPool)
+	}
 	return
 }
 
+// submitUnlessStopped sends the given task to the pool, waiting for room in the queue if needed, like trySubmit
+// with mustSubmit set. Unlike it, it returns ErrSubmitOnStoppedPool instead of panicking if the pool is stopped
+// before the task is accepted, as well as the error of the submit hook that vetoed the task, if any.
+func (p *WorkerPool) submitUnlessStopped(task *queuedTask) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			if value != ErrSubmitOnStoppedPool {
+				panic(value)
+			}
+			err = ErrSubmitOnStoppedPool
+		}
+	}()
+
+	_, err = p.trySubmit(task, true)
+	return
+}
+
+// unsubmit reverts the accounting of a task that was not accepted by the pool and reports it as dropped
+func (p *WorkerPool) unsubmit(task *queuedTask) {
+	// Task was not sumitted to the pool, decrement submitted and waiting task counters
+	atomic.AddUint64(&p.submittedTaskCount.value, ^uint64(0))
+	atomic.AddUint64(&p.waitingTaskCount.value, ^uint64(0))
+	p.lanes.unsubmit(task.info.Lane)
+	p.tasksWaitGroup.Done()
+	p.submitters.release(task.info.Submitter)
+	p.checkInvariants()
+
+	if p.Stopped() {
+		p.dropTask(DropStopped, task.info)
+	} else {
+		p.dropTask(DropQueueFull, task.info)
+	}
+}
+
+// SubmitLabeled sends a task to this worker pool for execution, just like Submit, attaching the given label to it.
+// The label identifies the kind of task in the metadata passed to handlers, such as the panic handler.
+func (p *WorkerPool) SubmitLabeled(label string, task func()) {
+	queued := newQueuedTask(task)
+	queued.info.Label = label
+
+	p.submit(queued, true)
+}
+
+// SubmitContext sends a context-aware task to this worker pool for execution, just like Submit.
+// The context passed to the task carries the task's metadata, which can be retrieved with FromContext,
+// and it's canceled if the pool is aborted (see Abort).
+func (p *WorkerPool) SubmitContext(task func(ctx context.Context)) {
+	p.submit(newContextTask(p.taskContext, task), true)
+}
+
 // SubmitAndWait sends a task to this worker pool for execution and waits for it to complete
 // before returning
 func (p *WorkerPool) SubmitAndWait(task func()) {
@@ -286,32 +861,32 @@ func (p *WorkerPool) SubmitAndWait(task func()) {
 	}
 
 	done := make(chan struct{})
-	p.Submit(func() {
+	queued := newQueuedTask(func() {
 		defer close(done)
 		task()
 	})
+	queued.onReject = func(error) {
+		close(done)
+	}
+
+	p.submit(queued, true)
 	<-done
 }
 
 // SubmitBefore attempts to send a task for execution to this worker pool but aborts it
 // if the task did not start before the given deadline.
 func (p *WorkerPool) SubmitBefore(task func(), deadline time.Duration) {
-	if task == nil {
-		return
-	}
+	p.SubmitWithDeadline(task, p.now().Add(deadline))
+}
 
-	timer := time.NewTimer(deadline)
-	p.Submit(func() {
-		select {
-		case <-timer.C:
-			// Deadline was reached, abort the task
-		default:
-			// Deadline not reached, execute the task
-			defer timer.Stop()
+// SubmitWithDeadline sends a task to this worker pool for execution, but discards it if it did not start
+// before the given deadline. Discarded tasks are reported to the expired task handler (see ExpiredTaskHandler).
+// When the pool dispatches tasks in EarliestDeadlineFirst order, the task with the earliest deadline runs first.
+func (p *WorkerPool) SubmitWithDeadline(task func(), deadline time.Time) {
+	queued := newQueuedTask(task)
+	queued.info.Deadline = deadline
 
-			task()
-		}
-	})
+	p.submit(queued, true)
 }
 
 // Stop causes this pool to stop accepting new tasks and signals all workers to exit.
@@ -321,6 +896,14 @@ func (p *WorkerPool) Stop() {
 	go p.stop(false)
 }
 
+// StopDiscarding causes this pool to stop accepting new tasks and discards the tasks in the queue, just like Stop,
+// but it waits for the tasks being executed to complete and returns the number of queued tasks that were discarded,
+// e.g. to account for them or replay them on shutdown. Discarded tasks are also reported to the DroppedTaskHandler,
+// and they complete with ErrSubmitOnStoppedPool, so the groups and callers waiting for them return.
+func (p *WorkerPool) StopDiscarding() (discarded int) {
+	return p.stop(false)
+}
+
 // StopAndWait causes this pool to stop accepting new tasks and then waits for all tasks in the queue
 // to complete before returning.
 func (p *WorkerPool) StopAndWait() {
@@ -344,11 +927,14 @@ func (p *WorkerPool) StopAndWaitFor(deadline time.Duration) {
 		return
 	case <-time.After(deadline):
 		p.contextCancel()
+
+		// Discard the tasks that are still queued, since workers are exiting
+		p.closeQueue()
 		return
 	}
 }
 
-func (p *WorkerPool) stop(waitForQueuedTasksToComplete bool) {
+func (p *WorkerPool) stop(waitForQueuedTasksToComplete bool) (discarded int) {
 	// Mark pool as stopped
 	atomic.StoreInt32(&p.stopped, 1)
 
@@ -366,24 +952,30 @@ func (p *WorkerPool) stop(waitForQueuedTasksToComplete bool) {
 	// Wait for all workers & purger goroutine to exit
 	p.workersWaitGroup.Wait()
 
-	// close tasks channel (only once, in case multiple concurrent calls to StopAndWait are made)
-	p.tasksCloseOnce.Do(func() {
-		close(p.tasks)
-	})
+	// Release the resources of the context passed to tasks, now that they have all returned
+	p.taskContextCancel()
+
+	// Close tasks queue and discard the tasks left in it (it can be called multiple times, in case multiple
+	// concurrent calls to StopAndWait are made)
+	discarded = p.closeQueue()
+	p.checkSettled()
+
+	return discarded
 }
 
 // purge represents the work done by the purger goroutine
 func (p *WorkerPool) purge() {
 	defer p.workersWaitGroup.Done()
 
-	idleTicker := time.NewTicker(p.idleTimeout)
-	defer idleTicker.Stop()
+	idleTimer := time.NewTimer(p.nextIdleTimeout())
+	defer idleTimer.Stop()
 
 	for {
 		select {
 		// Timed out waiting for any activity to happen, attempt to stop an idle worker
-		case <-idleTicker.C:
+		case <-idleTimer.C:
 			p.maybeStopIdleWorker()
+			idleTimer.Reset(p.nextIdleTimeout())
 		// Pool context was cancelled, exit
 		case <-p.context.Done():
 			return
@@ -391,23 +983,29 @@ func (p *WorkerPool) purge() {
 	}
 }
 
-// maybeStopIdleWorker attempts to stop an idle worker by sending it a nil task
-func (p *WorkerPool) maybeStopIdleWorker() bool {
-
-	if decremented := p.decrementWorkerCount(); !decremented {
-		return false
+// nextIdleTimeout returns the time to wait before attempting to stop the next idle worker
+func (p *WorkerPool) nextIdleTimeout() time.Duration {
+	if p.idleJitter <= 0 {
+		return p.idleTimeout
 	}
 
-	// Send a nil task to stop an idle worker
-	p.tasks <- nil
+	// Pick a timeout in [idleTimeout * (1 - jitter), idleTimeout * (1 + jitter)]
+	spread := int64(float64(p.idleTimeout) * p.idleJitter)
+	if spread <= 0 {
+		return p.idleTimeout
+	}
+	return p.idleTimeout - time.Duration(spread) + time.Duration(p.int63n(2*spread+1))
+}
 
-	return true
+// maybeStopIdleWorker attempts to stop an idle worker
+func (p *WorkerPool) maybeStopIdleWorker() bool {
+	return p.decrementWorkerCount()
 }
 
 // maybeStartWorker attempts to create a new worker goroutine to run the given task.
 // If the worker pool has reached the maximum number of workers or there are idle workers,
 // it will not create a new one.
-func (p *WorkerPool) maybeStartWorker(firstTask func()) bool {
+func (p *WorkerPool) maybeStartWorker(firstTask *queuedTask) bool {
 
 	if incremented := p.incrementWorkerCount(); !incremented {
 		return false
@@ -415,59 +1013,209 @@ func (p *WorkerPool) maybeStartWorker(firstTask func()) bool {
 
 	if firstTask == nil {
 		// Worker starts idle
-		atomic.AddInt32(&p.idleWorkerCount, 1)
+		atomic.AddInt32(&p.idleWorkerCount.value, 1)
 	}
 
 	// Launch worker goroutine
-	go worker(p.context, &p.workersWaitGroup, firstTask, p.tasks, p.executeTask)
+	go p.startWorker(firstTask)
 
 	return true
 }
 
-// executeTask executes the given task and updates task-related counters
-func (p *WorkerPool) executeTask(task func(), isFirstTask bool) {
+// startWorker runs a new worker with the given first task, pinned to the pool's CPUs if any. The ID of the worker
+// is returned to the pool once it exits, so a later worker can reuse it.
+func (p *WorkerPool) startWorker(firstTask *queuedTask) {
+	workerID := p.workerIDs.acquire(p.RunningWorkers)
+	defer p.workerIDs.release(workerID)
+
+	if len(p.cpus) > 0 {
+		p.pinnedWorker(p.workerContext(workerID), firstTask)
+		return
+	}
+	worker(p.workerContext(workerID), &p.workersWaitGroup, firstTask, p.tasks, p.executeWorkerTask)
+}
+
+// workerContext returns the context for the worker with the given ID, which carries the profiler labels that identify
+// it and its state
+func (p *WorkerPool) workerContext(workerID uint64) context.Context {
+	ctx := pprof.WithLabels(p.context, pprof.Labels(
+		poolProfilerLabel, p.name,
+		workerProfilerLabel, strconv.FormatUint(workerID, 10),
+	))
+	return withWorkerState(ctx, &workerState{id: workerID})
+}
+
+// executeWorkerTask executes a task dequeued by a worker, notifying the event listener when the worker becomes busy
+// and idle again
+func (p *WorkerPool) executeWorkerTask(ctx context.Context, task *queuedTask, isFirstTask bool) {
+	if p.events.OnWorkerBusy != nil {
+		p.events.OnWorkerBusy(workerStateFrom(ctx).id)
+	}
+
+	p.executeTask(ctx, task, isFirstTask, false)
+
+	if p.events.OnWorkerIdle != nil {
+		p.events.OnWorkerIdle(workerStateFrom(ctx).id)
+	}
+}
+
+// executeTask executes the given task and updates task-related counters. The worker is counted as busy while
+// the task runs, unless it was started to run it (isFirstTask) or the task runs in place of a yielding task (nested),
+// in which case the worker is already counted as busy. Nested tasks leave the worker busy once they are done.
+func (p *WorkerPool) executeTask(ctx context.Context, task *queuedTask, isFirstTask, nested bool) {
+
+	// Wait for a slot in the concurrency budget, which may be held by Acquire callers or other pools,
+	// and within the adaptive concurrency limit
+	p.acquire(context.Background(), 1)
+	p.adaptive.acquire()
+
+	var allocatedBefore uint64
 
 	defer func() {
-		if panic := recover(); panic != nil {
+		panic := recover()
+		if panic != nil {
 			// Increment failed task count
-			atomic.AddUint64(&p.failedTaskCount, 1)
+			atomic.AddUint64(&p.failedTaskCount.value, 1)
+			if !task.info.StartedAt.IsZero() {
+				p.finishTask(task, taskFailed, allocatedBefore, panic)
+			}
 
 			// Invoke panic handler
-			p.panicHandler(panic)
+			p.handlePanic(panic, task.info)
 
 			// Increment idle count
-			atomic.AddInt32(&p.idleWorkerCount, 1)
+			if !nested {
+				atomic.AddInt32(&p.idleWorkerCount.value, 1)
+			}
+		}
+		p.health.taskFinished(task.info.ID)
+		p.tasks.Release(task)
+		if !task.info.StartedAt.IsZero() && (panic != nil || atomic.LoadInt32(&task.latencyReported) == 0) {
+			// Tasks that reported the latency of their downstream calls were already taken into account
+			p.adaptive.observe(task.info.Duration, panic != nil, p.now())
 		}
+		p.adaptive.release()
+		p.release(1)
+		p.settleTask(task)
 		p.tasksWaitGroup.Done()
 	}()
 
 	// Decrement idle count
-	if !isFirstTask {
-		atomic.AddInt32(&p.idleWorkerCount, -1)
+	if !isFirstTask && !nested {
+		atomic.AddInt32(&p.idleWorkerCount.value, -1)
 	}
 
 	// Decrement waiting task count
-	atomic.AddUint64(&p.waitingTaskCount, ^uint64(0))
+	atomic.AddUint64(&p.waitingTaskCount.value, ^uint64(0))
+	p.lanes.dequeue(task.info.Lane)
+	p.updatePressure()
+	p.submitters.release(task.info.Submitter)
+	now := p.now()
+	p.queueWait.observe(now.Sub(task.info.SubmittedAt))
+
+	// Discard the task if its deadline passed or it grew too old while it was waiting
+	if task.expired(now, p.maxQueueAge) {
+		atomic.AddUint64(&p.expiredTaskCount, 1)
+		p.labels.record(task.info, taskExpired)
+		p.trace.record(task.info, taskExpired, now)
+
+		if p.expiredTaskHandler != nil {
+			p.expiredTaskHandler(task.info)
+		}
+		task.discard(DropExpired.err())
+		p.dropTask(DropExpired, task.info)
+
+		if !nested {
+			atomic.AddInt32(&p.idleWorkerCount.value, 1)
+		}
+		return
+	}
 
 	// Execute task
-	task()
+	if p.measureAllocations {
+		allocatedBefore = allocatedBytes()
+	}
+	task.info.StartedAt = p.now()
+	task.pool, task.workerCtx = p, ctx
+	p.health.taskStarted(task.info.ID, task.info.StartedAt)
+	if task.info.Label != "" {
+		// Annotate the worker goroutine with the task label while it runs
+		pprof.Do(ctx, pprof.Labels(taskProfilerLabel, task.info.Label), func(labeled context.Context) {
+			task.workerCtx = labeled
+			p.runTask(task)
+		})
+	} else {
+		p.runTask(task)
+	}
 
 	// Increment successful task count
-	atomic.AddUint64(&p.successfulTaskCount, 1)
+	atomic.AddUint64(&p.successfulTaskCount.value, 1)
+	p.finishTask(task, taskSucceeded, allocatedBefore, nil)
 
 	// Increment idle count
-	atomic.AddInt32(&p.idleWorkerCount, 1)
+	if !nested {
+		atomic.AddInt32(&p.idleWorkerCount.value, 1)
+	}
+}
+
+// runTask runs the given task, then calls the cleanup functions it registered (see OnTaskDone), even if it panics
+func (p *WorkerPool) runTask(task *queuedTask) {
+	defer p.runCleanups(task)
+
+	task.run()
+}
+
+// finishTask records the resources used by a task that returned or panicked, and notifies the event listener
+func (p *WorkerPool) finishTask(task *queuedTask, outcome taskOutcome, allocatedBefore uint64, panic interface{}) {
+	task.info.Duration = p.now().Sub(task.info.StartedAt)
+	p.clearCheckpoint(task)
+	if p.measureAllocations {
+		task.info.AllocatedBytes = allocatedBytes() - allocatedBefore
+	}
+
+	p.labels.record(task.info, outcome)
+	p.trace.record(task.info, outcome, task.info.StartedAt.Add(task.info.Duration))
+	p.lanes.complete(task.info.Lane)
+	p.metrics.observe(task.info.Label, task.info.Duration)
+
+	if p.events.OnTaskFinished != nil {
+		p.events.OnTaskFinished(task.info, panic)
+	}
+}
+
+// handlePanic invokes the panic handler, making sure a panic raised by the handler itself doesn't kill the worker.
+// Such failures are logged to stderr and reported to the event listener.
+func (p *WorkerPool) handlePanic(panic interface{}, info TaskInfo) {
+	defer func() {
+		if handlerPanic := recover(); handlerPanic != nil {
+			fmt.Fprintf(os.Stderr, "Panic handler failed%s while handling %v: %v\nStack trace: %s\n", describePool(p.name, p.tags), panic, handlerPanic, string(debug.Stack()))
+
+			if p.events.OnPanicHandlerFailed != nil {
+				p.events.OnPanicHandlerFailed(info, panic, handlerPanic)
+			}
+		}
+	}()
+
+	p.panicHandler(panic, info)
 }
 
 func (p *WorkerPool) incrementWorkerCount() bool {
 
+	// Fast path: an idle worker is waiting for the task or the pool is full, which is checked again with the lock held
+	// below, but not taking the lock here keeps the common case down to a single synchronization operation (the handoff)
+	if running := p.RunningWorkers(); running > 0 && running >= p.minWorkers && (p.IdleWorkers() > 0 || running >= p.effectiveMaxWorkers()) {
+		return false
+	}
+
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
 	runningWorkerCount := p.RunningWorkers()
 
+	maxWorkers := p.effectiveMaxWorkers()
+
 	// Reached max workers, do not create a new one
-	if runningWorkerCount >= p.maxWorkers {
+	if runningWorkerCount >= maxWorkers {
 		return false
 	}
 
@@ -477,12 +1225,18 @@ func (p *WorkerPool) incrementWorkerCount() bool {
 	}
 
 	// Execute the resizing strategy to determine if we should create more workers
-	if resize := p.strategy.Resize(runningWorkerCount, p.minWorkers, p.maxWorkers); !resize {
+	if resize := p.strategy.Resize(runningWorkerCount, p.minWorkers, maxWorkers); !resize {
+		return false
+	}
+
+	// Respect the scaling policy, unless the pool doesn't have enough workers yet
+	if runningWorkerCount >= p.minWorkers && runningWorkerCount > 0 && !p.scaling.allowStart(p.now()) {
 		return false
 	}
 
 	// Increment worker count
-	atomic.AddInt32(&p.workerCount, 1)
+	atomic.AddInt32(&p.workerCount.value, 1)
+	p.checkWorkerLimit(maxWorkers)
 
 	// Increment wait group
 	p.workersWaitGroup.Add(1)
@@ -495,15 +1249,27 @@ func (p *WorkerPool) decrementWorkerCount() bool {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	if p.IdleWorkers() <= 0 || p.RunningWorkers() <= p.minWorkers || p.Stopped() {
+	if p.IdleWorkers() <= 0 || p.RunningWorkers() <= p.minWorkers || p.RunningWorkers() <= p.keepWarm || p.Stopped() {
+		return false
+	}
+
+	// Respect the scaling policy
+	if !p.scaling.allowStop(p.now(), p.RunningWorkers(), p.IdleWorkers()) {
+		return false
+	}
+
+	// Signal a worker waiting for a task to exit. Workers counted as idle may also be about to dequeue a task,
+	// so they are not stopped, otherwise they would decrement the idle count a second time when they do.
+	if !p.tasks.StopOne() {
 		return false
 	}
 
 	// Decrement worker count
-	atomic.AddInt32(&p.workerCount, -1)
+	atomic.AddInt32(&p.workerCount.value, -1)
 
 	// Decrement idle count
-	atomic.AddInt32(&p.idleWorkerCount, -1)
+	atomic.AddInt32(&p.idleWorkerCount.value, -1)
+	p.checkInvariants()
 
 	return true
 }
@@ -514,10 +1280,55 @@ func (p *WorkerPool) resetWorkerCount() {
 	defer p.mutex.Unlock()
 
 	// Reset worker count
-	atomic.StoreInt32(&p.workerCount, 0)
+	atomic.StoreInt32(&p.workerCount.value, 0)
 
 	// Reset idle count
-	atomic.StoreInt32(&p.idleWorkerCount, 0)
+	atomic.StoreInt32(&p.idleWorkerCount.value, 0)
+}
+
+// Acquire reserves n units of this pool's concurrency budget (its maximum number of workers) for code
+// running on the caller's goroutine, blocking until they are available or ctx is done.
+// While held, these units reduce the number of tasks the pool executes concurrently.
+// On success, the caller must call Release with the same n once done. On failure, it returns ctx.Err().
+// If the pool draws from a shared ConcurrencyBudget, the units are reserved from it as well.
+func (p *WorkerPool) Acquire(ctx context.Context, n int) error {
+	return p.acquire(ctx, int64(n))
+}
+
+// TryAcquire reserves n units of this pool's concurrency budget without blocking.
+// It returns true if they were reserved and false otherwise.
+func (p *WorkerPool) TryAcquire(n int) bool {
+	if !p.semaphore.TryAcquire(int64(n)) {
+		return false
+	}
+	if !p.budget.tryAcquire(int64(n)) {
+		p.semaphore.Release(int64(n))
+		return false
+	}
+	return true
+}
+
+// Release returns n units of concurrency budget previously reserved with Acquire or TryAcquire
+func (p *WorkerPool) Release(n int) {
+	p.release(int64(n))
+}
+
+// acquire reserves n units of this pool's semaphore and then of the shared budget (if any)
+func (p *WorkerPool) acquire(ctx context.Context, n int64) error {
+	if err := p.semaphore.Acquire(ctx, n); err != nil {
+		return err
+	}
+	if err := p.budget.acquire(ctx, n); err != nil {
+		p.semaphore.Release(n)
+		return err
+	}
+	return nil
+}
+
+// release returns n units to the shared budget (if any) and then to this pool's semaphore
+func (p *WorkerPool) release(n int64) {
+	p.budget.release(n)
+	p.semaphore.Release(n)
 }
 
 // Group creates a new task group
@@ -531,18 +1342,52 @@ func (p *WorkerPool) Group() *TaskGroup {
 //
 // The derived Context is canceled the first time a function submitted to the group
 // returns a non-nil error or the first time Wait returns, whichever occurs first.
-func (p *WorkerPool) GroupContext(ctx context.Context) (*TaskGroupWithContext, context.Context) {
+// Groups created with the KeepContext option keep it alive after Wait returns if all tasks succeeded.
+func (p *WorkerPool) GroupContext(ctx context.Context, options ...GroupOption) (*TaskGroupWithContext, context.Context) {
 
 	if ctx == nil {
 		panic("a non-nil context needs to be specified when using GroupContext")
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
-	return &TaskGroupWithContext{
+	group := &TaskGroupWithContext{
 		TaskGroup: TaskGroup{
 			pool: p,
 		},
 		ctx:    ctx,
 		cancel: cancel,
-	}, ctx
+	}
+
+	// Apply all options
+	for _, opt := range options {
+		opt(group)
+	}
+
+	return group, ctx
+}
+
+// GroupWithTimeout creates a new task group, just like GroupContext, whose context is derived from the pool's
+// base context (see BaseContext) and expires after the given timeout. Wait returns by that deadline at the latest,
+// with context.DeadlineExceeded if tasks were still pending, so callers can bound their total latency.
+// Tasks that have not started by then are skipped, while running ones should honor the cancellation of the context.
+func (p *WorkerPool) GroupWithTimeout(timeout time.Duration, options ...GroupOption) (*TaskGroupWithContext, context.Context) {
+	return p.groupWithTimeout(p.baseContext, timeout, options...)
+}
+
+// groupWithTimeout creates a new task group whose context is derived from parent and expires after the given timeout
+// (see GroupWithTimeout)
+func (p *WorkerPool) groupWithTimeout(parent context.Context, timeout time.Duration, options ...GroupOption) (*TaskGroupWithContext, context.Context) {
+
+	deadlineCtx, cancelDeadline := context.WithTimeout(parent, timeout)
+
+	group, ctx := p.GroupContext(deadlineCtx, options...)
+	group.deadline, _ = deadlineCtx.Deadline()
+
+	cancel := group.cancel
+	group.cancel = func() {
+		cancel()
+		cancelDeadline()
+	}
+
+	return group, ctx
 }
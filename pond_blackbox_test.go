@@ -1,8 +1,12 @@
 package pond_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"runtime/pprof"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -132,6 +136,65 @@ func TestSubmitAndStopWithoutWaiting(t *testing.T) {
 	time.Sleep(6 * time.Millisecond)
 }
 
+func TestStopDiscarding(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var completed int32
+	pool.Submit(func() {
+		close(started)
+		<-release
+		atomic.AddInt32(&completed, 1)
+	})
+	<-started
+	for i := 0; i < 5; i++ {
+		pool.Submit(func() {
+			atomic.AddInt32(&completed, 1)
+		})
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+
+	// The running task completes but queued ones are discarded
+	assertEqual(t, 5, pool.StopDiscarding())
+	assertEqual(t, int32(1), atomic.LoadInt32(&completed))
+	assertEqual(t, uint64(0), pool.WaitingTasks())
+
+	// Stopping again doesn't discard anything
+	assertEqual(t, 0, pool.StopDiscarding())
+}
+
+func TestSubmitContextWithBaseContext(t *testing.T) {
+
+	type key struct{}
+	baseCtx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "base"))
+	pool := pond.New(1, 10, pond.BaseContext(baseCtx))
+
+	started := make(chan struct{})
+	var value interface{}
+	pool.SubmitContext(func(ctx context.Context) {
+		value = ctx.Value(key{})
+		close(started)
+		<-ctx.Done()
+	})
+	<-started
+
+	// Canceling the base context asks running tasks to return, without stopping the pool
+	cancel()
+	pool.SubmitAndWait(func() {})
+	assertEqual(t, false, pool.Stopped())
+
+	pool.StopAndWait()
+
+	assertEqual(t, "base", value)
+	assertEqual(t, uint64(2), pool.CompletedTasks())
+}
+
 func TestSubmitWithNilTask(t *testing.T) {
 
 	pool := pond.New(2, 5)
@@ -406,6 +469,31 @@ func TestPoolWithCustomIdleTimeout(t *testing.T) {
 	pool.StopAndWait()
 }
 
+func TestPoolWithKeepWarmAndIdleJitter(t *testing.T) {
+
+	pool := pond.New(5, 5, pond.IdleTimeout(1*time.Millisecond), pond.IdleJitter(0.5), pond.KeepWarm(2))
+
+	// Submit tasks to start all workers
+	release := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		pool.Submit(func() {
+			<-release
+		})
+	}
+	assertEqual(t, 5, pool.RunningWorkers())
+	close(release)
+
+	// Wait for some time
+	time.Sleep(50 * time.Millisecond)
+
+	// Only the warm workers should remain
+	assertEqual(t, 2, pool.RunningWorkers())
+
+	pool.StopAndWait()
+
+	assertEqual(t, 0, pool.RunningWorkers())
+}
+
 func TestPoolWithCustomPanicHandler(t *testing.T) {
 
 	var capturedPanic interface{} = nil
@@ -593,3 +681,361 @@ func TestSubmitToIdleWorker(t *testing.T) {
 
 	assertEqual(t, int32(3), atomic.LoadInt32(&doneCount))
 }
+
+func TestAcquireAndRelease(t *testing.T) {
+
+	pool := pond.New(2, 10)
+
+	// Reserve the whole concurrency budget
+	err := pool.Acquire(context.Background(), 2)
+	assertEqual(t, nil, err)
+	assertEqual(t, false, pool.TryAcquire(1))
+
+	var doneCount int32
+	for i := 0; i < 3; i++ {
+		pool.Submit(func() {
+			atomic.AddInt32(&doneCount, 1)
+		})
+	}
+
+	// Tasks cannot run while the budget is held
+	time.Sleep(10 * time.Millisecond)
+	assertEqual(t, int32(0), atomic.LoadInt32(&doneCount))
+
+	pool.Release(2)
+	pool.StopAndWait()
+
+	assertEqual(t, int32(3), atomic.LoadInt32(&doneCount))
+}
+
+func TestAcquireWithCancelledContext(t *testing.T) {
+
+	pool := pond.New(1, 1)
+	defer pool.StopAndWait()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	err := pool.Acquire(ctx, 1)
+	assertEqual(t, context.DeadlineExceeded, err)
+
+	close(release)
+}
+
+func TestSubmitWithLIFOOrder(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.QueueOrder(pond.LIFO))
+
+	// Block the only worker until all tasks are queued
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var order []int
+	for i := 0; i < 5; i++ {
+		n := i
+		pool.Submit(func() {
+			order = append(order, n)
+		})
+	}
+	close(release)
+
+	pool.StopAndWait()
+
+	assertEqual(t, 5, len(order))
+	for i, n := range order {
+		assertEqual(t, 4-i, n)
+	}
+}
+
+func TestSubmitWithDeadlineInEDFOrder(t *testing.T) {
+
+	var expired []pond.TaskInfo
+	pool := pond.New(1, 10, pond.QueueOrder(pond.EarliestDeadlineFirst), pond.ExpiredTaskHandler(func(info pond.TaskInfo) {
+		expired = append(expired, info)
+	}))
+
+	// Block the only worker until all tasks are queued
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	now := time.Now()
+	var order []int
+	pool.SubmitWithDeadline(func() {
+		order = append(order, 1)
+	}, now.Add(1*time.Minute))
+	pool.SubmitWithDeadline(func() {
+		order = append(order, 2)
+	}, now.Add(1*time.Second))
+	pool.Submit(func() {
+		order = append(order, 3)
+	})
+	pool.SubmitWithDeadline(func() {
+		order = append(order, 4)
+	}, now.Add(1*time.Millisecond))
+
+	// Let the last task expire while it's waiting in the queue
+	time.Sleep(5 * time.Millisecond)
+	close(release)
+
+	pool.StopAndWait()
+
+	assertEqual(t, 3, len(order))
+	assertEqual(t, 2, order[0])
+	assertEqual(t, 1, order[1])
+	assertEqual(t, 3, order[2])
+	assertEqual(t, 1, len(expired))
+	assertEqual(t, now.Add(1*time.Millisecond), expired[0].Deadline)
+	assertEqual(t, uint64(1), pool.ExpiredTasks())
+	assertEqual(t, uint64(4), pool.CompletedTasks())
+}
+
+func TestSubmitWithMaxQueueAge(t *testing.T) {
+
+	var expiredCount int32
+	pool := pond.New(1, 10, pond.MaxQueueAge(5*time.Millisecond), pond.ExpiredTaskHandler(func(info pond.TaskInfo) {
+		atomic.AddInt32(&expiredCount, 1)
+	}))
+
+	// Block the only worker long enough for queued tasks to grow old
+	pool.Submit(func() {
+		time.Sleep(20 * time.Millisecond)
+	})
+
+	var doneCount int32
+	for i := 0; i < 3; i++ {
+		pool.Submit(func() {
+			atomic.AddInt32(&doneCount, 1)
+		})
+	}
+
+	pool.StopAndWait()
+
+	assertEqual(t, int32(0), atomic.LoadInt32(&doneCount))
+	assertEqual(t, int32(3), atomic.LoadInt32(&expiredCount))
+	assertEqual(t, uint64(3), pool.ExpiredTasks())
+	assertEqual(t, uint64(0), pool.WaitingTasks())
+}
+
+func TestResizeQueue(t *testing.T) {
+
+	pool := pond.New(1, 1)
+	assertEqual(t, 1, pool.QueueCap())
+	assertEqual(t, 0, pool.QueueLen())
+
+	// Block the only worker and fill the queue
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	pool.Submit(func() {})
+	assertEqual(t, 1, pool.QueueLen())
+	assertEqual(t, false, pool.TrySubmit(func() {}))
+
+	// Widen the queue to accept more tasks
+	pool.ResizeQueue(3)
+	assertEqual(t, 3, pool.QueueCap())
+	assertEqual(t, 3, pool.MaxCapacity())
+	assertEqual(t, true, pool.TrySubmit(func() {}))
+	assertEqual(t, true, pool.TrySubmit(func() {}))
+	assertEqual(t, false, pool.TrySubmit(func() {}))
+	assertEqual(t, 3, pool.QueueLen())
+
+	// Shrinking keeps queued tasks
+	pool.ResizeQueue(-1)
+	assertEqual(t, 0, pool.QueueCap())
+	assertEqual(t, 3, pool.QueueLen())
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, uint64(4), pool.SuccessfulTasks())
+}
+
+func TestResizeQueueUnblocksSubmitters(t *testing.T) {
+
+	pool := pond.New(1, 0)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	submitted := make(chan struct{})
+	go func() {
+		pool.Submit(func() {})
+		close(submitted)
+	}()
+
+	// Wait for the submitter to block
+	for pool.WaitingTasks() == 0 {
+		time.Sleep(1 * time.Millisecond)
+	}
+	pool.ResizeQueue(1)
+	<-submitted
+	assertEqual(t, 1, pool.QueueLen())
+
+	close(release)
+	pool.StopAndWait()
+}
+
+func TestSubmitLabeledWithPanic(t *testing.T) {
+
+	var capturedLabels []string
+	pool := pond.New(1, 5, pond.TaskPanicHandler(func(panic interface{}, info pond.TaskInfo) {
+		capturedLabels = append(capturedLabels, info.Label)
+	}))
+
+	pool.SubmitLabeled("sync", func() {
+		panic("panic now!")
+	})
+
+	group := pool.Group()
+	group.SetLabel("reports")
+	group.Submit(func() {
+		panic("panic now!")
+	})
+	group.Wait()
+
+	pool.StopAndWait()
+
+	assertEqual(t, 2, len(capturedLabels))
+	assertEqual(t, "sync", capturedLabels[0])
+	assertEqual(t, "reports", capturedLabels[1])
+}
+
+func TestSubmitContextWithTaskInfo(t *testing.T) {
+
+	pool := pond.New(2, 10)
+
+	var mutex sync.Mutex
+	var infos []pond.TaskInfo
+	before := time.Now()
+	for i := 0; i < 3; i++ {
+		pool.SubmitContext(func(ctx context.Context) {
+			info, ok := pond.FromContext(ctx)
+			if !ok {
+				t.Error("Task info not found in context")
+			}
+			mutex.Lock()
+			infos = append(infos, info)
+			mutex.Unlock()
+		})
+	}
+	pool.SubmitContext(nil)
+
+	pool.StopAndWait()
+
+	assertEqual(t, 3, len(infos))
+	ids := make(map[uint64]bool)
+	for _, info := range infos {
+		ids[info.ID] = true
+		assertEqual(t, 1, info.Attempt)
+		assertEqual(t, false, info.SubmittedAt.Before(before))
+	}
+	assertEqual(t, 3, len(ids))
+
+	_, ok := pond.FromContext(context.Background())
+	assertEqual(t, false, ok)
+}
+
+func TestWorkerProfilerLabels(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.Name("images"))
+
+	assertEqual(t, "images", pool.Name())
+
+	var profile bytes.Buffer
+	pool.SubmitLabeled("resize", func() {
+		pprof.Lookup("goroutine").WriteTo(&profile, 1)
+	})
+
+	pool.StopAndWait()
+
+	output := profile.String()
+	assertEqual(t, true, strings.Contains(output, `"pond.pool":"images"`))
+	assertEqual(t, true, strings.Contains(output, `"pond.task":"resize"`))
+	assertEqual(t, true, strings.Contains(output, `"pond.worker":"0"`))
+}
+
+func TestNewWithOptions(t *testing.T) {
+
+	pool, err := pond.NewWithOptions(5, 10, pond.MinWorkers(2), pond.Name("valid"))
+
+	assertEqual(t, nil, err)
+	assertEqual(t, 2, pool.RunningWorkers())
+
+	pool.StopAndWait()
+}
+
+func TestNewWithOptionsInvalidConfig(t *testing.T) {
+
+	tests := []struct {
+		maxWorkers  int
+		maxCapacity int
+		options     []pond.Option
+		err         string
+	}{
+		{0, 10, nil, "maxWorkers must be greater than 0, got 0"},
+		{1, -1, nil, "maxCapacity must not be negative, got -1"},
+		{2, 10, []pond.Option{pond.MinWorkers(3)}, "minWorkers (3) must not be greater than maxWorkers (2)"},
+		{1, 10, []pond.Option{pond.IdleTimeout(0)}, "idle timeout must be greater than 0, got 0s"},
+		{1, 10, []pond.Option{pond.IdleJitter(1.5)}, "idle jitter must be between 0 and 1, got 1.5"},
+		{1, 10, []pond.Option{pond.KeepWarm(-1)}, "keepWarm must not be negative, got -1"},
+		{1, 10, []pond.Option{pond.BaseContext(nil)}, "base context must not be nil"},
+		{1, 10, []pond.Option{pond.Strategy(nil)}, "resizing strategy must not be nil"},
+		{1, 10, []pond.Option{pond.PanicHandler(nil)}, "panic handler must not be nil"},
+		{1, 0, []pond.Option{pond.QueueOrder(pond.LIFO)}, "queue order LIFO has no effect when maxCapacity is 0, since tasks are never queued"},
+		{1, 10, []pond.Option{pond.Backpressure(0.5, 0.8, nil)}, "backpressure watermarks must satisfy 0 <= low <= high <= 1 and high > 0, got low 0.8 and high 0.5"},
+		{1, 10, []pond.Option{pond.HealthCheck(pond.HealthCriteria{MaxRejectionRate: 2})}, "max rejection rate must be between 0 and 1, got 2"},
+	}
+
+	for _, test := range tests {
+		pool, err := pond.NewWithOptions(test.maxWorkers, test.maxCapacity, test.options...)
+
+		assertEqual(t, true, pool == nil)
+		assertEqual(t, true, errors.Is(err, pond.ErrInvalidConfig))
+		if err != nil {
+			assertEqual(t, "invalid worker pool configuration: "+test.err, err.Error())
+		}
+	}
+}
+
+func TestNewNormalizesInvalidConfig(t *testing.T) {
+
+	pool := pond.New(0, -1, pond.IdleTimeout(0), pond.Strategy(nil), pond.PanicHandler(nil))
+
+	assertEqual(t, 1, pool.MaxWorkers())
+	assertEqual(t, 0, pool.MaxCapacity())
+
+	pool.SubmitAndWait(func() {
+		panic("recovered by the default panic handler")
+	})
+
+	assertEqual(t, uint64(1), pool.FailedTasks())
+
+	pool.StopAndWait()
+}
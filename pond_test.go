@@ -73,3 +73,17 @@ func TestPurgeDuringSubmit(t *testing.T) {
 	assertEqual(t, 0, pool.RunningWorkers())
 
 }
+
+func TestDeterministicIdleJitter(t *testing.T) {
+
+	first := New(1, 1, Deterministic(42, nil), IdleJitter(0.5))
+	second := New(1, 1, Deterministic(42, nil), IdleJitter(0.5))
+
+	// Purger goroutines draw from the same source, so they must be stopped before drawing here
+	first.StopAndWait()
+	second.StopAndWait()
+
+	for i := 0; i < 10; i++ {
+		assertEqual(t, first.nextIdleTimeout(), second.nextIdleTimeout())
+	}
+}
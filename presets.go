@@ -0,0 +1,32 @@
+package pond
+
+import (
+	"runtime"
+	"time"
+)
+
+// NewCPUBound creates a worker pool tuned for CPU-bound tasks (e.g. hashing, encoding or image processing): it has
+// as many workers as GOMAXPROCS, which are kept running since more of them would only add scheduling overhead,
+// a small queue (twice the number of workers) so that producers feel backpressure early, and LIFO order to keep
+// latency low under overload. The given options are applied on top of these defaults.
+func NewCPUBound(options ...Option) *WorkerPool {
+	workers := runtime.GOMAXPROCS(0)
+
+	defaults := []Option{
+		MinWorkers(workers),
+		QueueOrder(LIFO),
+	}
+	return New(workers, 2*workers, append(defaults, options...)...)
+}
+
+// NewIOBound creates a worker pool tuned for IO-bound tasks (e.g. network calls or disk access), which spend most
+// of their time waiting: it can scale up to the given (typically large) number of workers, which are started lazily
+// and retired after a minute of inactivity, and a generous queue (ten times the maximum number of workers) to absorb
+// bursts. The given options are applied on top of these defaults.
+func NewIOBound(maxWorkers int, options ...Option) *WorkerPool {
+	defaults := []Option{
+		Strategy(Balanced()),
+		IdleTimeout(time.Minute),
+	}
+	return New(maxWorkers, 10*maxWorkers, append(defaults, options...)...)
+}
@@ -0,0 +1,37 @@
+package pond_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestNewCPUBound(t *testing.T) {
+
+	pool := pond.NewCPUBound()
+	defer pool.StopAndWait()
+
+	workers := runtime.GOMAXPROCS(0)
+	config := pool.Options()
+
+	assertEqual(t, workers, config.MaxWorkers)
+	assertEqual(t, workers, config.MinWorkers)
+	assertEqual(t, 2*workers, config.MaxCapacity)
+	assertEqual(t, pond.LIFO, config.QueueOrder)
+}
+
+func TestNewIOBound(t *testing.T) {
+
+	pool := pond.NewIOBound(200, pond.IdleTimeout(5*time.Second))
+	defer pool.StopAndWait()
+
+	config := pool.Options()
+
+	assertEqual(t, 200, config.MaxWorkers)
+	assertEqual(t, 0, config.MinWorkers)
+	assertEqual(t, 2000, config.MaxCapacity)
+	assertEqual(t, pond.FIFO, config.QueueOrder)
+	assertEqual(t, 5*time.Second, config.IdleTimeout)
+}
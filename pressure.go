@@ -0,0 +1,126 @@
+package pond
+
+import (
+	"sync"
+)
+
+// PressureEvent describes a change in the queue utilization of a worker pool
+type PressureEvent struct {
+	// High is true when utilization crossed the high watermark and false when it dropped back below the low watermark
+	High bool
+	// Utilization is the fraction of the queue capacity in use (between 0 and 1) when the event was emitted
+	Utilization float64
+	// WaitingTasks is the number of tasks waiting to be executed when the event was emitted
+	WaitingTasks uint64
+}
+
+// Backpressure configures a handler that is invoked when the queue utilization of a worker pool reaches the high
+// watermark, and again when it drops to the low watermark, so producers can slow down before Submit starts blocking.
+// Watermarks are fractions of the queue capacity between 0 and 1. The handler is invoked synchronously by the goroutine
+// that caused the transition (a submitter or a worker), so it should return quickly. It may submit tasks to the pool
+// itself (e.g. to shed or requeue load): events caused meanwhile are delivered in order once it returns.
+func Backpressure(high, low float64, handler func(PressureEvent)) Option {
+	return func(pool *WorkerPool) {
+		pool.pressure = &pressureMonitor{
+			high:    high,
+			low:     low,
+			handler: handler,
+		}
+	}
+}
+
+// pressureMonitor tracks queue utilization and emits events when it crosses the watermarks
+type pressureMonitor struct {
+	high    float64
+	low     float64
+	handler func(PressureEvent)
+	active  bool
+	// Events waiting to be delivered, and whether a goroutine is delivering them
+	pending    []PressureEvent
+	delivering bool
+	mutex      sync.Mutex
+}
+
+// normalize makes sure the watermarks are consistent
+func (m *pressureMonitor) normalize() {
+	if m.high <= 0 || m.high > 1 {
+		m.high = 1
+	}
+	if m.low < 0 {
+		m.low = 0
+	}
+	if m.low > m.high {
+		m.low = m.high
+	}
+}
+
+// update emits an event if the given utilization crossed one of the watermarks. The handler is invoked without
+// holding the mutex, since it may cause another transition, e.g. by submitting a task. If another goroutine is already
+// delivering events, the event is left to it, so that events are delivered one at a time and in order.
+func (m *pressureMonitor) update(utilization float64, waitingTasks uint64) {
+	if m == nil || m.handler == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	switch {
+	case !m.active && utilization >= m.high:
+		m.active = true
+	case m.active && utilization <= m.low:
+		m.active = false
+	default:
+		return
+	}
+
+	m.pending = append(m.pending, PressureEvent{
+		High:         m.active,
+		Utilization:  utilization,
+		WaitingTasks: waitingTasks,
+	})
+	if m.delivering {
+		return
+	}
+
+	m.delivering = true
+	for len(m.pending) > 0 {
+		event := m.pending[0]
+		m.pending = m.pending[1:]
+
+		m.mutex.Unlock()
+		m.handler(event)
+		m.mutex.Lock()
+	}
+	m.delivering = false
+}
+
+// updatePressure reports the current queue utilization to the pressure and health monitors (if any)
+func (p *WorkerPool) updatePressure() {
+	if p.pressure == nil && p.health == nil {
+		return
+	}
+
+	utilization, waitingTasks := p.queueUtilization()
+
+	p.pressure.update(utilization, waitingTasks)
+	p.health.updateSaturation(utilization >= 1, p.now())
+}
+
+// queueUtilization returns the fraction of the queue capacity in use (between 0 and 1) and the number of waiting tasks
+func (p *WorkerPool) queueUtilization() (float64, uint64) {
+	waitingTasks := p.WaitingTasks()
+
+	var utilization float64
+	if capacity := p.tasks.Cap(); capacity > 0 {
+		utilization = float64(waitingTasks) / float64(capacity)
+	} else if waitingTasks > 0 {
+		// Without a queue, any waiting task means submitters are blocked
+		utilization = 1
+	}
+	if utilization > 1 {
+		utilization = 1
+	}
+
+	return utilization, waitingTasks
+}
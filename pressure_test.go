@@ -0,0 +1,87 @@
+package pond_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestBackpressure(t *testing.T) {
+
+	var mutex sync.Mutex
+	var events []pond.PressureEvent
+	pool := pond.New(1, 4, pond.Backpressure(0.75, 0.25, func(event pond.PressureEvent) {
+		mutex.Lock()
+		events = append(events, event)
+		mutex.Unlock()
+	}))
+
+	// Block the only worker while the queue fills up
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	for i := 0; i < 4; i++ {
+		pool.Submit(func() {})
+	}
+
+	mutex.Lock()
+	assertEqual(t, 1, len(events))
+	assertEqual(t, true, events[0].High)
+	assertEqual(t, 0.75, events[0].Utilization)
+	assertEqual(t, uint64(3), events[0].WaitingTasks)
+	mutex.Unlock()
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, 2, len(events))
+	assertEqual(t, false, events[1].High)
+	assertEqual(t, 0.25, events[1].Utilization)
+}
+
+func TestBackpressureHandlerSubmittingTasks(t *testing.T) {
+
+	var pool *pond.WorkerPool
+	var mutex sync.Mutex
+	var events []bool
+	pool = pond.New(1, 4, pond.Backpressure(0.5, 0, func(event pond.PressureEvent) {
+		mutex.Lock()
+		events = append(events, event.High)
+		mutex.Unlock()
+
+		if event.High {
+			// Requeue some work, which updates the pressure again
+			pool.TrySubmit(func() {})
+		}
+	}))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	assertReturns(t, func() {
+		for i := 0; i < 2; i++ {
+			pool.Submit(func() {})
+		}
+	})
+	assertEqual(t, uint64(3), pool.WaitingTasks())
+
+	close(release)
+	pool.StopAndWait()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assertEqual(t, 2, len(events))
+	assertEqual(t, true, events[0])
+	assertEqual(t, false, events[1])
+}
@@ -0,0 +1,689 @@
+package pond
+
+import (
+	"container/heap"
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Order represents the order in which queued tasks are dispatched to workers
+type Order int
+
+const (
+	// FIFO dispatches tasks in the order they were submitted. It's the default order.
+	FIFO Order = iota
+	// LIFO dispatches the most recently submitted tasks first, which reduces tail latency under overload
+	// for cache-warm workloads and request-scoped work that loses its value as it ages
+	LIFO
+	// EarliestDeadlineFirst dispatches the task with the earliest deadline first (see SubmitWithDeadline).
+	// Tasks without a deadline are dispatched after all tasks with one, in FIFO order.
+	EarliestDeadlineFirst
+)
+
+// String returns the name of the order
+func (o Order) String() string {
+	switch o {
+	case FIFO:
+		return "FIFO"
+	case LIFO:
+		return "LIFO"
+	case EarliestDeadlineFirst:
+		return "EarliestDeadlineFirst"
+	}
+	return fmt.Sprintf("Order(%d)", int(o))
+}
+
+// QueueOrder allows to change the order in which queued tasks are dispatched to workers
+func QueueOrder(order Order) Option {
+	return func(pool *WorkerPool) {
+		pool.queueOrder = order
+	}
+}
+
+// taskBuffer holds queued tasks and determines the order in which they are dequeued
+type taskBuffer interface {
+	Push(task *queuedTask)
+	// PushFront adds a task that must be dequeued before the ones already buffered (see SubmitFront)
+	PushFront(task *queuedTask)
+	Pop() *queuedTask
+	Len() int
+	// Each calls visit for each buffered task, in no particular order, until it returns false.
+	// It returns false if it was stopped this way.
+	Each(visit func(task *queuedTask) bool) bool
+	// Remove removes the buffered tasks that match, keeping the others in order, and returns them
+	Remove(match func(task *queuedTask) bool) []*queuedTask
+}
+
+// removeTasks removes the tasks that match from the given slice in place, returning the remaining and removed tasks.
+// The slots freed at the end of the slice are cleared.
+func removeTasks(tasks []*queuedTask, match func(task *queuedTask) bool) (remaining, removed []*queuedTask) {
+	remaining = tasks[:0]
+	for _, task := range tasks {
+		if match(task) {
+			removed = append(removed, task)
+		} else {
+			remaining = append(remaining, task)
+		}
+	}
+	for i := len(remaining); i < len(tasks); i++ {
+		tasks[i] = nil
+	}
+	return remaining, removed
+}
+
+// gatedBuffer is implemented by task buffers that limit how many of their tasks of some kind can run at the same
+// time (see laneBuffer). Tasks must be admitted before running and released once they complete.
+type gatedBuffer interface {
+	taskBuffer
+	// Ready returns true if one of the buffered tasks can be dequeued, admitting it
+	Ready() bool
+	// Admit returns true if the given task can run, reserving a slot for it
+	Admit(task *queuedTask) bool
+	// Release frees the slot of an admitted task that completed
+	Release(task *queuedTask)
+}
+
+// newTaskBuffer creates a task buffer that dequeues tasks in the given order
+func newTaskBuffer(order Order) taskBuffer {
+	switch order {
+	case LIFO:
+		return &lifoBuffer{}
+	case EarliestDeadlineFirst:
+		return &deadlineBuffer{}
+	default:
+		return &fifoBuffer{}
+	}
+}
+
+// fifoBuffer is a task buffer that dequeues the oldest task first
+type fifoBuffer struct {
+	tasks []*queuedTask
+	head  int
+}
+
+func (b *fifoBuffer) Push(task *queuedTask) {
+	b.tasks = append(b.tasks, task)
+}
+
+func (b *fifoBuffer) PushFront(task *queuedTask) {
+	if b.head > 0 {
+		b.head--
+		b.tasks[b.head] = task
+		return
+	}
+	b.tasks = append(b.tasks, nil)
+	copy(b.tasks[1:], b.tasks)
+	b.tasks[0] = task
+}
+
+func (b *fifoBuffer) Pop() *queuedTask {
+	task := b.tasks[b.head]
+	b.tasks[b.head] = nil
+	b.head++
+
+	// Reclaim the space of dequeued tasks once they represent half of the slice
+	if b.head*2 >= len(b.tasks) {
+		b.tasks = append(b.tasks[:0], b.tasks[b.head:]...)
+		b.head = 0
+	}
+
+	return task
+}
+
+func (b *fifoBuffer) Len() int {
+	return len(b.tasks) - b.head
+}
+
+func (b *fifoBuffer) Remove(match func(task *queuedTask) bool) []*queuedTask {
+	remaining, removed := removeTasks(b.tasks[b.head:], match)
+	b.tasks = append(b.tasks[:0], remaining...)
+	b.head = 0
+	return removed
+}
+
+func (b *fifoBuffer) Each(visit func(task *queuedTask) bool) bool {
+	for _, task := range b.tasks[b.head:] {
+		if !visit(task) {
+			return false
+		}
+	}
+	return true
+}
+
+// lifoBuffer is a task buffer that dequeues the newest task first
+type lifoBuffer struct {
+	tasks []*queuedTask
+}
+
+func (b *lifoBuffer) Push(task *queuedTask) {
+	b.tasks = append(b.tasks, task)
+}
+
+func (b *lifoBuffer) PushFront(task *queuedTask) {
+	// The newest task is dequeued first anyway
+	b.tasks = append(b.tasks, task)
+}
+
+func (b *lifoBuffer) Pop() *queuedTask {
+	last := len(b.tasks) - 1
+	task := b.tasks[last]
+	b.tasks[last] = nil
+	b.tasks = b.tasks[:last]
+
+	return task
+}
+
+func (b *lifoBuffer) Len() int {
+	return len(b.tasks)
+}
+
+func (b *lifoBuffer) Remove(match func(task *queuedTask) bool) []*queuedTask {
+	var removed []*queuedTask
+	b.tasks, removed = removeTasks(b.tasks, match)
+	return removed
+}
+
+func (b *lifoBuffer) Each(visit func(task *queuedTask) bool) bool {
+	for _, task := range b.tasks {
+		if !visit(task) {
+			return false
+		}
+	}
+	return true
+}
+
+// producer represents a caller blocked until there is room in the queue for its task
+type producer struct {
+	task     *queuedTask
+	accepted chan bool
+}
+
+// taskQueue is a bounded queue of tasks. Tasks are handed directly to waiting workers when possible,
+// and buffered up to the queue capacity otherwise.
+type taskQueue struct {
+	buffer   taskBuffer
+	capacity int
+	// Workers waiting for a task, each one with a channel to receive it (nil means exit)
+	consumers list.List
+	// Callers waiting for room in the queue
+	producers list.List
+	closed    bool
+	mutex     sync.Mutex
+}
+
+// newTaskQueue creates a task queue that can buffer up to capacity tasks and dequeues them in the given order
+func newTaskQueue(capacity int, order Order) *taskQueue {
+	return &taskQueue{
+		buffer:   newTaskBuffer(order),
+		capacity: capacity,
+	}
+}
+
+// Push adds a task to the queue. If the queue is full and mustPush is true, it waits until there is room for it.
+// It returns true if the task was queued and false if the queue is full (and mustPush is false) or closed.
+func (q *taskQueue) Push(task *queuedTask, mustPush bool) bool {
+
+	q.mutex.Lock()
+
+	if q.closed {
+		q.mutex.Unlock()
+		return false
+	}
+
+	// Hand the task directly to a waiting worker
+	if elem := q.consumers.Front(); elem != nil && q.admit(task) {
+		q.consumers.Remove(elem)
+		elem.Value.(chan *queuedTask) <- task
+		q.mutex.Unlock()
+		return true
+	}
+
+	if q.buffer.Len() < q.capacity {
+		q.push(task)
+		q.mutex.Unlock()
+		return true
+	}
+
+	if !mustPush {
+		q.mutex.Unlock()
+		return false
+	}
+
+	// Wait until a worker makes room for this task or the queue is closed, ahead of other producers if it goes first
+	accepted := make(chan bool, 1)
+	waiting := &producer{
+		task:     task,
+		accepted: accepted,
+	}
+	if task.front {
+		q.producers.PushFront(waiting)
+	} else {
+		q.producers.PushBack(waiting)
+	}
+	q.mutex.Unlock()
+
+	return <-accepted
+}
+
+// PushAll adds the given tasks to the queue, handing as many as possible to waiting workers and buffering the
+// others under a single acquisition of the lock. If the queue gets full, it waits until there is room for the
+// remaining tasks one at a time. It returns the number of tasks that were queued before the queue was closed.
+func (q *taskQueue) PushAll(tasks []*queuedTask) int {
+
+	q.mutex.Lock()
+
+	if q.closed {
+		q.mutex.Unlock()
+		return 0
+	}
+
+	pushed := 0
+	for _, task := range tasks {
+		if elem := q.consumers.Front(); elem != nil && q.admit(task) {
+			// Hand the task directly to a waiting worker
+			q.consumers.Remove(elem)
+			elem.Value.(chan *queuedTask) <- task
+		} else if q.buffer.Len() < q.capacity {
+			q.push(task)
+		} else {
+			break
+		}
+		pushed++
+	}
+
+	q.mutex.Unlock()
+
+	for _, task := range tasks[pushed:] {
+		if !q.Push(task, true) {
+			break
+		}
+		pushed++
+	}
+	return pushed
+}
+
+// Pop removes a task from the queue, waiting until one is available. It returns false if the worker calling it
+// must exit, either because ctx is done, the queue was closed or the worker was asked to stop (see StopOne).
+// While waiting, the task is handed over through the given consumer channel, which must have a capacity of 1 and
+// be empty. Workers reuse their channel across calls, since it's always left empty when Pop returns.
+func (q *taskQueue) Pop(ctx context.Context, consumer chan *queuedTask) (*queuedTask, bool) {
+
+	if ctx.Err() != nil {
+		return nil, false
+	}
+
+	q.mutex.Lock()
+
+	if task, ok := q.dequeue(); ok {
+		q.mutex.Unlock()
+		return task, true
+	}
+
+	if q.closed {
+		q.mutex.Unlock()
+		return nil, false
+	}
+
+	// Wait for a task to be handed over
+	elem := q.consumers.PushBack(consumer)
+	q.mutex.Unlock()
+
+	select {
+	case task := <-consumer:
+		return task, task != nil
+	case <-ctx.Done():
+		q.mutex.Lock()
+		defer q.mutex.Unlock()
+
+		select {
+		case task := <-consumer:
+			// A task was handed over right before ctx was done, execute it anyway
+			return task, task != nil
+		default:
+			q.consumers.Remove(elem)
+			return nil, false
+		}
+	}
+}
+
+// StopOne asks a worker waiting for a task to exit. It returns false if no worker is waiting, since workers
+// between two tasks may still find tasks in the queue.
+func (q *taskQueue) StopOne() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	elem := q.consumers.Front()
+	if elem == nil {
+		return false
+	}
+	q.consumers.Remove(elem)
+	elem.Value.(chan *queuedTask) <- nil
+	return true
+}
+
+// Each calls visit for each task waiting in the queue, including the ones of producers waiting for room in it,
+// in no particular order, until it returns false. The queue is locked meanwhile, so visit must return quickly.
+func (q *taskQueue) Each(visit func(task *queuedTask) bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !q.buffer.Each(visit) {
+		return
+	}
+	for elem := q.producers.Front(); elem != nil; elem = elem.Next() {
+		if !visit(elem.Value.(*producer).task) {
+			return
+		}
+	}
+}
+
+// Remove removes the buffered tasks that match and returns them. Producers waiting for room in the queue are let in
+// the room freed this way, but their tasks are not matched.
+func (q *taskQueue) Remove(match func(task *queuedTask) bool) []*queuedTask {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	removed := q.buffer.Remove(match)
+
+	for q.buffer.Len() < q.capacity {
+		elem := q.producers.Front()
+		if elem == nil {
+			break
+		}
+		q.producers.Remove(elem)
+		p := elem.Value.(*producer)
+		q.push(p.task)
+		p.accepted <- true
+	}
+
+	return removed
+}
+
+// Len returns the number of tasks buffered in the queue
+func (q *taskQueue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.buffer.Len()
+}
+
+// Cap returns the maximum number of tasks that can be buffered in the queue
+func (q *taskQueue) Cap() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.capacity
+}
+
+// Resize changes the maximum number of tasks that can be buffered in the queue. When growing, waiting producers
+// are let in. When shrinking, tasks already buffered are kept and new ones wait until there is room for them.
+func (q *taskQueue) Resize(capacity int) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.capacity = capacity
+
+	for q.buffer.Len() < q.capacity {
+		elem := q.producers.Front()
+		if elem == nil {
+			return
+		}
+		q.producers.Remove(elem)
+		p := elem.Value.(*producer)
+		q.push(p.task)
+		p.accepted <- true
+	}
+}
+
+// Admit reserves a slot for a task that is about to run without going through the queue, returning false if
+// it can't run yet. Buffers that don't limit running tasks admit all of them.
+func (q *taskQueue) Admit(task *queuedTask) bool {
+	gated, ok := q.buffer.(gatedBuffer)
+	if !ok {
+		return true
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return gated.Admit(task)
+}
+
+// Release frees the slot of an admitted task that completed, handing a task that was waiting for it to a waiting
+// worker if there is one
+func (q *taskQueue) Release(task *queuedTask) {
+	gated, ok := q.buffer.(gatedBuffer)
+	if !ok {
+		return
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	gated.Release(task)
+
+	for {
+		elem := q.consumers.Front()
+		if elem == nil {
+			return
+		}
+		next, ok := q.dequeue()
+		if !ok {
+			return
+		}
+		q.consumers.Remove(elem)
+		elem.Value.(chan *queuedTask) <- next
+	}
+}
+
+// PopLane removes a buffered task of the given lane without waiting, returning false if there is none
+// or the queue has no lanes (see Lanes)
+func (q *taskQueue) PopLane(lane Lane) (*queuedTask, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	lanes, ok := q.buffer.(laneTaskBuffer)
+	if !ok {
+		return nil, false
+	}
+
+	task := lanes.PopLane(lane)
+	if task == nil {
+		return nil, false
+	}
+
+	// Move the task of the first waiting producer into the buffer
+	if elem := q.producers.Front(); elem != nil {
+		q.producers.Remove(elem)
+		p := elem.Value.(*producer)
+		q.push(p.task)
+		p.accepted <- true
+	}
+
+	return task, true
+}
+
+// push adds a task to the buffer, at its front if it was submitted with SubmitFront. Must be called with the mutex held.
+func (q *taskQueue) push(task *queuedTask) {
+	if task.front {
+		q.buffer.PushFront(task)
+	} else {
+		q.buffer.Push(task)
+	}
+}
+
+// admit reserves a slot for the given task if the buffer limits running tasks. Must be called with the mutex held.
+func (q *taskQueue) admit(task *queuedTask) bool {
+	if gated, ok := q.buffer.(gatedBuffer); ok {
+		return gated.Admit(task)
+	}
+	return true
+}
+
+// ready returns true if a buffered task can be dequeued. Must be called with the mutex held.
+func (q *taskQueue) ready() bool {
+	if gated, ok := q.buffer.(gatedBuffer); ok {
+		return gated.Ready()
+	}
+	return q.buffer.Len() > 0
+}
+
+// Close closes the queue, causing all waiting workers to exit and all waiting producers to fail.
+// It returns the tasks that were still buffered, which will never be handed over to a worker.
+func (q *taskQueue) Close() []*queuedTask {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+
+	var discarded []*queuedTask
+	for q.buffer.Len() > 0 {
+		discarded = append(discarded, q.buffer.Pop())
+	}
+
+	for elem := q.consumers.Front(); elem != nil; elem = elem.Next() {
+		elem.Value.(chan *queuedTask) <- nil
+	}
+	q.consumers.Init()
+
+	for elem := q.producers.Front(); elem != nil; elem = elem.Next() {
+		elem.Value.(*producer).accepted <- false
+	}
+	q.producers.Init()
+
+	return discarded
+}
+
+// dequeue takes the next task from the buffer (or from a waiting producer when the buffer is empty)
+// and lets the first waiting producer in. Must be called with the mutex held.
+func (q *taskQueue) dequeue() (*queuedTask, bool) {
+
+	if q.ready() {
+		task := q.buffer.Pop()
+
+		// Move the task of the first waiting producer into the buffer
+		if elem := q.producers.Front(); elem != nil {
+			q.producers.Remove(elem)
+			p := elem.Value.(*producer)
+			q.push(p.task)
+			p.accepted <- true
+		}
+
+		return task, true
+	}
+
+	// Buffer is empty (or its tasks can't run yet) but producers may be waiting (e.g. the queue has no capacity)
+	if elem := q.producers.Front(); elem != nil && q.admit(elem.Value.(*producer).task) {
+		q.producers.Remove(elem)
+		p := elem.Value.(*producer)
+		p.accepted <- true
+		return p.task, true
+	}
+
+	return nil, false
+}
+
+// deadlineBuffer is a task buffer that dequeues the task with the earliest deadline first
+type deadlineBuffer struct {
+	entries []deadlineEntry
+	seq     uint64
+}
+
+// deadlineEntry is an item of a deadline buffer. The sequence number keeps tasks with equal deadlines in FIFO order.
+// Tasks pushed to the front go before all others, the last one first.
+type deadlineEntry struct {
+	task  *queuedTask
+	seq   uint64
+	front bool
+}
+
+func (b *deadlineBuffer) Push(task *queuedTask) {
+	b.seq++
+	heap.Push((*deadlineHeap)(b), deadlineEntry{task: task, seq: b.seq})
+}
+
+func (b *deadlineBuffer) PushFront(task *queuedTask) {
+	b.seq++
+	heap.Push((*deadlineHeap)(b), deadlineEntry{task: task, seq: b.seq, front: true})
+}
+
+func (b *deadlineBuffer) Pop() *queuedTask {
+	return heap.Pop((*deadlineHeap)(b)).(deadlineEntry).task
+}
+
+func (b *deadlineBuffer) Len() int {
+	return len(b.entries)
+}
+
+func (b *deadlineBuffer) Remove(match func(task *queuedTask) bool) []*queuedTask {
+	var removed []*queuedTask
+	remaining := b.entries[:0]
+	for _, entry := range b.entries {
+		if match(entry.task) {
+			removed = append(removed, entry.task)
+		} else {
+			remaining = append(remaining, entry)
+		}
+	}
+	for i := len(remaining); i < len(b.entries); i++ {
+		b.entries[i] = deadlineEntry{}
+	}
+	b.entries = remaining
+	heap.Init((*deadlineHeap)(b))
+	return removed
+}
+
+func (b *deadlineBuffer) Each(visit func(task *queuedTask) bool) bool {
+	for _, entry := range b.entries {
+		if !visit(entry.task) {
+			return false
+		}
+	}
+	return true
+}
+
+// deadlineHeap implements heap.Interface on top of a deadline buffer
+type deadlineHeap deadlineBuffer
+
+func (h *deadlineHeap) Len() int {
+	return len(h.entries)
+}
+
+func (h *deadlineHeap) Less(i, j int) bool {
+	a, b := h.entries[i], h.entries[j]
+	aDeadline, bDeadline := a.task.info.Deadline, b.task.info.Deadline
+	switch {
+	case a.front != b.front:
+		return a.front
+	case a.front:
+		return a.seq > b.seq
+	case aDeadline.IsZero() != bDeadline.IsZero():
+		// Tasks with a deadline go first
+		return bDeadline.IsZero()
+	case !aDeadline.Equal(bDeadline):
+		return aDeadline.Before(bDeadline)
+	default:
+		return a.seq < b.seq
+	}
+}
+
+func (h *deadlineHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+}
+
+func (h *deadlineHeap) Push(x interface{}) {
+	h.entries = append(h.entries, x.(deadlineEntry))
+}
+
+func (h *deadlineHeap) Pop() interface{} {
+	last := len(h.entries) - 1
+	entry := h.entries[last]
+	h.entries[last] = deadlineEntry{}
+	h.entries = h.entries[:last]
+	return entry
+}
@@ -0,0 +1,213 @@
+package pond
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFIFOBuffer(t *testing.T) {
+
+	buffer := newTaskBuffer(FIFO)
+	var order []int
+	for i := 0; i < 5; i++ {
+		n := i
+		buffer.Push(newQueuedTask(func() { order = append(order, n) }))
+	}
+	assertEqual(t, 5, buffer.Len())
+
+	for buffer.Len() > 0 {
+		buffer.Pop().run()
+	}
+
+	assertEqual(t, 5, len(order))
+	for i, n := range order {
+		assertEqual(t, i, n)
+	}
+}
+
+func TestLIFOBuffer(t *testing.T) {
+
+	buffer := newTaskBuffer(LIFO)
+	var order []int
+	for i := 0; i < 5; i++ {
+		n := i
+		buffer.Push(newQueuedTask(func() { order = append(order, n) }))
+	}
+	assertEqual(t, 5, buffer.Len())
+
+	for buffer.Len() > 0 {
+		buffer.Pop().run()
+	}
+
+	assertEqual(t, 5, len(order))
+	for i, n := range order {
+		assertEqual(t, 4-i, n)
+	}
+}
+
+func TestTaskQueueHandsOverToWaitingWorker(t *testing.T) {
+
+	queue := newTaskQueue(0, FIFO)
+
+	// Nothing can be queued without a waiting worker
+	assertEqual(t, false, queue.Push(newQueuedTask(func() {}), false))
+
+	popped := make(chan bool)
+	go func() {
+		_, ok := queue.Pop(context.Background(), make(chan *queuedTask, 1))
+		popped <- ok
+	}()
+
+	// Wait for the worker to start waiting
+	for {
+		queue.mutex.Lock()
+		waiting := queue.consumers.Len()
+		queue.mutex.Unlock()
+		if waiting > 0 {
+			break
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	assertEqual(t, true, queue.Push(newQueuedTask(func() {}), false))
+	assertEqual(t, true, <-popped)
+}
+
+func TestTaskQueueStopOneWaitingWorker(t *testing.T) {
+
+	queue := newTaskQueue(2, FIFO)
+	queue.Push(newQueuedTask(func() {}), true)
+
+	// No worker is waiting, so none is asked to exit and queued tasks are still dequeued
+	assertEqual(t, false, queue.StopOne())
+	_, ok := queue.Pop(context.Background(), make(chan *queuedTask, 1))
+	assertEqual(t, true, ok)
+
+	popped := make(chan bool)
+	go func() {
+		_, ok := queue.Pop(context.Background(), make(chan *queuedTask, 1))
+		popped <- ok
+	}()
+
+	// Wait for the worker to find the queue empty
+	for !queue.StopOne() {
+		time.Sleep(1 * time.Millisecond)
+	}
+	assertEqual(t, false, <-popped)
+}
+
+func TestTaskQueueCloseUnblocksProducers(t *testing.T) {
+
+	queue := newTaskQueue(0, FIFO)
+
+	pushed := make(chan bool)
+	go func() {
+		pushed <- queue.Push(newQueuedTask(func() {}), true)
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	queue.Close()
+	queue.Close()
+
+	assertEqual(t, false, <-pushed)
+	assertEqual(t, false, queue.Push(newQueuedTask(func() {}), true))
+
+	_, ok := queue.Pop(context.Background(), make(chan *queuedTask, 1))
+	assertEqual(t, false, ok)
+}
+
+func TestTaskQueueEach(t *testing.T) {
+
+	queue := newTaskQueue(2, FIFO)
+	queue.Push(newQueuedTask(func() {}), true)
+	queue.Push(newQueuedTask(func() {}), true)
+
+	// The third task waits for room in the queue
+	pushed := make(chan bool)
+	go func() {
+		pushed <- queue.Push(newQueuedTask(func() {}), true)
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	visited := 0
+	queue.Each(func(task *queuedTask) bool {
+		visited++
+		return true
+	})
+	assertEqual(t, 3, visited)
+
+	visited = 0
+	queue.Each(func(task *queuedTask) bool {
+		visited++
+		return false
+	})
+	assertEqual(t, 1, visited)
+
+	queue.Close()
+	assertEqual(t, false, <-pushed)
+}
+
+func TestTaskBufferRemove(t *testing.T) {
+
+	buffers := []taskBuffer{
+		newTaskBuffer(FIFO),
+		newTaskBuffer(LIFO),
+		newTaskBuffer(EarliestDeadlineFirst),
+		newLaneBuffer(func() taskBuffer { return newTaskBuffer(FIFO) }, 0, 0),
+		newWeightedBuffer(FIFO, map[string]int{}),
+	}
+
+	for _, buffer := range buffers {
+		for i := 0; i < 6; i++ {
+			task := newQueuedTask(func() {})
+			task.info.Attempt = i
+			task.info.Label = []string{"a", "b"}[i%2]
+			task.info.Lane = Lane(i % 2)
+			buffer.Push(task)
+		}
+
+		removed := buffer.Remove(func(task *queuedTask) bool {
+			return task.info.Attempt%3 == 0
+		})
+		assertEqual(t, 2, len(removed))
+		assertEqual(t, 4, buffer.Len())
+
+		remaining := 0
+		for buffer.Len() > 0 {
+			assertEqual(t, false, buffer.Pop().info.Attempt%3 == 0)
+			remaining++
+		}
+		assertEqual(t, 4, remaining)
+	}
+}
+
+func TestDeadlineBuffer(t *testing.T) {
+
+	buffer := newTaskBuffer(EarliestDeadlineFirst)
+	now := time.Now()
+	var order []int
+	push := func(n int, deadline time.Time) {
+		task := newQueuedTask(func() { order = append(order, n) })
+		task.info.Deadline = deadline
+		buffer.Push(task)
+	}
+
+	push(0, time.Time{})
+	push(1, now.Add(3*time.Second))
+	push(2, now.Add(1*time.Second))
+	push(3, time.Time{})
+	push(4, now.Add(2*time.Second))
+	push(5, now.Add(1*time.Second))
+	assertEqual(t, 6, buffer.Len())
+
+	for buffer.Len() > 0 {
+		buffer.Pop().run()
+	}
+
+	expected := []int{2, 5, 4, 1, 0, 3}
+	assertEqual(t, len(expected), len(order))
+	for i, n := range order {
+		assertEqual(t, expected[i], n)
+	}
+}
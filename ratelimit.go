@@ -0,0 +1,77 @@
+package pond
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter spaces out events so that they don't happen more often than a given rate
+type rateLimiter struct {
+	interval time.Duration
+	// Time at which the next event can happen
+	next  time.Time
+	mutex sync.Mutex
+}
+
+// newRateLimiter creates a rate limiter allowing the given number of events per second, or nil if rps is not positive
+func newRateLimiter(rps float64) *rateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		interval: time.Duration(float64(time.Second) / rps),
+	}
+}
+
+// reserve reserves the next slot and returns how long to wait until it
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	return delay
+}
+
+// wait blocks until the next slot, or until ctx is done in which case it returns ctx.Err()
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	delay := l.reserve(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GroupWithRate creates a new task group whose tasks are submitted at most rps times per second, independently
+// of other groups sharing the pool, e.g. to be polite to a remote API. Submit blocks until the task's turn comes,
+// so a burst of submissions is spread over time.
+func (p *WorkerPool) GroupWithRate(rps float64) *TaskGroup {
+	group := p.Group()
+	group.limiter = newRateLimiter(rps)
+	return group
+}
+
+// RateLimit makes a task group submit its tasks at most rps times per second (see GroupWithRate).
+// Submit blocks until the task's turn comes or the group's context is canceled, in which case the task is skipped.
+func RateLimit(rps float64) GroupOption {
+	return func(group *TaskGroupWithContext) {
+		group.limiter = newRateLimiter(rps)
+	}
+}
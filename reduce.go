@@ -0,0 +1,70 @@
+package pond
+
+import (
+	"sync"
+)
+
+// Reduce maps the given items concurrently on the worker pool and folds the results with reduceFn, which must be
+// associative. Items are split in contiguous chunks, one per worker, which are mapped and folded in parallel, and
+// the partial results are then folded in order, so reduceFn doesn't need to be commutative.
+// It returns the zero value of R if there are no items. If mapFn or reduceFn panic, Reduce panics with the same value,
+// and if a submit hook vetoes a chunk (see SubmitHooks), it panics with the hook's error.
+func Reduce[T, R any](pool *WorkerPool, items []T, mapFn func(T) R, reduceFn func(R, R) R) R {
+
+	var result R
+	if len(items) == 0 {
+		return result
+	}
+
+	// Split items in one chunk per worker
+	workers := pool.MaxWorkers()
+	if workers > len(items) {
+		workers = len(items)
+	}
+	chunks := splitChunks(items, (len(items)+workers-1)/workers)
+
+	partials := make([]R, len(chunks))
+
+	var waitGroup sync.WaitGroup
+	var panicOnce sync.Once
+	var panicValue interface{}
+
+	waitGroup.Add(len(chunks))
+	for c, chunk := range chunks {
+		c, chunk := c, chunk
+
+		pool.submitOrReject(func() {
+			defer waitGroup.Done()
+			defer func() {
+				if p := recover(); p != nil {
+					panicOnce.Do(func() {
+						panicValue = p
+					})
+				}
+			}()
+
+			partial := mapFn(chunk[0])
+			for _, item := range chunk[1:] {
+				partial = reduceFn(partial, mapFn(item))
+			}
+			partials[c] = partial
+		}, func(err error) {
+			panicOnce.Do(func() {
+				panicValue = err
+			})
+			waitGroup.Done()
+		})
+	}
+
+	waitGroup.Wait()
+
+	if panicValue != nil {
+		panic(panicValue)
+	}
+
+	result = partials[0]
+	for _, partial := range partials[1:] {
+		result = reduceFn(result, partial)
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package pond_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestReduce(t *testing.T) {
+
+	pool := pond.New(4, 100)
+	defer pool.StopAndWait()
+
+	items := make([]int, 1000)
+	for i := range items {
+		items[i] = i + 1
+	}
+
+	sum := pond.Reduce(pool, items, func(n int) int {
+		return n * 2
+	}, func(a, b int) int {
+		return a + b
+	})
+
+	assertEqual(t, 1001000, sum)
+}
+
+func TestReducePreservesOrder(t *testing.T) {
+
+	pool := pond.New(3, 100)
+	defer pool.StopAndWait()
+
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	// Concatenation is associative but not commutative
+	joined := pond.Reduce(pool, items, strconv.Itoa, func(a, b string) string {
+		return a + "," + b
+	})
+
+	assertEqual(t, "1,2,3,4,5,6,7,8,9,10", joined)
+}
+
+func TestReduceWithoutItems(t *testing.T) {
+
+	pool := pond.New(3, 100)
+	defer pool.StopAndWait()
+
+	result := pond.Reduce(pool, nil, strconv.Itoa, func(a, b string) string {
+		return a + b
+	})
+
+	assertEqual(t, "", result)
+}
+
+func TestReducePanic(t *testing.T) {
+
+	pool := pond.New(3, 100)
+	defer pool.StopAndWait()
+
+	var thrownPanic interface{}
+	func() {
+		defer func() {
+			thrownPanic = recover()
+		}()
+		pond.Reduce(pool, []int{1, 2, 3}, func(n int) int {
+			if n == 2 {
+				panic("boom")
+			}
+			return n
+		}, func(a, b int) int {
+			return a + b
+		})
+	}()
+
+	assertEqual(t, "boom", thrownPanic)
+	assertEqual(t, uint64(0), pool.FailedTasks())
+}
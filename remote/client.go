@@ -0,0 +1,78 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Client sends task envelopes to a remote server
+type Client struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewClient creates a client that sends envelopes to the server listening at the given URL.
+// If httpClient is nil, http.DefaultClient is used.
+func NewClient(url string, httpClient *http.Client) *Client {
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		url:        url,
+		httpClient: httpClient,
+	}
+}
+
+// Submit encodes the payload as JSON and sends it to the server to be executed by the task registered
+// under the given name. It returns once the server has queued the task, without waiting for it to complete.
+func (c *Client) Submit(ctx context.Context, name string, payload interface{}) error {
+
+	envelope := Envelope{
+		Name: name,
+	}
+
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("encoding payload of remote task %q: %w", name, err)
+		}
+		envelope.Payload = encoded
+	}
+
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("encoding envelope of remote task %q: %w", name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the underlying connection can be reused
+	io.Copy(io.Discard, resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("submitting remote task %q: %w", name, ErrUnknownTask)
+	case http.StatusServiceUnavailable:
+		return fmt.Errorf("submitting remote task %q: %w", name, ErrRejected)
+	default:
+		return fmt.Errorf("submitting remote task %q: unexpected status %s", name, resp.Status)
+	}
+}
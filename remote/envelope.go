@@ -0,0 +1,25 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var (
+	// ErrUnknownTask is returned when the envelope references a task name that was not registered on the server
+	ErrUnknownTask = errors.New("remote task is not registered")
+	// ErrRejected is returned when the server could not enqueue the task, either because the queue is full
+	// or because the pool has been stopped
+	ErrRejected = errors.New("remote task was rejected by the server")
+)
+
+// Envelope represents a named task sent over HTTP to a remote worker pool
+type Envelope struct {
+	// Name identifies the task to execute, as registered on the server
+	Name string `json:"name"`
+	// Payload holds the arguments passed to the task, encoded as JSON
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// TaskFunc represents a named task that can be executed remotely
+type TaskFunc func(payload json.RawMessage) error
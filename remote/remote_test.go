@@ -0,0 +1,124 @@
+package remote_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kraneware/pond"
+	"github.com/kraneware/pond/remote"
+)
+
+func assertEqual(t *testing.T, expected interface{}, actual interface{}) {
+	if expected != actual {
+		t.Helper()
+		t.Errorf("Expected %T(%v) but was %T(%v)", expected, expected, actual, actual)
+	}
+}
+
+func TestSubmitRemoteTask(t *testing.T) {
+
+	pool := pond.New(2, 10)
+
+	var sum int32
+	server := remote.NewServer(pool)
+	server.Register("add", func(payload json.RawMessage) error {
+		var n int32
+		if err := json.Unmarshal(payload, &n); err != nil {
+			return err
+		}
+		atomic.AddInt32(&sum, n)
+		return nil
+	})
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	client := remote.NewClient(httpServer.URL, nil)
+	for i := 1; i <= 4; i++ {
+		err := client.Submit(context.Background(), "add", i)
+		assertEqual(t, nil, err)
+	}
+
+	pool.StopAndWait()
+
+	assertEqual(t, int32(10), atomic.LoadInt32(&sum))
+}
+
+func TestSubmitUnknownRemoteTask(t *testing.T) {
+
+	pool := pond.New(1, 1)
+	defer pool.StopAndWait()
+
+	httpServer := httptest.NewServer(remote.NewServer(pool))
+	defer httpServer.Close()
+
+	err := remote.NewClient(httpServer.URL, nil).Submit(context.Background(), "missing", nil)
+
+	assertEqual(t, true, errors.Is(err, remote.ErrUnknownTask))
+}
+
+func TestSubmitRemoteTaskOnStoppedPool(t *testing.T) {
+
+	pool := pond.New(1, 1)
+	pool.StopAndWait()
+
+	server := remote.NewServer(pool)
+	server.Register("noop", func(payload json.RawMessage) error {
+		return nil
+	})
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	err := remote.NewClient(httpServer.URL, nil).Submit(context.Background(), "noop", nil)
+
+	assertEqual(t, true, errors.Is(err, remote.ErrRejected))
+}
+
+func TestRemoteTaskErrorHandler(t *testing.T) {
+
+	pool := pond.New(1, 1)
+
+	expectedErr := errors.New("task failed")
+	var capturedErr error
+	server := remote.NewServer(pool, remote.ErrorHandler(func(envelope remote.Envelope, err error) {
+		capturedErr = err
+	}))
+	server.Register("fail", func(payload json.RawMessage) error {
+		return expectedErr
+	})
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	err := remote.NewClient(httpServer.URL, nil).Submit(context.Background(), "fail", nil)
+	assertEqual(t, nil, err)
+
+	pool.StopAndWait()
+
+	assertEqual(t, expectedErr, capturedErr)
+}
+
+func TestRemoteServerRejectsInvalidRequests(t *testing.T) {
+
+	pool := pond.New(1, 1)
+	defer pool.StopAndWait()
+
+	httpServer := httptest.NewServer(remote.NewServer(pool))
+	defer httpServer.Close()
+
+	resp, err := http.Get(httpServer.URL)
+	assertEqual(t, nil, err)
+	resp.Body.Close()
+	assertEqual(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = http.Post(httpServer.URL, "application/json", nil)
+	assertEqual(t, nil, err)
+	resp.Body.Close()
+	assertEqual(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,116 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/kraneware/pond"
+)
+
+const (
+	// defaultMaxBodySize defines the default maximum size of a request body when not explicitly specified
+	// via the MaxBodySize() option
+	defaultMaxBodySize = 1 << 20
+)
+
+// defaultErrorHandler is the default handler for errors returned by remote tasks
+func defaultErrorHandler(envelope Envelope, err error) {
+	fmt.Printf("Remote task %q failed: %v\n", envelope.Name, err)
+}
+
+// Option represents an option that can be passed when instantiating a server to customize it
+type Option func(*Server)
+
+// ErrorHandler allows to change the function invoked when a remote task returns an error
+func ErrorHandler(errorHandler func(Envelope, error)) Option {
+	return func(server *Server) {
+		server.errorHandler = errorHandler
+	}
+}
+
+// MaxBodySize allows to change the maximum size (in bytes) of the envelopes accepted by the server
+func MaxBodySize(maxBodySize int64) Option {
+	return func(server *Server) {
+		server.maxBodySize = maxBodySize
+	}
+}
+
+// Server is an http.Handler that accepts task envelopes and enqueues them on a local worker pool
+type Server struct {
+	pool         *pond.WorkerPool
+	errorHandler func(Envelope, error)
+	maxBodySize  int64
+	tasks        map[string]TaskFunc
+	mutex        sync.RWMutex
+}
+
+// NewServer creates a server that enqueues the tasks it receives on the given worker pool.
+// The options parameter can take a list of functions to customize configuration values on this server.
+func NewServer(pool *pond.WorkerPool, options ...Option) *Server {
+
+	server := &Server{
+		pool:         pool,
+		errorHandler: defaultErrorHandler,
+		maxBodySize:  defaultMaxBodySize,
+		tasks:        make(map[string]TaskFunc),
+	}
+
+	for _, opt := range options {
+		opt(server)
+	}
+
+	if server.maxBodySize <= 0 {
+		server.maxBodySize = defaultMaxBodySize
+	}
+
+	return server
+}
+
+// Register associates a task with the given name, replacing any task previously registered under that name
+func (s *Server) Register(name string, task TaskFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.tasks[name] = task
+}
+
+// ServeHTTP decodes the envelope contained in the request body and enqueues the named task on the pool.
+// It responds with 202 (Accepted) once the task is queued, 404 (Not Found) if the task is not registered
+// and 503 (Service Unavailable) if the pool cannot accept more tasks.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var envelope Envelope
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.maxBodySize)).Decode(&envelope); err != nil {
+		http.Error(w, fmt.Sprintf("invalid envelope: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.RLock()
+	task, ok := s.tasks[envelope.Name]
+	s.mutex.RUnlock()
+
+	if !ok {
+		http.Error(w, ErrUnknownTask.Error(), http.StatusNotFound)
+		return
+	}
+
+	submitted := s.pool.TrySubmit(func() {
+		if err := task(envelope.Payload); err != nil {
+			s.errorHandler(envelope, err)
+		}
+	})
+	if !submitted {
+		http.Error(w, ErrRejected.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
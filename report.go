@@ -0,0 +1,37 @@
+package pond
+
+import "time"
+
+// ReportEvery allows to periodically report the stats of a worker pool, e.g. to log them from long-running
+// processes without relying on external scrapers. The reporter function receives a snapshot of the stats every
+// interval and once more when the pool stops. If reporter is nil, stats are written to the default logger
+// (log/slog when available, otherwise log) as a line like "pond: 12 running, 340 queued, p99 queue wait 1s".
+// Reporting is disabled if interval is not greater than zero.
+func ReportEvery(interval time.Duration, reporter func(Stats)) Option {
+	return func(pool *WorkerPool) {
+		if reporter == nil {
+			reporter = defaultStatsReporter
+		}
+		pool.reportInterval = interval
+		pool.reporter = reporter
+	}
+}
+
+// report represents the work done by the reporter goroutine
+func (p *WorkerPool) report() {
+	defer p.workersWaitGroup.Done()
+
+	ticker := time.NewTicker(p.reportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.reporter(p.StatsSnapshot())
+		case <-p.context.Done():
+			// Report the final stats before exiting
+			p.reporter(p.StatsSnapshot())
+			return
+		}
+	}
+}
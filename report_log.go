@@ -0,0 +1,10 @@
+//go:build !go1.21
+
+package pond
+
+import "log"
+
+// defaultStatsReporter writes the given stats to the standard logger
+func defaultStatsReporter(stats Stats) {
+	log.Print(stats.String())
+}
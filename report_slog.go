@@ -0,0 +1,19 @@
+//go:build go1.21
+
+package pond
+
+import "log/slog"
+
+// defaultStatsReporter writes the given stats to the default structured logger
+func defaultStatsReporter(stats Stats) {
+	slog.Info(stats.String(),
+		slog.String("pool", stats.Name),
+		slog.Int("running", stats.RunningWorkers),
+		slog.Uint64("queued", stats.WaitingTasks),
+		slog.Duration("p99QueueWait", stats.QueueWait.Quantile(0.99)),
+		slog.Duration("oldestWaiting", stats.OldestWaiting),
+		slog.Float64("saturation", stats.Saturation),
+		slog.Uint64("successful", stats.SuccessfulTasks),
+		slog.Uint64("failed", stats.FailedTasks),
+	)
+}
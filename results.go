@@ -0,0 +1,203 @@
+package pond
+
+import (
+	"context"
+	"sync"
+)
+
+// Result holds the outcome of a task, as reported by pond's asynchronous APIs (futures, result groups and gather
+// helpers). Err is a PanicError if the task panicked, and it holds why the task was not executed otherwise, such as
+// the error of the submit hook that vetoed it or the error of the context that was canceled before it started.
+type Result[T any] struct {
+	Value T
+	Err   error
+	// Info is the metadata of the task as of when it started, or when it was rejected if it didn't
+	Info TaskInfo
+}
+
+// ResultGroupOption represents an option that can be passed when creating a result group
+type ResultGroupOption func(*resultGroupConfig)
+
+// resultGroupConfig holds the settings of a result group that don't depend on its type parameter
+type resultGroupConfig struct {
+	ordered bool
+}
+
+// Ordered makes a result group deliver results in submission order rather than completion order.
+// Results of tasks that complete before the ones submitted earlier are buffered until those are delivered.
+func Ordered() ResultGroupOption {
+	return func(config *resultGroupConfig) {
+		config.ordered = true
+	}
+}
+
+// ResultGroup is a group of tasks that return a value, whose results are streamed as they complete
+// (see Results) so consumers can start processing them before the whole group finishes
+type ResultGroup[T any] struct {
+	config  resultGroupConfig
+	pool    *WorkerPool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	results chan Result[T]
+	// Completed results that have not been delivered yet, indexed by their position in the delivery order
+	buffer    map[int]Result[T]
+	submitted int
+	completed int
+	delivered int
+	pending   int
+	closed    bool
+	notify    chan struct{}
+	mutex     sync.Mutex
+}
+
+// NewResultGroup creates a result group bound to the given pool and an associated Context derived from ctx,
+// which is passed to all tasks. The derived Context is canceled when ctx is canceled or Cancel is called.
+func NewResultGroup[T any](ctx context.Context, pool *WorkerPool, options ...ResultGroupOption) (*ResultGroup[T], context.Context) {
+
+	if ctx == nil {
+		panic("a non-nil context needs to be specified when using NewResultGroup")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	group := &ResultGroup[T]{
+		pool:    pool,
+		ctx:     ctx,
+		cancel:  cancel,
+		results: make(chan Result[T]),
+		buffer:  make(map[int]Result[T]),
+		notify:  make(chan struct{}, 1),
+	}
+
+	// Apply all options
+	for _, opt := range options {
+		opt(&group.config)
+	}
+
+	go group.deliver()
+
+	return group, ctx
+}
+
+// Submit adds a task to this group and sends it to the worker pool to be executed. Its result is delivered
+// through the Results channel once it completes. Tasks that did not start before the group's context was canceled
+// are skipped and their result holds the context's error, tasks that panic yield a result holding a PanicError
+// and tasks dropped by the pool yield a result holding the drop error (see DropReason). Submit must not be called
+// after Close.
+func (g *ResultGroup[T]) Submit(task func(ctx context.Context) (T, error)) {
+	g.mutex.Lock()
+	if g.closed {
+		g.mutex.Unlock()
+		panic("submit on closed result group")
+	}
+	index := g.submitted
+	g.submitted++
+	g.pending++
+	g.mutex.Unlock()
+
+	g.pool.submitWithInfo(func(info TaskInfo) {
+		completed := false
+		defer func() {
+			if !completed {
+				// Report the panic as the result of the task and let the pool handle it
+				p := recover()
+				g.push(index, Result[T]{Err: newPanicError(p), Info: info})
+				panic(p)
+			}
+		}()
+
+		result := Result[T]{Info: info}
+		if err := g.ctx.Err(); err != nil {
+			result.Err = err
+		} else {
+			result.Value, result.Err = task(g.ctx)
+		}
+		completed = true
+
+		g.push(index, result)
+	}, func(info TaskInfo, err error) {
+		g.push(index, Result[T]{Err: err, Info: info})
+	})
+}
+
+// Close signals that no more tasks will be submitted to this group, so the Results channel can be closed
+// once all submitted tasks have completed
+func (g *ResultGroup[T]) Close() {
+	g.mutex.Lock()
+	g.closed = true
+	g.mutex.Unlock()
+
+	g.signal()
+}
+
+// Cancel cancels the group's context, which causes tasks that did not start yet to be skipped
+// and stops the delivery of results
+func (g *ResultGroup[T]) Cancel() {
+	g.cancel()
+}
+
+// Results returns the channel through which the results of the tasks are delivered, in completion order
+// (or in submission order if the group was created with the Ordered option).
+// The channel is closed once Close was called and all results were delivered, or when the group's context is canceled.
+// It must be drained (or the group canceled) to release the resources held by the group.
+func (g *ResultGroup[T]) Results() <-chan Result[T] {
+	return g.results
+}
+
+// push records the result of the completed task submitted at the given index
+func (g *ResultGroup[T]) push(index int, result Result[T]) {
+	g.mutex.Lock()
+	if !g.config.ordered {
+		index = g.completed
+	}
+	g.buffer[index] = result
+	g.completed++
+	g.pending--
+	g.mutex.Unlock()
+
+	g.signal()
+}
+
+// signal wakes up the delivery goroutine
+func (g *ResultGroup[T]) signal() {
+	select {
+	case g.notify <- struct{}{}:
+	default:
+	}
+}
+
+// deliver represents the work done by the goroutine that sends results to the Results channel.
+// Results are buffered so that workers never block on a slow consumer.
+func (g *ResultGroup[T]) deliver() {
+	defer close(g.results)
+
+	for {
+		g.mutex.Lock()
+		next, ok := g.buffer[g.delivered]
+		if !ok {
+			done := g.closed && g.pending == 0
+			g.mutex.Unlock()
+
+			if done {
+				// Release the context's resources
+				g.cancel()
+				return
+			}
+
+			select {
+			case <-g.notify:
+			case <-g.ctx.Done():
+				return
+			}
+			continue
+		}
+		delete(g.buffer, g.delivered)
+		g.delivered++
+		g.mutex.Unlock()
+
+		select {
+		case g.results <- next:
+		case <-g.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,24 @@
+//go:build go1.23
+
+package pond
+
+import "iter"
+
+// Iter closes this group (see Close) and returns an iterator over the results of its tasks, in completion order.
+// Breaking out of the loop cancels the group, so tasks that did not start yet are skipped.
+//
+//	for value, err := range group.Iter() {
+//		...
+//	}
+func (g *ResultGroup[T]) Iter() iter.Seq2[T, error] {
+	g.Close()
+
+	return func(yield func(T, error) bool) {
+		for result := range g.Results() {
+			if !yield(result.Value, result.Err) {
+				g.Cancel()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+//go:build go1.23
+
+package pond_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestResultGroupIter(t *testing.T) {
+
+	pool := pond.New(5, 100)
+	defer pool.StopAndWait()
+
+	group, _ := pond.NewResultGroup[int](context.Background(), pool)
+	for i := 1; i <= 10; i++ {
+		n := i
+		group.Submit(func(ctx context.Context) (int, error) {
+			return n, nil
+		})
+	}
+
+	sum := 0
+	for value, err := range group.Iter() {
+		assertEqual(t, nil, err)
+		sum += value
+	}
+
+	assertEqual(t, 55, sum)
+}
+
+func TestResultGroupIterBreakCancelsGroup(t *testing.T) {
+
+	pool := pond.New(1, 100)
+	defer pool.StopAndWait()
+
+	group, ctx := pond.NewResultGroup[int](context.Background(), pool)
+
+	var executed int32
+	for i := 0; i < 10; i++ {
+		group.Submit(func(ctx context.Context) (int, error) {
+			if atomic.AddInt32(&executed, 1) > 1 {
+				// Block all tasks but the first one until the group is cancelled
+				<-ctx.Done()
+			}
+			return 0, nil
+		})
+	}
+
+	for range group.Iter() {
+		break
+	}
+
+	assertEqual(t, context.Canceled, ctx.Err())
+
+	pool.StopAndWait()
+
+	assertEqual(t, true, atomic.LoadInt32(&executed) <= 2)
+}
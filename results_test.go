@@ -0,0 +1,212 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestResultGroup(t *testing.T) {
+
+	pool := pond.New(5, 100)
+	defer pool.StopAndWait()
+
+	group, _ := pond.NewResultGroup[int](context.Background(), pool)
+
+	sampleErr := errors.New("sample error")
+	for i := 0; i < 10; i++ {
+		n := i
+		group.Submit(func(ctx context.Context) (int, error) {
+			if n == 3 {
+				return 0, sampleErr
+			}
+			return n * n, nil
+		})
+	}
+	group.Close()
+
+	var values []int
+	var errs []error
+	for result := range group.Results() {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+			continue
+		}
+		values = append(values, result.Value)
+	}
+
+	sort.Ints(values)
+	assertEqual(t, 9, len(values))
+	assertEqual(t, 81, values[8])
+	assertEqual(t, 1, len(errs))
+	assertEqual(t, sampleErr, errs[0])
+}
+
+func TestResultGroupStreamsResults(t *testing.T) {
+
+	pool := pond.New(2, 100)
+	defer pool.StopAndWait()
+
+	group, _ := pond.NewResultGroup[string](context.Background(), pool)
+
+	release := make(chan struct{})
+	group.Submit(func(ctx context.Context) (string, error) {
+		return "fast", nil
+	})
+	group.Submit(func(ctx context.Context) (string, error) {
+		<-release
+		return "slow", nil
+	})
+	group.Close()
+
+	// The first result is delivered before the whole group completes
+	first := <-group.Results()
+	assertEqual(t, "fast", first.Value)
+
+	close(release)
+
+	second := <-group.Results()
+	assertEqual(t, "slow", second.Value)
+
+	_, ok := <-group.Results()
+	assertEqual(t, false, ok)
+}
+
+func TestResultGroupOrdered(t *testing.T) {
+
+	pool := pond.New(10, 100)
+	defer pool.StopAndWait()
+
+	group, _ := pond.NewResultGroup[int](context.Background(), pool, pond.Ordered())
+
+	for i := 0; i < 10; i++ {
+		n := i
+		group.Submit(func(ctx context.Context) (int, error) {
+			// Tasks submitted first complete last
+			time.Sleep(time.Duration(10-n) * time.Millisecond)
+			return n, nil
+		})
+	}
+	group.Close()
+
+	var values []int
+	for result := range group.Results() {
+		values = append(values, result.Value)
+	}
+
+	assertEqual(t, 10, len(values))
+	for i, value := range values {
+		assertEqual(t, i, value)
+	}
+}
+
+func TestResultGroupPanic(t *testing.T) {
+
+	pool := pond.New(1, 100, pond.PanicHandler(func(interface{}) {}))
+	defer pool.StopAndWait()
+
+	group, _ := pond.NewResultGroup[int](context.Background(), pool)
+
+	group.Submit(func(ctx context.Context) (int, error) {
+		panic("boom")
+	})
+	group.Close()
+
+	result := <-group.Results()
+	assertEqual(t, "task panicked: boom", result.Err.Error())
+}
+
+func TestResultGroupCancel(t *testing.T) {
+
+	pool := pond.New(1, 100)
+	defer pool.StopAndWait()
+
+	group, ctx := pond.NewResultGroup[int](context.Background(), pool)
+
+	group.Submit(func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+
+	time.Sleep(5 * time.Millisecond)
+	group.Cancel()
+
+	// The channel is closed even though Close was never called
+	for range group.Results() {
+	}
+
+	assertEqual(t, context.Canceled, ctx.Err())
+}
+
+func TestResultGroupWithNilContext(t *testing.T) {
+
+	pool := pond.New(1, 100)
+	defer pool.StopAndWait()
+
+	var thrownPanic interface{}
+	func() {
+		defer func() {
+			thrownPanic = recover()
+		}()
+		pond.NewResultGroup[int](nil, pool)
+	}()
+
+	assertEqual(t, "a non-nil context needs to be specified when using NewResultGroup", thrownPanic)
+}
+
+func TestResultGroupResultInfo(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.Name("results"))
+	defer pool.StopAndWait()
+
+	group, _ := pond.NewResultGroup[int](context.Background(), pool)
+	group.Submit(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	group.Close()
+
+	result := <-group.Results()
+	assertEqual(t, 1, result.Value)
+	assertEqual(t, "results", result.Info.Pool)
+	assertEqual(t, false, result.Info.StartedAt.IsZero())
+}
+
+func TestResultGroupWithExpiredTasks(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MaxQueueAge(time.Millisecond))
+	defer pool.StopAndWait()
+
+	// Occupy the only worker until the tasks of the group expire
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	group, _ := pond.NewResultGroup[int](context.Background(), pool)
+	for i := 0; i < 3; i++ {
+		group.Submit(func(ctx context.Context) (int, error) {
+			return 1, nil
+		})
+	}
+	group.Close()
+	time.Sleep(5 * time.Millisecond)
+	close(release)
+
+	var errs []error
+	assertReturns(t, func() {
+		for result := range group.Results() {
+			errs = append(errs, result.Err)
+		}
+	})
+	assertEqual(t, 3, len(errs))
+	for _, err := range errs {
+		assertEqual(t, pond.ErrTaskExpired, err)
+	}
+}
@@ -0,0 +1,88 @@
+package pond
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy defines how the tasks of a group are retried when they return an error (see Retry)
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a task is attempted, including the first attempt
+	MaxAttempts int
+	// Backoff is the delay before the first retry, which is doubled after each retry
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries, if greater than zero
+	MaxBackoff time.Duration
+	// RetryIf decides whether an error is worth retrying. If nil, all errors are retried.
+	RetryIf func(err error) bool
+	// ResumeFromCheckpoint makes each retry start from the latest state reported with Checkpoint by the previous
+	// attempts, which the task can read from TaskInfo.Checkpoint (see FromContext and SubmitContext)
+	ResumeFromCheckpoint bool
+}
+
+// Retry makes a task group retry the tasks that return an error according to the given policy. Each attempt is
+// submitted to the pool as a separate task, whose TaskInfo.Attempt holds the attempt number (as seen by event
+// listeners and handlers), and the pool's workers are not held while waiting between attempts.
+// Only the error of the last attempt is recorded by the group, and tasks are not retried once its context is canceled.
+func Retry(policy RetryPolicy) GroupOption {
+	return func(group *TaskGroupWithContext) {
+		group.retry = &policy
+	}
+}
+
+// shouldRetry returns true if a task that returned the given error on the given attempt must be retried
+func (r *RetryPolicy) shouldRetry(err error, attempt int) bool {
+	if r == nil || attempt >= r.MaxAttempts {
+		return false
+	}
+	return r.RetryIf == nil || r.RetryIf(err)
+}
+
+// backoff returns the delay to wait before the given attempt (starting at 2, since the first one is not a retry)
+func (r *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := r.Backoff
+	for i := 2; i < attempt && delay > 0; i++ {
+		delay *= 2
+		if r.MaxBackoff > 0 && delay >= r.MaxBackoff {
+			break
+		}
+	}
+	if r.MaxBackoff > 0 && delay > r.MaxBackoff {
+		delay = r.MaxBackoff
+	}
+	return delay
+}
+
+// retryAttempt waits for the backoff delay of the given attempt and then submits it. If the group's context
+// is canceled while waiting or the pool is stopped, the error of the previous attempt is recorded instead.
+func (g *TaskGroupWithContext) retryAttempt(task func(context.Context) error, index int, label string, attempt int, lastErr error, checkpoint interface{}) {
+
+	giveUp := func() {
+		g.fail(g.wrapError(lastErr, index, label))
+		g.releaseSlot()
+		g.pending.remove(1)
+	}
+
+	if delay := g.retry.backoff(attempt); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-g.ctx.Done():
+			giveUp()
+			return
+		}
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			if p != ErrSubmitOnStoppedPool {
+				panic(p)
+			}
+			giveUp()
+		}
+	}()
+
+	g.submitAttempt(task, index, label, attempt, lastErr, checkpoint)
+}
@@ -0,0 +1,115 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestGroupRetry(t *testing.T) {
+
+	var attempts []int
+	var mutex sync.Mutex
+	pool := pond.New(2, 10, pond.Events(pond.EventListener{
+		OnTaskFinished: func(info pond.TaskInfo, panic interface{}) {
+			mutex.Lock()
+			attempts = append(attempts, info.Attempt)
+			mutex.Unlock()
+		},
+	}))
+
+	group, _ := pool.GroupContext(context.Background(), pond.Retry(pond.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+	}))
+
+	var calls int32
+	group.Submit(func() error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+
+	assertEqual(t, nil, group.Wait())
+	assertEqual(t, int32(3), atomic.LoadInt32(&calls))
+
+	pool.StopAndWait()
+	assertEqual(t, 3, len(attempts))
+	for i, attempt := range attempts {
+		assertEqual(t, i+1, attempt)
+	}
+}
+
+func TestGroupRetryExhausted(t *testing.T) {
+
+	pool := pond.New(2, 10)
+	defer pool.StopAndWait()
+
+	group, _ := pool.GroupContext(context.Background(), pond.Retry(pond.RetryPolicy{
+		MaxAttempts: 5,
+	}))
+
+	var calls int32
+	group.Submit(func() error {
+		return fmt.Errorf("attempt %d failed", atomic.AddInt32(&calls, 1))
+	})
+
+	// Only the error of the last attempt is recorded
+	assertEqual(t, "attempt 5 failed", group.Wait().Error())
+	assertEqual(t, int32(5), atomic.LoadInt32(&calls))
+}
+
+func TestGroupRetryIf(t *testing.T) {
+
+	pool := pond.New(2, 10)
+	defer pool.StopAndWait()
+
+	permanent := errors.New("permanent")
+	group, _ := pool.GroupContext(context.Background(), pond.Retry(pond.RetryPolicy{
+		MaxAttempts: 5,
+		RetryIf: func(err error) bool {
+			return !errors.Is(err, permanent)
+		},
+	}))
+
+	var calls int32
+	group.Submit(func() error {
+		atomic.AddInt32(&calls, 1)
+		return permanent
+	})
+
+	assertEqual(t, permanent, group.Wait())
+	assertEqual(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestGroupRetryStopsWhenCanceled(t *testing.T) {
+
+	pool := pond.New(2, 10)
+	defer pool.StopAndWait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	group, _ := pool.GroupContext(ctx, pond.CollectErrors(), pond.Retry(pond.RetryPolicy{
+		MaxAttempts: 10,
+		Backoff:     time.Hour,
+	}))
+
+	var calls int32
+	group.Submit(func() error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("failed")
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	// The error of the last attempt is kept when the group gives up
+	assertEqual(t, "1 tasks failed: task 0: failed", group.Wait().Error())
+	assertEqual(t, int32(1), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,26 @@
+package pond
+
+// Saturation returns how saturated this pool is, as a value between 0 and 1 suited to drive autoscalers
+// (e.g. as a custom metric of a horizontal pod autoscaler). It's the average of two ratios, each between 0 and 1:
+//
+//	saturation = (busy workers / maximum workers + waiting tasks / queue capacity) / 2
+//
+// The maximum number of workers includes burst workers (see Burst), and waiting tasks include the submitters
+// blocked waiting for room in the queue, so the second ratio is capped at 1. Without a queue (a capacity of 0),
+// it's 1 as soon as a task is waiting. A pool is thus half saturated when all its workers are busy, and fully
+// saturated when its queue is full too.
+func (p *WorkerPool) Saturation() float64 {
+	var workers float64
+	if maxWorkers := p.effectiveMaxWorkers(); maxWorkers > 0 {
+		if busy := p.RunningWorkers() - p.IdleWorkers(); busy > 0 {
+			workers = float64(busy) / float64(maxWorkers)
+		}
+	}
+	if workers > 1 {
+		workers = 1
+	}
+
+	queue, _ := p.queueUtilization()
+
+	return (workers + queue) / 2
+}
@@ -0,0 +1,38 @@
+package pond_test
+
+import (
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestSaturation(t *testing.T) {
+
+	pool := pond.New(2, 4)
+	assertEqual(t, float64(0), pool.Saturation())
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	blocking := func() {
+		started <- struct{}{}
+		<-release
+	}
+
+	// Half of the workers are busy
+	pool.Submit(blocking)
+	<-started
+	assertEqual(t, 0.25, pool.Saturation())
+
+	// All workers are busy and half of the queue is used
+	pool.Submit(blocking)
+	<-started
+	pool.Submit(func() {})
+	pool.Submit(func() {})
+	assertEqual(t, 0.75, pool.Saturation())
+	assertEqual(t, 0.75, pool.StatsSnapshot().Saturation)
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, float64(0), pool.Saturation())
+}
@@ -0,0 +1,103 @@
+package pond
+
+import (
+	"time"
+)
+
+// ScalingPolicy defines how fast a worker pool grows and shrinks, which prevents thrashing under oscillating load.
+// Fields left at their zero value do not constrain scaling.
+type ScalingPolicy struct {
+	// MaxStartsPerSecond is the maximum rate at which workers are started once the pool has at least MinWorkers
+	// (and at least one) workers. Tasks that can't get a new worker wait in the queue for a running one.
+	MaxStartsPerSecond float64
+	// ScaleDownDelay is how long the pool must remain underutilized before idle workers are stopped.
+	// Utilization is sampled every idle timeout, when idle workers are considered for stopping.
+	ScaleDownDelay time.Duration
+	// ScaleDownThreshold is the fraction of running workers that are busy (between 0 and 1) below which the pool
+	// is considered underutilized. Defaults to 1, i.e. the pool is underutilized as long as any worker is idle.
+	ScaleDownThreshold float64
+}
+
+// Scaling allows to change how fast a worker pool grows and shrinks
+func Scaling(policy ScalingPolicy) Option {
+	return func(pool *WorkerPool) {
+		pool.scaling = &scalingLimiter{
+			policy: policy,
+		}
+	}
+}
+
+// scalingLimiter enforces a scaling policy. Its methods must be called while holding the pool mutex.
+type scalingLimiter struct {
+	policy ScalingPolicy
+	// Token bucket limiting the rate at which workers are started
+	tokens     float64
+	lastRefill time.Time
+	// Time since which the pool has been underutilized, or the zero time if it's not
+	underutilizedSince time.Time
+}
+
+// normalize makes sure the policy is consistent
+func (s *scalingLimiter) normalize() {
+	if s.policy.ScaleDownThreshold <= 0 || s.policy.ScaleDownThreshold > 1 {
+		s.policy.ScaleDownThreshold = 1
+	}
+	if s.policy.MaxStartsPerSecond < 0 {
+		s.policy.MaxStartsPerSecond = 0
+	}
+	if s.policy.ScaleDownDelay < 0 {
+		s.policy.ScaleDownDelay = 0
+	}
+	s.tokens = s.burst()
+}
+
+// burst returns the maximum number of workers that can be started at once
+func (s *scalingLimiter) burst() float64 {
+	if s.policy.MaxStartsPerSecond < 1 {
+		return 1
+	}
+	return s.policy.MaxStartsPerSecond
+}
+
+// allowStart returns true if a new worker can be started at the given time
+func (s *scalingLimiter) allowStart(now time.Time) bool {
+	if s == nil || s.policy.MaxStartsPerSecond <= 0 {
+		return true
+	}
+
+	if !s.lastRefill.IsZero() {
+		s.tokens += now.Sub(s.lastRefill).Seconds() * s.policy.MaxStartsPerSecond
+		if burst := s.burst(); s.tokens > burst {
+			s.tokens = burst
+		}
+	}
+	s.lastRefill = now
+
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+// allowStop returns true if an idle worker can be stopped at the given time, given the current number of
+// running and idle workers
+func (s *scalingLimiter) allowStop(now time.Time, runningWorkers, idleWorkers int) bool {
+	if s == nil || s.policy.ScaleDownDelay <= 0 {
+		return true
+	}
+
+	utilization := 0.0
+	if runningWorkers > 0 {
+		utilization = float64(runningWorkers-idleWorkers) / float64(runningWorkers)
+	}
+
+	if utilization >= s.policy.ScaleDownThreshold {
+		s.underutilizedSince = time.Time{}
+		return false
+	}
+	if s.underutilizedSince.IsZero() {
+		s.underutilizedSince = now
+	}
+	return now.Sub(s.underutilizedSince) >= s.policy.ScaleDownDelay
+}
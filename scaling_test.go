@@ -0,0 +1,75 @@
+package pond_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestScalingLimitsWorkerStartRate(t *testing.T) {
+
+	pool := pond.New(10, 100, pond.Scaling(pond.ScalingPolicy{
+		MaxStartsPerSecond: 1,
+	}))
+
+	release := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		pool.Submit(func() {
+			<-release
+		})
+	}
+
+	// The first worker is always started, the second one consumes the only token
+	assertEqual(t, 2, pool.RunningWorkers())
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, uint64(10), pool.CompletedTasks())
+}
+
+func TestScalingDelaysScaleDown(t *testing.T) {
+
+	pool := pond.New(10, 100, pond.IdleTimeout(5*time.Millisecond), pond.Scaling(pond.ScalingPolicy{
+		ScaleDownDelay: 200 * time.Millisecond,
+	}))
+
+	release := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		pool.Submit(func() {
+			<-release
+		})
+	}
+	assertEqual(t, 5, pool.RunningWorkers())
+	close(release)
+
+	// Workers are idle but the pool has not been underutilized long enough yet
+	time.Sleep(50 * time.Millisecond)
+	assertEqual(t, 5, pool.RunningWorkers())
+
+	time.Sleep(400 * time.Millisecond)
+	assertEqual(t, 0, pool.RunningWorkers())
+
+	pool.StopAndWait()
+}
+
+func TestScalingValidation(t *testing.T) {
+
+	_, err := pond.NewWithOptions(10, 10, pond.Scaling(pond.ScalingPolicy{
+		ScaleDownThreshold: 2,
+	}))
+	assertEqual(t, true, err != nil)
+
+	pool, err := pond.NewWithOptions(10, 10, pond.Scaling(pond.ScalingPolicy{
+		MaxStartsPerSecond: 5,
+		ScaleDownDelay:     time.Second,
+	}))
+	assertEqual(t, nil, err)
+	assertEqual(t, pond.ScalingPolicy{
+		MaxStartsPerSecond: 5,
+		ScaleDownDelay:     time.Second,
+		ScaleDownThreshold: 1,
+	}, *pool.Options().ScalingPolicy)
+	pool.StopAndWait()
+}
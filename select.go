@@ -0,0 +1,78 @@
+package pond
+
+import (
+	"context"
+	"reflect"
+)
+
+// Handle is the pending outcome of a task, regardless of the type of its result. It is implemented by Future.
+type Handle interface {
+	// Done returns a channel that is closed once the task has completed
+	Done() <-chan struct{}
+	// Err returns the error of the task once it has completed
+	Err() error
+}
+
+// Select waits for the first of the given handles to complete and returns its index along with its error.
+// If ctx is done before any of them completes, it returns -1 and ctx.Err(). Nil handles are ignored.
+func Select(ctx context.Context, handles ...Handle) (index int, err error) {
+
+	if ctx == nil {
+		panic("a non-nil context needs to be specified when using Select")
+	}
+
+	cases := make([]reflect.SelectCase, 0, len(handles)+1)
+	indexes := make([]int, 0, len(handles))
+	for i, handle := range handles {
+		if handle == nil {
+			continue
+		}
+		cases = append(cases, reflect.SelectCase{
+			Dir:  reflect.SelectRecv,
+			Chan: reflect.ValueOf(handle.Done()),
+		})
+		indexes = append(indexes, i)
+	}
+	cases = append(cases, reflect.SelectCase{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(ctx.Done()),
+	})
+
+	chosen, _, _ := reflect.Select(cases)
+	if chosen == len(indexes) {
+		return -1, ctx.Err()
+	}
+
+	index = indexes[chosen]
+	return index, handles[index].Err()
+}
+
+// WaitAll waits for all the given handles to complete and returns the error of the first one, in argument order,
+// that failed. If ctx is done before all of them complete, it returns ctx.Err(). Nil handles are ignored.
+func WaitAll(ctx context.Context, handles ...Handle) error {
+
+	if ctx == nil {
+		panic("a non-nil context needs to be specified when using WaitAll")
+	}
+
+	for _, handle := range handles {
+		if handle == nil {
+			continue
+		}
+		select {
+		case <-handle.Done():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for _, handle := range handles {
+		if handle == nil {
+			continue
+		}
+		if err := handle.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestSelect(t *testing.T) {
+
+	pool := pond.New(2, 10)
+	release := make(chan struct{})
+
+	slow := pond.SubmitFuture(pool, func() (int, error) {
+		<-release
+		return 1, nil
+	})
+	fast := pond.SubmitFuture(pool, func() (string, error) {
+		return "", errors.New("failed")
+	})
+
+	index, err := pond.Select(context.Background(), slow, nil, fast)
+	assertEqual(t, 2, index)
+	assertEqual(t, "failed", err.Error())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	index, err = pond.Select(ctx, slow)
+	assertEqual(t, -1, index)
+	assertEqual(t, context.DeadlineExceeded, err)
+
+	close(release)
+	pool.StopAndWait()
+}
+
+func TestWaitAll(t *testing.T) {
+
+	pool := pond.New(2, 10)
+	release := make(chan struct{})
+
+	first := pond.SubmitFuture(pool, func() (int, error) {
+		<-release
+		return 0, errors.New("first")
+	})
+	second := pond.SubmitFuture(pool, func() (string, error) {
+		return "", errors.New("second")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assertEqual(t, context.DeadlineExceeded, pond.WaitAll(ctx, first, second))
+
+	// The error of the first failed handle is returned, regardless of completion order
+	close(release)
+	assertEqual(t, "first", pond.WaitAll(context.Background(), first, nil, second).Error())
+	assertEqual(t, "second", pond.WaitAll(context.Background(), second, first).Error())
+
+	ok := pond.SubmitFuture(pool, func() (int, error) {
+		return 1, nil
+	})
+	assertEqual(t, nil, pond.WaitAll(context.Background(), ok))
+
+	pool.StopAndWait()
+}
@@ -0,0 +1,125 @@
+package pond
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// semaphoreWaiter represents a caller blocked until n units become available
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// semaphore is a weighted semaphore which serves waiters in FIFO order
+type semaphore struct {
+	size    int64
+	current int64
+	waiters list.List
+	mutex   sync.Mutex
+}
+
+// newSemaphore creates a weighted semaphore with the given maximum combined weight
+func newSemaphore(size int64) *semaphore {
+	return &semaphore{
+		size: size,
+	}
+}
+
+// Acquire acquires n units of the semaphore, blocking until they are available or ctx is done.
+// On failure, it returns ctx.Err() and leaves the semaphore unchanged.
+func (s *semaphore) Acquire(ctx context.Context, n int64) error {
+
+	s.mutex.Lock()
+	if s.size-s.current >= n && s.waiters.Len() == 0 {
+		s.current += n
+		s.mutex.Unlock()
+		return nil
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		s.mutex.Unlock()
+		return ctxErr
+	}
+
+	ready := make(chan struct{})
+	elem := s.waiters.PushBack(semaphoreWaiter{n: n, ready: ready})
+	s.mutex.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		s.mutex.Lock()
+		select {
+		case <-ready:
+			// Acquired the units right after ctx was cancelled, give them back
+			s.current -= n
+			s.notifyWaiters()
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			// If we were at the front and there is spare capacity, the next waiters may be able to proceed
+			if isFront && s.size > s.current {
+				s.notifyWaiters()
+			}
+		}
+		s.mutex.Unlock()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire acquires n units of the semaphore without blocking and reports whether it succeeded
+func (s *semaphore) TryAcquire(n int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.size-s.current >= n && s.waiters.Len() == 0 {
+		s.current += n
+		return true
+	}
+	return false
+}
+
+// Release releases n units of the semaphore
+func (s *semaphore) Release(n int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.current -= n
+	if s.current < 0 {
+		panic("pond: released more units than held")
+	}
+	s.notifyWaiters()
+}
+
+// Resize changes the maximum combined weight of the semaphore. Shrinking it below the weight currently held
+// doesn't affect current holders, but new acquisitions wait until enough units are released.
+func (s *semaphore) Resize(size int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.size = size
+	s.notifyWaiters()
+}
+
+// notifyWaiters wakes up as many waiters as possible, in FIFO order. Must be called with the mutex held.
+func (s *semaphore) notifyWaiters() {
+	for {
+		next := s.waiters.Front()
+		if next == nil {
+			return
+		}
+
+		w := next.Value.(semaphoreWaiter)
+		if s.size-s.current < w.n {
+			// Not enough units for the next waiter. Keep waiting to avoid starving large requests.
+			return
+		}
+
+		s.current += w.n
+		s.waiters.Remove(next)
+		close(w.ready)
+	}
+}
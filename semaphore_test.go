@@ -0,0 +1,68 @@
+package pond
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSemaphore(t *testing.T) {
+
+	sem := newSemaphore(3)
+
+	assertEqual(t, nil, sem.Acquire(context.Background(), 2))
+	assertEqual(t, true, sem.TryAcquire(1))
+	assertEqual(t, false, sem.TryAcquire(1))
+
+	// Release units from another goroutine while waiting
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		sem.Release(2)
+	}()
+	assertEqual(t, nil, sem.Acquire(context.Background(), 2))
+
+	sem.Release(3)
+	assertEqual(t, int64(0), sem.current)
+}
+
+func TestSemaphoreWithCancelledContext(t *testing.T) {
+
+	sem := newSemaphore(1)
+	assertEqual(t, true, sem.TryAcquire(1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	assertEqual(t, context.DeadlineExceeded, sem.Acquire(ctx, 1))
+	assertEqual(t, 0, sem.waiters.Len())
+
+	sem.Release(1)
+	assertEqual(t, true, sem.TryAcquire(1))
+}
+
+func TestSemaphoreServesWaitersInOrder(t *testing.T) {
+
+	sem := newSemaphore(2)
+	assertEqual(t, true, sem.TryAcquire(2))
+
+	acquired := make(chan int64, 2)
+	go func() {
+		sem.Acquire(context.Background(), 2)
+		acquired <- 2
+	}()
+	time.Sleep(5 * time.Millisecond)
+	go func() {
+		sem.Acquire(context.Background(), 1)
+		acquired <- 1
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	// Small requests must not overtake the large one waiting at the front
+	assertEqual(t, false, sem.TryAcquire(1))
+
+	sem.Release(2)
+	assertEqual(t, int64(2), <-acquired)
+
+	sem.Release(2)
+	assertEqual(t, int64(1), <-acquired)
+}
@@ -0,0 +1,147 @@
+package pond
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+)
+
+// ShardedPool is a set of independent worker pools (shards) that tasks are distributed to. Tasks submitted with
+// SubmitKeyed are routed to a shard by the hash of their key, so tasks sharing a key always run on the same shard
+// (key affinity, e.g. to keep caches warm), while submitters and workers contend on the locks and counters of
+// a single shard rather than on those of one large pool.
+type ShardedPool struct {
+	// Number of tasks submitted with Submit, used to spread them across shards. It's placed first so it's
+	// 64-bit aligned on 32-bit platforms.
+	submitted uint64
+	shards    []*WorkerPool
+}
+
+// NewSharded creates a sharded pool made of the given number of shards, each one a worker pool with up to
+// workersPerShard workers configured with the given options. Shards have no queue unless the MaxCapacity option
+// is given, in which case each one has a queue of that capacity. If the Name option is given, shards are named
+// after it followed by their index, e.g. "images-0". Invalid values are replaced by sensible defaults, like New does.
+func NewSharded(shards, workersPerShard int, options ...Option) *ShardedPool {
+	if shards <= 0 {
+		shards = 1
+	}
+
+	pool := &ShardedPool{
+		shards: make([]*WorkerPool, shards),
+	}
+	for i := range pool.shards {
+		shardOptions := append(append([]Option(nil), options...), shard(i, shards))
+		pool.shards[i] = New(workersPerShard, 0, shardOptions...)
+	}
+	return pool
+}
+
+// shard returns an option that makes a pool the shard with the given index, suffixing its name with it if it has one
+func shard(index, shards int) Option {
+	return func(pool *WorkerPool) {
+		pool.shardIndex, pool.shards = index, shards
+		if pool.name != "" {
+			pool.name = fmt.Sprintf("%s-%d", pool.name, index)
+		}
+	}
+}
+
+// Shards returns the shards of this pool, in index order
+func (s *ShardedPool) Shards() []*WorkerPool {
+	return append([]*WorkerPool(nil), s.shards...)
+}
+
+// Shard returns the shard that tasks with the given key are routed to
+func (s *ShardedPool) Shard(key string) *WorkerPool {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+
+	return s.shards[jumpHash(hash.Sum64(), len(s.shards))]
+}
+
+// Submit sends a task to one of the shards for execution, spreading tasks across shards in a round-robin fashion
+func (s *ShardedPool) Submit(task func()) {
+	index := (atomic.AddUint64(&s.submitted, 1) - 1) % uint64(len(s.shards))
+
+	s.shards[index].Submit(task)
+}
+
+// SubmitKeyed sends a task to the shard the given key is routed to (see Shard) for execution.
+// Note that tasks sharing a key may still run concurrently if the shard has more than one worker
+// (see KeyedExecutor to run them serially).
+func (s *ShardedPool) SubmitKeyed(key string, task func()) {
+	s.Shard(key).Submit(task)
+}
+
+// StopAndWait stops all the shards and waits for their queued tasks to complete
+func (s *ShardedPool) StopAndWait() {
+	for _, shard := range s.shards {
+		shard.StopAndWait()
+	}
+}
+
+// ShardStats returns a snapshot of the stats of each shard, in index order
+func (s *ShardedPool) ShardStats() []Stats {
+	stats := make([]Stats, len(s.shards))
+	for i, shard := range s.shards {
+		stats[i] = shard.StatsSnapshot()
+	}
+	return stats
+}
+
+// StatsSnapshot returns a snapshot of the stats of all shards combined: sizes, gauges, counters and histograms
+// are added up, while the remaining settings are those of the first shard. The name is left empty.
+func (s *ShardedPool) StatsSnapshot() Stats {
+	shards := s.ShardStats()
+
+	total := shards[0]
+	total.Name = ""
+	total.QueueWait = DurationHistogram{}
+	total.Labels = nil
+
+	for i, shard := range shards {
+		if i > 0 {
+			total.MinWorkers += shard.MinWorkers
+			total.MaxWorkers += shard.MaxWorkers
+			total.MaxCapacity += shard.MaxCapacity
+			total.RunningWorkers += shard.RunningWorkers
+			total.IdleWorkers += shard.IdleWorkers
+			total.QueueCap += shard.QueueCap
+			total.QueueLen += shard.QueueLen
+			total.Stopped = total.Stopped && shard.Stopped
+			total.SubmittedTasks += shard.SubmittedTasks
+			total.WaitingTasks += shard.WaitingTasks
+			total.SuccessfulTasks += shard.SuccessfulTasks
+			total.FailedTasks += shard.FailedTasks
+			total.ExpiredTasks += shard.ExpiredTasks
+			total.CompletedTasks += shard.CompletedTasks
+		}
+		total.QueueWait.merge(shard.QueueWait)
+
+		for label, stats := range shard.Labels {
+			if total.Labels == nil {
+				total.Labels = make(map[string]LabelStats)
+			}
+			merged := total.Labels[label]
+			merged.Successful += stats.Successful
+			merged.Failed += stats.Failed
+			merged.Expired += stats.Expired
+			merged.AllocatedBytes += stats.AllocatedBytes
+			merged.Durations.merge(stats.Durations)
+			total.Labels[label] = merged
+		}
+	}
+	return total
+}
+
+// jumpHash maps a key hash to one of the given number of buckets with Lamping and Veach's jump consistent hash,
+// which spreads keys evenly and only moves a minimal fraction of them if the number of buckets changes
+func jumpHash(key uint64, buckets int) int {
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
@@ -0,0 +1,84 @@
+package pond_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestShardedPoolKeyAffinity(t *testing.T) {
+
+	pool := pond.NewSharded(4, 2, pond.MaxCapacity(100))
+
+	used := make(map[*pond.WorkerPool]bool)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		shard := pool.Shard(key)
+
+		assertEqual(t, shard, pool.Shard(key))
+		used[shard] = true
+
+		pool.SubmitKeyed(key, func() {})
+	}
+	pool.StopAndWait()
+
+	// Keys are spread across all shards
+	assertEqual(t, 4, len(used))
+	assertEqual(t, 4, len(pool.Shards()))
+}
+
+func TestShardedPoolStats(t *testing.T) {
+
+	pool := pond.NewSharded(3, 2, pond.MaxCapacity(10), pond.Name("images"))
+
+	for i := 0; i < 30; i++ {
+		pool.Submit(func() {})
+	}
+	pool.StopAndWait()
+
+	shards := pool.ShardStats()
+	assertEqual(t, 3, len(shards))
+	for i, shard := range shards {
+		assertEqual(t, fmt.Sprintf("images-%d", i), shard.Name)
+		assertEqual(t, 2, shard.MaxWorkers)
+		assertEqual(t, 10, shard.MaxCapacity)
+		assertEqual(t, uint64(10), shard.SubmittedTasks)
+	}
+
+	total := pool.StatsSnapshot()
+	assertEqual(t, "", total.Name)
+	assertEqual(t, 6, total.MaxWorkers)
+	assertEqual(t, 30, total.MaxCapacity)
+	assertEqual(t, uint64(30), total.SubmittedTasks)
+	assertEqual(t, uint64(30), total.CompletedTasks)
+	assertEqual(t, uint64(30), total.QueueWait.Count)
+	assertEqual(t, true, total.Stopped)
+}
+
+func TestShardedPoolInvalidShards(t *testing.T) {
+
+	pool := pond.NewSharded(0, 1)
+	defer pool.StopAndWait()
+
+	assertEqual(t, 1, len(pool.Shards()))
+}
+
+func TestShardedPoolPinShards(t *testing.T) {
+
+	pool := pond.NewSharded(2, 1, pond.PinShards())
+
+	done := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		pool.Submit(func() {
+			done <- struct{}{}
+		})
+	}
+	<-done
+	<-done
+	pool.StopAndWait()
+
+	for _, shard := range pool.Shards() {
+		assertEqual(t, true, len(shard.Options().PinnedCPUs) > 0)
+	}
+}
@@ -0,0 +1,101 @@
+package pond
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Quiesce causes this pool to stop accepting new tasks, while letting the tasks being executed and those in the queue
+// run to completion. It returns immediately: use StopAndWait or Abort to wait for the pool to drain.
+func (p *WorkerPool) Quiesce() {
+	atomic.StoreInt32(&p.stopped, 1)
+}
+
+// Abort stops this pool and waits for its queued and running tasks to complete, for as long as ctx allows
+// (the grace period). If ctx is done first, the contexts passed to the tasks submitted with SubmitContext or
+// SubmitWithValues are canceled and queued tasks are discarded. Abort then waits for the running tasks to return,
+// so they must honor the cancellation of their context, and it returns ctx.Err(). It returns nil if all tasks
+// completed within the grace period.
+func (p *WorkerPool) Abort(ctx context.Context) error {
+	p.Quiesce()
+
+	stopped := make(chan struct{})
+	go func() {
+		p.stop(true)
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+	}
+
+	// Grace period is over, ask running tasks to return and drop queued ones
+	p.taskContextCancel()
+	p.closeQueue()
+
+	<-stopped
+	return ctx.Err()
+}
+
+// ShutdownReport describes how a worker pool was stopped by StopOnSignal
+type ShutdownReport struct {
+	// Signal is the signal that triggered the shutdown
+	Signal os.Signal
+	// Unfinished is the number of tasks that were still queued or running when the pool stopped
+	Unfinished uint64
+	// TimedOut is true if the deadline was reached before all tasks completed
+	TimedOut bool
+}
+
+// StopOnSignal stops the given pool when the process receives one of the given signals (SIGINT or SIGTERM
+// if none is specified), waiting up to timeout for queued and running tasks to complete (see StopAndWaitFor).
+// The returned channel receives a report once the pool has stopped. If the pool is stopped by other means first,
+// the channel is closed without a report.
+func StopOnSignal(pool *WorkerPool, timeout time.Duration, signals ...os.Signal) <-chan ShutdownReport {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, signals...)
+
+	reports := make(chan ShutdownReport, 1)
+	go func() {
+		defer close(reports)
+		defer signal.Stop(received)
+
+		if report, ok := stopOnSignal(pool, timeout, received); ok {
+			reports <- report
+		}
+	}()
+
+	return reports
+}
+
+// stopOnSignal waits for a signal on the given channel and stops the pool, returning false if the pool
+// was stopped before a signal was received
+func stopOnSignal(pool *WorkerPool, timeout time.Duration, received <-chan os.Signal) (ShutdownReport, bool) {
+	select {
+	case sig := <-received:
+		pool.StopAndWaitFor(timeout)
+
+		// Counters are read one at a time, so compute the difference in signed arithmetic in case they don't add up
+		unfinished := int64(pool.SubmittedTasks()) - int64(pool.CompletedTasks()) - int64(pool.ExpiredTasks())
+		if unfinished < 0 {
+			unfinished = 0
+		}
+		return ShutdownReport{
+			Signal:     sig,
+			Unfinished: uint64(unfinished),
+			TimedOut:   unfinished > 0,
+		}, true
+	case <-pool.context.Done():
+		return ShutdownReport{}, false
+	}
+}
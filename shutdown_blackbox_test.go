@@ -0,0 +1,56 @@
+package pond_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestQuiesceAndAbort(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	started := make(chan struct{})
+	var canceled int32
+	pool.SubmitContext(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		atomic.AddInt32(&canceled, 1)
+	})
+	<-started
+	for i := 0; i < 3; i++ {
+		pool.Submit(func() {})
+	}
+
+	pool.Quiesce()
+	assertEqual(t, true, pool.Stopped())
+	assertEqual(t, false, pool.TrySubmit(func() {}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// The running task only returns once its context is canceled, after the grace period
+	assertEqual(t, context.DeadlineExceeded, pool.Abort(ctx))
+	assertEqual(t, int32(1), atomic.LoadInt32(&canceled))
+	assertEqual(t, uint64(1), pool.CompletedTasks())
+}
+
+func TestAbortWithinGracePeriod(t *testing.T) {
+
+	pool := pond.New(2, 10)
+
+	var completed int32
+	for i := 0; i < 5; i++ {
+		pool.Submit(func() {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&completed, 1)
+		})
+	}
+
+	pool.Quiesce()
+	assertEqual(t, nil, pool.Abort(context.Background()))
+	assertEqual(t, int32(5), atomic.LoadInt32(&completed))
+}
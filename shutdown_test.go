@@ -0,0 +1,84 @@
+package pond
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestStopOnSignal(t *testing.T) {
+
+	pool := New(1, 10)
+
+	pool.Submit(func() {
+		time.Sleep(10 * time.Millisecond)
+	})
+
+	received := make(chan os.Signal, 1)
+	received <- syscall.SIGTERM
+
+	report, ok := stopOnSignal(pool, 1*time.Second, received)
+
+	assertEqual(t, true, ok)
+	assertEqual(t, syscall.SIGTERM, report.Signal)
+	assertEqual(t, uint64(0), report.Unfinished)
+	assertEqual(t, false, report.TimedOut)
+	assertEqual(t, true, pool.Stopped())
+	assertEqual(t, uint64(1), pool.SuccessfulTasks())
+}
+
+func TestStopOnSignalTimeout(t *testing.T) {
+
+	pool := New(1, 10)
+
+	release := make(chan struct{})
+	pool.Submit(func() {
+		<-release
+	})
+	pool.Submit(func() {})
+
+	received := make(chan os.Signal, 1)
+	received <- os.Interrupt
+
+	report, ok := stopOnSignal(pool, 10*time.Millisecond, received)
+	close(release)
+
+	assertEqual(t, true, ok)
+	assertEqual(t, uint64(2), report.Unfinished)
+	assertEqual(t, true, report.TimedOut)
+}
+
+func TestStopOnSignalWithKeyedExecutor(t *testing.T) {
+
+	pool := New(1, 10, PanicHandler(func(interface{}) {}))
+
+	executor := NewKeyedExecutor[string](pool)
+	executor.Submit("a", func() {
+		panic("boom")
+	})
+	executor.Submit("a", func() {})
+	executor.Wait()
+
+	received := make(chan os.Signal, 1)
+	received <- syscall.SIGTERM
+
+	report, ok := stopOnSignal(pool, 1*time.Second, received)
+
+	assertEqual(t, true, ok)
+	assertEqual(t, uint64(0), report.Unfinished)
+	assertEqual(t, false, report.TimedOut)
+	assertEqual(t, uint64(1), executor.FailedTasks())
+}
+
+func TestStopOnSignalAfterStop(t *testing.T) {
+
+	pool := New(1, 10)
+
+	reports := StopOnSignal(pool, 1*time.Second)
+
+	pool.StopAndWait()
+
+	_, ok := <-reports
+	assertEqual(t, false, ok)
+}
@@ -0,0 +1,30 @@
+package pond
+
+import "sort"
+
+// QueueSnapshot returns the metadata of up to max tasks waiting to start (all of them if max is not greater than 0),
+// oldest first, e.g. to find out what is stuck in the queue. Tasks waiting in the queue of a tenant (see SubmitTenant)
+// are included, with the tenant as their submitter. The queue is scanned while holding its lock, so it's meant for
+// debugging rather than for frequent polling.
+func (p *WorkerPool) QueueSnapshot(max int) []TaskInfo {
+	snapshot := p.tenants.waiting()
+	p.tasks.Each(func(task *queuedTask) bool {
+		// Skip the runners of tenant tasks, which are reported above
+		if !task.internal {
+			snapshot = append(snapshot, task.info)
+		}
+		return true
+	})
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		a, b := snapshot[i], snapshot[j]
+		if !a.SubmittedAt.Equal(b.SubmittedAt) {
+			return a.SubmittedAt.Before(b.SubmittedAt)
+		}
+		return a.ID < b.ID
+	})
+	if max > 0 && len(snapshot) > max {
+		snapshot = snapshot[:max]
+	}
+	return snapshot
+}
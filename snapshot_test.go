@@ -0,0 +1,47 @@
+package pond_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestQueueSnapshot(t *testing.T) {
+
+	clock := pond.NewManualClock(time.Unix(0, 0))
+	pool := pond.New(1, 10, pond.Deterministic(1, clock), pond.QueueOrder(pond.LIFO))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	assertEqual(t, 0, len(pool.QueueSnapshot(0)))
+
+	pool.SubmitLabeled("first", func() {})
+	clock.Advance(time.Second)
+	pool.SubmitLabeled("second", func() {})
+	clock.Advance(time.Second)
+	pool.SubmitTenant("acme", func() {})
+	clock.Advance(time.Second)
+	pool.SubmitLabeled("third", func() {})
+
+	// Oldest first, whatever the queue order
+	snapshot := pool.QueueSnapshot(0)
+	assertEqual(t, 4, len(snapshot))
+	assertEqual(t, "first", snapshot[0].Label)
+	assertEqual(t, "second", snapshot[1].Label)
+	assertEqual(t, "acme", snapshot[2].Submitter)
+	assertEqual(t, "third", snapshot[3].Label)
+	assertEqual(t, time.Unix(1, 0), snapshot[1].SubmittedAt)
+
+	snapshot = pool.QueueSnapshot(2)
+	assertEqual(t, 2, len(snapshot))
+	assertEqual(t, "second", snapshot[1].Label)
+
+	close(release)
+	pool.StopAndWait()
+}
@@ -0,0 +1,131 @@
+package pond
+
+import (
+	"sort"
+	"time"
+)
+
+// starvationChecks is the number of times the queue is checked for starving tasks per starvation threshold
+const starvationChecks = 4
+
+// Starvation describes a label or a tenant whose tasks have been waiting to start for longer than the starvation
+// threshold (see StarvationAlert)
+type Starvation struct {
+	// Label is the label of the starving tasks, if they are waiting in the pool's queue
+	Label string
+	// Tenant is the tenant of the starving tasks, if they are waiting in the queue of a tenant (see SubmitTenant)
+	Tenant string
+	// Oldest is the metadata of the starving task that has been waiting the longest
+	Oldest TaskInfo
+	// Waited is how long the oldest task has been waiting
+	Waited time.Duration
+	// Starving is the number of tasks that have been waiting for longer than the threshold
+	Starving int
+}
+
+// StarvationAlert makes a worker pool check regularly (4 times per threshold) whether queued tasks have been waiting
+// to start for longer than the given threshold, and invoke handler for each label and tenant that has such tasks,
+// on every check until they start or are dropped. This way, fairness misconfigurations (e.g. label weights or lanes
+// that starve some tasks) are noticed as they happen. The handler is invoked by a dedicated goroutine.
+func StarvationAlert(threshold time.Duration, handler func(Starvation)) Option {
+	return func(pool *WorkerPool) {
+		pool.starvation = &starvationMonitor{
+			threshold: threshold,
+			handler:   handler,
+		}
+	}
+}
+
+// starvationMonitor holds the settings of starvation alerts
+type starvationMonitor struct {
+	threshold time.Duration
+	handler   func(Starvation)
+}
+
+// watchStarvation represents the work done by the goroutine that checks regularly for starving tasks
+func (p *WorkerPool) watchStarvation() {
+	defer p.workersWaitGroup.Done()
+
+	interval := p.starvation.threshold / starvationChecks
+	if interval <= 0 {
+		interval = p.starvation.threshold
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, starvation := range p.starving(p.now()) {
+				p.starvation.handler(starvation)
+			}
+		case <-p.context.Done():
+			return
+		}
+	}
+}
+
+// starving returns the labels and tenants that have tasks waiting for longer than the starvation threshold at the
+// given time, sorted by label and then by tenant
+func (p *WorkerPool) starving(now time.Time) []Starvation {
+	threshold := p.starvation.threshold
+
+	byLabel := make(map[string]*Starvation)
+	p.tasks.Each(func(task *queuedTask) bool {
+		waited := now.Sub(task.info.SubmittedAt)
+		// Skip the runners of tenant tasks, which are checked below
+		if waited <= threshold || task.internal {
+			return true
+		}
+		starvation, ok := byLabel[task.info.Label]
+		if !ok {
+			starvation = &Starvation{Label: task.info.Label}
+			byLabel[task.info.Label] = starvation
+		}
+		starvation.Starving++
+		if waited > starvation.Waited {
+			starvation.Oldest, starvation.Waited = task.info, waited
+		}
+		return true
+	})
+
+	starving := make([]Starvation, 0, len(byLabel))
+	for _, starvation := range byLabel {
+		starving = append(starving, *starvation)
+	}
+	sort.Slice(starving, func(i, j int) bool {
+		return starving[i].Label < starving[j].Label
+	})
+
+	return append(starving, p.tenants.starving(threshold, now)...)
+}
+
+// starving returns the tenants that have tasks waiting for longer than the given threshold, sorted by tenant
+func (s *tenantScheduler) starving(threshold time.Duration, now time.Time) []Starvation {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var starving []Starvation
+	for id, t := range s.tenants {
+		// Tenant queues are FIFO, so the oldest task is the first one
+		if len(t.queue) == 0 || now.Sub(t.queue[0].submittedAt) <= threshold {
+			continue
+		}
+
+		count := 0
+		for count < len(t.queue) && now.Sub(t.queue[count].submittedAt) > threshold {
+			count++
+		}
+		starving = append(starving, Starvation{
+			Tenant:   id,
+			Oldest:   s.infoOf(t, t.queue[0]),
+			Waited:   now.Sub(t.queue[0].submittedAt),
+			Starving: count,
+		})
+	}
+	sort.Slice(starving, func(i, j int) bool {
+		return starving[i].Tenant < starving[j].Tenant
+	})
+
+	return starving
+}
@@ -0,0 +1,81 @@
+package pond_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestStarvationAlert(t *testing.T) {
+
+	alerts := make(chan pond.Starvation, 100)
+	pool := pond.New(1, 10, pond.MinWorkers(1), pond.StarvationAlert(20*time.Millisecond, func(starvation pond.Starvation) {
+		select {
+		case alerts <- starvation:
+		default:
+		}
+	}))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	pool.SubmitLabeled("reports", func() {})
+	pool.SubmitLabeled("reports", func() {})
+
+	starvation := <-alerts
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, "reports", starvation.Label)
+	assertEqual(t, "", starvation.Tenant)
+	assertEqual(t, 2, starvation.Starving)
+	assertEqual(t, "reports", starvation.Oldest.Label)
+	assertEqual(t, true, starvation.Waited > 20*time.Millisecond)
+	assertEqual(t, 20*time.Millisecond, pool.Options().StarvationThreshold)
+}
+
+func TestStarvationAlertTenant(t *testing.T) {
+
+	alerts := make(chan pond.Starvation, 100)
+	pool := pond.New(2, 10,
+		pond.DefaultTenantQuota(pond.TenantQuota{MaxConcurrency: 1}),
+		pond.StarvationAlert(20*time.Millisecond, func(starvation pond.Starvation) {
+			select {
+			case alerts <- starvation:
+			default:
+			}
+		}))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.SubmitTenant("noisy", func() {
+		close(started)
+		<-release
+	})
+	<-started
+	pool.SubmitTenant("noisy", func() {})
+
+	starvation := <-alerts
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, "noisy", starvation.Tenant)
+	assertEqual(t, "noisy", starvation.Oldest.Submitter)
+	assertEqual(t, 1, starvation.Starving)
+	assertEqual(t, true, starvation.Waited > 20*time.Millisecond)
+}
+
+func TestStarvationAlertValidation(t *testing.T) {
+
+	_, err := pond.NewWithOptions(1, 10, pond.StarvationAlert(0, func(pond.Starvation) {}))
+	assertEqual(t, "invalid worker pool configuration: starvation threshold must be greater than 0, got 0s", err.Error())
+
+	_, err = pond.NewWithOptions(1, 10, pond.StarvationAlert(time.Second, nil))
+	assertEqual(t, "invalid worker pool configuration: starvation handler must not be nil", err.Error())
+}
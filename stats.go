@@ -0,0 +1,118 @@
+package pond
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a point-in-time snapshot of the counters, gauges and configuration of a worker pool.
+// It can be encoded as JSON, e.g. to be served from a health endpoint or written to a log line.
+type Stats struct {
+	// Configuration
+	Name        string            `json:"name,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	MinWorkers  int               `json:"minWorkers"`
+	MaxWorkers  int               `json:"maxWorkers"`
+	MaxCapacity int               `json:"maxCapacity"`
+	IdleTimeout time.Duration     `json:"idleTimeout"`
+	MaxQueueAge time.Duration     `json:"maxQueueAge,omitempty"`
+	QueueOrder  string            `json:"queueOrder"`
+
+	// Gauges
+	RunningWorkers int  `json:"runningWorkers"`
+	IdleWorkers    int  `json:"idleWorkers"`
+	QueueCap       int  `json:"queueCap"`
+	QueueLen       int  `json:"queueLen"`
+	Stopped        bool `json:"stopped"`
+	// OldestWaiting is how long the oldest waiting task has been waiting to start (see OldestWaiting)
+	OldestWaiting time.Duration `json:"oldestWaiting"`
+	// Saturation is how saturated the pool is, between 0 and 1 (see Saturation)
+	Saturation float64 `json:"saturation"`
+
+	// Counters
+	SubmittedTasks  uint64 `json:"submittedTasks"`
+	WaitingTasks    uint64 `json:"waitingTasks"`
+	SuccessfulTasks uint64 `json:"successfulTasks"`
+	FailedTasks     uint64 `json:"failedTasks"`
+	ExpiredTasks    uint64 `json:"expiredTasks"`
+	CompletedTasks  uint64 `json:"completedTasks"`
+
+	// QueueWait tracks how long tasks waited in the queue before a worker picked them up
+	QueueWait DurationHistogram `json:"queueWait"`
+
+	// Labels holds the statistics of labeled tasks (see LabelStats)
+	Labels map[string]LabelStats `json:"labels,omitempty"`
+}
+
+// StatsSnapshot returns a snapshot of the counters, gauges and configuration of this pool.
+// Values are read individually, so they might not be mutually consistent while tasks are running.
+func (p *WorkerPool) StatsSnapshot() Stats {
+	return Stats{
+		Name:            p.name,
+		Tags:            p.Tags(),
+		MinWorkers:      p.MinWorkers(),
+		MaxWorkers:      p.MaxWorkers(),
+		MaxCapacity:     p.MaxCapacity(),
+		IdleTimeout:     p.idleTimeout,
+		MaxQueueAge:     p.maxQueueAge,
+		QueueOrder:      p.queueOrder.String(),
+		RunningWorkers:  p.RunningWorkers(),
+		IdleWorkers:     p.IdleWorkers(),
+		QueueCap:        p.QueueCap(),
+		QueueLen:        p.QueueLen(),
+		Stopped:         p.Stopped(),
+		OldestWaiting:   p.OldestWaiting(),
+		Saturation:      p.Saturation(),
+		SubmittedTasks:  p.SubmittedTasks(),
+		WaitingTasks:    p.WaitingTasks(),
+		SuccessfulTasks: p.SuccessfulTasks(),
+		FailedTasks:     p.FailedTasks(),
+		ExpiredTasks:    p.ExpiredTasks(),
+		CompletedTasks:  p.CompletedTasks(),
+		QueueWait:       p.queueWait.snapshot(),
+		Labels:          p.LabelStats(),
+	}
+}
+
+// String returns a human-readable summary of the stats, e.g. "pond: 12 running, 340 queued, p99 queue wait 1.2s"
+func (s Stats) String() string {
+	prefix := "pond"
+	if s.Name != "" {
+		prefix = fmt.Sprintf("pond[%s]", s.Name)
+	}
+
+	return fmt.Sprintf("%s: %d running, %d queued, p99 queue wait %v", prefix, s.RunningWorkers, s.WaitingTasks, s.QueueWait.Quantile(0.99))
+}
+
+// waitHistogram is a lock-free histogram of the time tasks spend waiting in the queue.
+// Its 64-bit counters are placed first so they are 64-bit aligned on 32-bit platforms.
+type waitHistogram struct {
+	count  uint64
+	sum    int64
+	counts []uint64
+}
+
+func newWaitHistogram() *waitHistogram {
+	return &waitHistogram{
+		counts: make([]uint64, len(DefaultDurationBuckets)+1),
+	}
+}
+
+// observe records the time a task spent in the queue
+func (h *waitHistogram) observe(wait time.Duration) {
+	atomic.AddUint64(&h.counts[durationBucket(DefaultDurationBuckets, wait)], 1)
+	atomic.AddUint64(&h.count, 1)
+	atomic.AddInt64(&h.sum, int64(wait))
+}
+
+// snapshot returns a copy of the histogram. Values are read individually, so they might not be mutually consistent.
+func (h *waitHistogram) snapshot() DurationHistogram {
+	snapshot := newDurationHistogram(DefaultDurationBuckets)
+	for i := range h.counts {
+		snapshot.Counts[i] = atomic.LoadUint64(&h.counts[i])
+	}
+	snapshot.Count = atomic.LoadUint64(&h.count)
+	snapshot.Sum = time.Duration(atomic.LoadInt64(&h.sum))
+	return snapshot
+}
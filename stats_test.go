@@ -0,0 +1,124 @@
+package pond_test
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestStatsSnapshot(t *testing.T) {
+
+	pool := pond.New(2, 10, pond.Name("reports"), pond.IdleTimeout(1*time.Second), pond.QueueOrder(pond.LIFO))
+
+	pool.SubmitLabeled("render", func() {})
+	pool.Submit(func() {
+		panic("boom")
+	})
+
+	pool.StopAndWait()
+
+	stats := pool.StatsSnapshot()
+
+	assertEqual(t, "reports", stats.Name)
+	assertEqual(t, 2, stats.MaxWorkers)
+	assertEqual(t, 10, stats.MaxCapacity)
+	assertEqual(t, 1*time.Second, stats.IdleTimeout)
+	assertEqual(t, "LIFO", stats.QueueOrder)
+	assertEqual(t, true, stats.Stopped)
+	assertEqual(t, uint64(2), stats.SubmittedTasks)
+	assertEqual(t, uint64(1), stats.SuccessfulTasks)
+	assertEqual(t, uint64(1), stats.FailedTasks)
+	assertEqual(t, uint64(2), stats.CompletedTasks)
+	assertEqual(t, uint64(1), stats.Labels["render"].Successful)
+
+	encoded, err := json.Marshal(stats)
+	assertEqual(t, nil, err)
+
+	var decoded map[string]interface{}
+	assertEqual(t, nil, json.Unmarshal(encoded, &decoded))
+	assertEqual(t, "reports", decoded["name"])
+	assertEqual(t, float64(2), decoded["submittedTasks"])
+	assertEqual(t, "LIFO", decoded["queueOrder"])
+}
+
+func TestStatsString(t *testing.T) {
+
+	stats := pond.Stats{
+		Name:           "reports",
+		RunningWorkers: 12,
+		WaitingTasks:   340,
+		QueueWait: pond.DurationHistogram{
+			Bounds: []time.Duration{100 * time.Millisecond, 1 * time.Second},
+			Counts: []uint64{98, 2, 0},
+			Count:  100,
+		},
+	}
+
+	assertEqual(t, "pond[reports]: 12 running, 340 queued, p99 queue wait 1s", stats.String())
+}
+
+func TestDurationHistogramQuantile(t *testing.T) {
+
+	histogram := pond.DurationHistogram{
+		Bounds: []time.Duration{1 * time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond},
+		Counts: []uint64{50, 40, 9, 1},
+		Count:  100,
+	}
+
+	assertEqual(t, 1*time.Millisecond, histogram.Quantile(0.5))
+	assertEqual(t, 10*time.Millisecond, histogram.Quantile(0.9))
+	assertEqual(t, 100*time.Millisecond, histogram.Quantile(0.99))
+	assertEqual(t, 100*time.Millisecond, histogram.Quantile(1))
+	assertEqual(t, time.Duration(0), pond.DurationHistogram{}.Quantile(0.99))
+}
+
+func TestReportEvery(t *testing.T) {
+
+	var mutex sync.Mutex
+	var reports []pond.Stats
+	pool := pond.New(1, 10, pond.ReportEvery(1*time.Hour, func(stats pond.Stats) {
+		mutex.Lock()
+		reports = append(reports, stats)
+		mutex.Unlock()
+	}))
+
+	pool.SubmitAndWait(func() {})
+
+	pool.StopAndWait()
+
+	// Stats are reported once more when the pool stops
+	assertEqual(t, 1, len(reports))
+	assertEqual(t, uint64(1), reports[0].SuccessfulTasks)
+	assertEqual(t, uint64(1), reports[0].QueueWait.Count)
+}
+
+func TestOldestWaiting(t *testing.T) {
+
+	clock := pond.NewManualClock(time.Unix(0, 0))
+	pool := pond.New(1, 10, pond.Deterministic(1, clock))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	assertEqual(t, time.Duration(0), pool.OldestWaiting())
+
+	pool.Submit(func() {})
+	clock.Advance(2 * time.Second)
+	pool.Submit(func() {})
+	clock.Advance(1 * time.Second)
+
+	assertEqual(t, 3*time.Second, pool.OldestWaiting())
+	assertEqual(t, 3*time.Second, pool.StatsSnapshot().OldestWaiting)
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, time.Duration(0), pool.OldestWaiting())
+}
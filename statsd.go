@@ -0,0 +1,106 @@
+package pond
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// statsDMaxPacketSize is the maximum size of the packets sent to a StatsD server, which keeps them
+	// below the typical MTU of 1500 bytes once IP and UDP headers are added
+	statsDMaxPacketSize = 1432
+)
+
+// StatsDSink is a MetricsSink that sends metrics to a StatsD server over UDP. Tags are sent using the
+// DogStatsD format ("|#tag1,tag2"), which is understood by Datadog agents and ignored by most StatsD servers.
+// Metrics are buffered until the sink is flushed or a packet is full.
+type StatsDSink struct {
+	conn   net.Conn
+	prefix string
+	buffer bytes.Buffer
+	mutex  sync.Mutex
+}
+
+// NewStatsDSink creates a StatsD sink that sends metrics to the server listening on the given UDP address
+// (e.g. "127.0.0.1:8125"). The prefix, if not empty, is prepended to the name of every metric (e.g. "myapp.pond.").
+func NewStatsDSink(address, prefix string) (*StatsDSink, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatsDSink{
+		conn:   conn,
+		prefix: prefix,
+	}, nil
+}
+
+// Count adds the given delta to a counter
+func (s *StatsDSink) Count(name string, delta int64, tags []string) {
+	s.write(name, strconv.FormatInt(delta, 10), "c", tags)
+}
+
+// Gauge sets the current value of a gauge
+func (s *StatsDSink) Gauge(name string, value float64, tags []string) {
+	s.write(name, strconv.FormatFloat(value, 'f', -1, 64), "g", tags)
+}
+
+// Timing records a duration, in milliseconds
+func (s *StatsDSink) Timing(name string, duration time.Duration, tags []string) {
+	s.write(name, strconv.FormatFloat(float64(duration)/float64(time.Millisecond), 'f', -1, 64), "ms", tags)
+}
+
+// Flush sends all buffered metrics to the server
+func (s *StatsDSink) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.flush()
+}
+
+// Close flushes all buffered metrics and closes the connection to the server
+func (s *StatsDSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.flush()
+	if closeErr := s.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// write appends a metric to the buffer, sending the buffered metrics first if the packet would grow too large
+func (s *StatsDSink) write(name, value, metricType string, tags []string) {
+	line := s.prefix + name + ":" + value + "|" + metricType
+	if len(tags) > 0 {
+		line += "|#" + strings.Join(tags, ",")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.buffer.Len() > 0 && s.buffer.Len()+1+len(line) > statsDMaxPacketSize {
+		// Metrics are sent on a best-effort basis
+		s.flush()
+	}
+	if s.buffer.Len() > 0 {
+		s.buffer.WriteByte('\n')
+	}
+	s.buffer.WriteString(line)
+}
+
+// flush sends the buffered metrics in a single packet. It must be called while holding the mutex.
+func (s *StatsDSink) flush() error {
+	if s.buffer.Len() == 0 {
+		return nil
+	}
+	defer s.buffer.Reset()
+
+	_, err := s.conn.Write(s.buffer.Bytes())
+	return err
+}
@@ -0,0 +1,100 @@
+package pond_test
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+// The stress tests below exercise the pool under concurrent submissions, resizing, panics, expirations and shutdown.
+// Run them with -race -tags pond_debug to also check the pool's internal invariants as they run.
+
+func TestStressSubmitAndResize(t *testing.T) {
+
+	var dropped uint64
+	pool := pond.New(8, 16,
+		pond.MinWorkers(1),
+		pond.IdleTimeout(time.Millisecond),
+		pond.MaxQueueAge(time.Millisecond),
+		pond.PanicHandler(func(interface{}) {}),
+		pond.DroppedTaskHandler(func(reason pond.DropReason, info pond.TaskInfo) {
+			if reason == pond.DropQueueFull {
+				atomic.AddUint64(&dropped, 1)
+			}
+		}))
+
+	var rejected uint64
+	var submitters sync.WaitGroup
+	for s := 0; s < 8; s++ {
+		submitters.Add(1)
+		go func(s int) {
+			defer submitters.Done()
+
+			r := rand.New(rand.NewSource(int64(s)))
+			for i := 0; i < 500; i++ {
+				work := time.Duration(r.Intn(100)) * time.Microsecond
+				task := func() {
+					time.Sleep(work)
+				}
+
+				switch r.Intn(5) {
+				case 0:
+					if !pool.TrySubmit(task) {
+						atomic.AddUint64(&rejected, 1)
+					}
+				case 1:
+					pool.Submit(func() {
+						panic("stress")
+					})
+				case 2:
+					pool.SubmitAll([]func(){task, task, task})
+				case 3:
+					pool.SubmitBefore(task, work)
+				default:
+					pool.Submit(task)
+				}
+			}
+		}(s)
+	}
+	submitters.Wait()
+	pool.StopAndWait()
+
+	assertEqual(t, 0, pool.RunningWorkers())
+	assertEqual(t, uint64(0), pool.WaitingTasks())
+	assertEqual(t, pool.SubmittedTasks(), pool.CompletedTasks()+pool.ExpiredTasks())
+	assertEqual(t, rejected, atomic.LoadUint64(&dropped))
+}
+
+func TestStressStopDiscarding(t *testing.T) {
+
+	for round := 0; round < 20; round++ {
+		var dropped uint64
+		pool := pond.New(4, 1000, pond.DroppedTaskHandler(func(reason pond.DropReason, info pond.TaskInfo) {
+			atomic.AddUint64(&dropped, 1)
+		}))
+
+		var submitters sync.WaitGroup
+		for s := 0; s < 4; s++ {
+			submitters.Add(1)
+			go func() {
+				defer submitters.Done()
+
+				for i := 0; i < 100; i++ {
+					pool.Submit(func() {
+						time.Sleep(10 * time.Microsecond)
+					})
+				}
+			}()
+		}
+		submitters.Wait()
+
+		discarded := pool.StopDiscarding()
+
+		assertEqual(t, uint64(discarded), atomic.LoadUint64(&dropped))
+		assertEqual(t, pool.SubmittedTasks(), pool.CompletedTasks()+uint64(discarded))
+	}
+}
@@ -0,0 +1,220 @@
+package pond
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Priority determines where a task submitted with SubmitTask is queued
+type Priority int
+
+const (
+	// NormalPriority queues the task like Submit
+	NormalPriority Priority = iota
+	// HighPriority queues the task ahead of the waiting ones, like SubmitFront
+	HighPriority
+	// LowPriority queues the task in the batch lane in two-lane mode (see SubmitBatch), and like Submit otherwise
+	LowPriority
+)
+
+// Task describes a task submitted with SubmitTask, along with the options that apply to it only
+type Task struct {
+	// Fn is the function to run. Its context carries the metadata of the task (see FromContext) and is canceled
+	// if the pool is aborted or once the timeout of the attempt expires.
+	Fn func(ctx context.Context) error
+	// Label identifies the kind of task, as given to SubmitLabeled
+	Label string
+	// Submitter identifies who submitted the task, as given to SubmitFrom
+	Submitter string
+	// Key serializes the task with the other tasks submitted with the same key: it's only sent to the pool once
+	// the previous one (including its retries) is done, so they run one at a time, in submission order
+	// (see KeyedExecutor). Tasks with an empty key are not serialized.
+	Key string
+	// Priority determines where the task is queued
+	Priority Priority
+	// Deadline is the time by which the task must start executing (see SubmitWithDeadline), or the zero time
+	Deadline time.Time
+	// Timeout is how long each attempt can run before its context is canceled, or 0 if it's not limited
+	Timeout time.Duration
+	// Retries is the number of times the task is retried if Fn returns an error. Each attempt is submitted as a separate
+	// task (see RetryPolicy) after a delay of Backoff, which is doubled after each retry.
+	Retries int
+	Backoff time.Duration
+}
+
+// SubmitTask sends a task to this worker pool for execution with the given options, which saves a method variant
+// per combination of options, and returns a Future that holds the error of its last attempt once it's done.
+// If the task is not executed, the Future holds why: ErrSubmitOnStoppedPool, ErrSubmitterLimitReached, the error
+// of the submit hook that vetoed it, or ErrTaskDropped if it was dropped from the queue (e.g. its deadline passed).
+// If the task panics, the Future holds a PanicError and the panic is handled by the pool's panic handler as well.
+func (p *WorkerPool) SubmitTask(task Task) *Future[struct{}] {
+	future := &Future[struct{}]{
+		done: make(chan struct{}),
+	}
+	if task.Fn == nil {
+		close(future.done)
+		return future
+	}
+
+	retry := &RetryPolicy{
+		MaxAttempts: task.Retries + 1,
+		Backoff:     task.Backoff,
+	}
+	start := func() {
+		p.submitTaskAttempt(task, retry, future, 1)
+	}
+	if task.Key == "" {
+		start()
+	} else {
+		p.taskKeys.start(task.Key, start)
+	}
+
+	return future
+}
+
+// completeTask completes the future of a task sent with SubmitTask and starts the next task with the same key, if any
+func (p *WorkerPool) completeTask(task Task, future *Future[struct{}], err error, info TaskInfo) {
+	future.complete(struct{}{}, err, info)
+
+	if task.Key != "" {
+		p.taskKeys.done(task.Key)
+	}
+}
+
+// submitTaskAttempt submits the given attempt of a task sent with SubmitTask, completing its future if it's not
+// accepted
+func (p *WorkerPool) submitTaskAttempt(task Task, retry *RetryPolicy, future *Future[struct{}], attempt int) {
+	queued := newQueuedTask(nil)
+	queued.info.Label = task.Label
+	queued.info.Submitter = task.Submitter
+	queued.info.Deadline = task.Deadline
+	queued.info.Attempt = attempt
+	switch task.Priority {
+	case HighPriority:
+		queued.front = true
+	case LowPriority:
+		queued.info.Lane = BatchLane
+	}
+
+	complete := func(err error) {
+		p.completeTask(task, future, err, queued.info)
+	}
+
+	if p.Stopped() {
+		complete(ErrSubmitOnStoppedPool)
+		return
+	}
+	if task.Submitter != "" && !p.submitters.acquire(task.Submitter) {
+		complete(ErrSubmitterLimitReached)
+		return
+	}
+
+	queued.onReject = complete
+	queued.onDiscard = func(error) {
+		complete(ErrTaskDropped)
+	}
+
+	queued.run = func() {
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if task.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(withTaskInfo(p.taskContext, queued), task.Timeout)
+		} else {
+			ctx, cancel = context.WithCancel(withTaskInfo(p.taskContext, queued))
+		}
+		defer cancel()
+
+		completed := false
+		defer func() {
+			if !completed {
+				// Report the panic as the error of the task and let the pool handle it
+				value := recover()
+				complete(newPanicError(value))
+				panic(value)
+			}
+		}()
+
+		err := task.Fn(ctx)
+		completed = true
+
+		if err != nil && retry.shouldRetry(err, attempt) {
+			go p.retryTask(task, retry, future, attempt+1, err, queued.info)
+			return
+		}
+		complete(err)
+	}
+
+	defer func() {
+		if value := recover(); value != nil {
+			if value != ErrSubmitOnStoppedPool {
+				panic(value)
+			}
+			// Pool was stopped while waiting for room in the queue
+			complete(ErrSubmitOnStoppedPool)
+		}
+	}()
+
+	p.submit(queued, true)
+}
+
+// retryTask waits for the backoff delay of the given attempt of a task sent with SubmitTask and then submits it.
+// If the pool is aborted while waiting, the outcome of the previous attempt is reported instead.
+func (p *WorkerPool) retryTask(task Task, retry *RetryPolicy, future *Future[struct{}], attempt int, lastErr error, lastInfo TaskInfo) {
+	if delay := retry.backoff(attempt); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-p.taskContext.Done():
+			p.completeTask(task, future, lastErr, lastInfo)
+			return
+		}
+	}
+
+	p.submitTaskAttempt(task, retry, future, attempt)
+}
+
+// taskKeys serializes the tasks sent with SubmitTask that share a key
+type taskKeys struct {
+	// Tasks waiting for the one in flight to be done, by key. A key is present as long as one of its tasks is in flight.
+	waiting map[string][]func()
+	mutex   sync.Mutex
+}
+
+// start invokes the given function to submit a task with the given key, or defers it until the task with the same
+// key that is in flight is done
+func (k *taskKeys) start(key string, submit func()) {
+	k.mutex.Lock()
+	if queue, inFlight := k.waiting[key]; inFlight {
+		k.waiting[key] = append(queue, submit)
+		k.mutex.Unlock()
+		return
+	}
+	if k.waiting == nil {
+		k.waiting = make(map[string][]func())
+	}
+	k.waiting[key] = nil
+	k.mutex.Unlock()
+
+	submit()
+}
+
+// done submits the next task with the given key, now that the one in flight is done. It's submitted from another
+// goroutine, since done is usually called by a worker, which must not block waiting for room in the queue.
+func (k *taskKeys) done(key string) {
+	k.mutex.Lock()
+	queue := k.waiting[key]
+	if len(queue) == 0 {
+		delete(k.waiting, key)
+		k.mutex.Unlock()
+		return
+	}
+	submit := queue[0]
+	queue[0] = nil
+	k.waiting[key] = queue[1:]
+	k.mutex.Unlock()
+
+	go submit()
+}
@@ -0,0 +1,203 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestSubmitTask(t *testing.T) {
+
+	pool := pond.New(1, 10)
+	defer pool.StopAndWait()
+
+	var info pond.TaskInfo
+	future := pool.SubmitTask(pond.Task{
+		Fn: func(ctx context.Context) error {
+			info, _ = pond.FromContext(ctx)
+			return nil
+		},
+		Label:     "sync",
+		Submitter: "alice",
+	})
+
+	_, err := future.Wait()
+	assertEqual(t, nil, err)
+	assertEqual(t, "sync", info.Label)
+	assertEqual(t, "alice", info.Submitter)
+	assertEqual(t, 1, info.Attempt)
+}
+
+func TestSubmitTaskRetries(t *testing.T) {
+
+	pool := pond.New(1, 10)
+	defer pool.StopAndWait()
+
+	errFlaky := errors.New("flaky")
+	var attempts []int
+	future := pool.SubmitTask(pond.Task{
+		Fn: func(ctx context.Context) error {
+			info, _ := pond.FromContext(ctx)
+			attempts = append(attempts, info.Attempt)
+			return errFlaky
+		},
+		Retries: 2,
+		Backoff: time.Millisecond,
+	})
+
+	_, err := future.Wait()
+	assertEqual(t, errFlaky, err)
+	assertEqual(t, 3, len(attempts))
+	assertEqual(t, 3, attempts[2])
+}
+
+func TestSubmitTaskTimeout(t *testing.T) {
+
+	pool := pond.New(1, 10)
+	defer pool.StopAndWait()
+
+	future := pool.SubmitTask(pond.Task{
+		Fn: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		Timeout: 10 * time.Millisecond,
+	})
+
+	_, err := future.Wait()
+	assertEqual(t, context.DeadlineExceeded, err)
+}
+
+func TestSubmitTaskPriority(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MinWorkers(1), pond.Lanes(0))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var executed strings.Builder
+	var mutex sync.Mutex
+	submit := func(name string, priority pond.Priority) *pond.Future[struct{}] {
+		return pool.SubmitTask(pond.Task{
+			Fn: func(ctx context.Context) error {
+				mutex.Lock()
+				executed.WriteString(name)
+				mutex.Unlock()
+				return nil
+			},
+			Priority: priority,
+		})
+	}
+	submit("l", pond.LowPriority)
+	submit("n", pond.NormalPriority)
+	last := submit("h", pond.HighPriority)
+
+	close(release)
+	last.Wait()
+	pool.StopAndWait()
+
+	assertEqual(t, "hnl", executed.String())
+}
+
+func TestSubmitTaskNotExecuted(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MinWorkers(1))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	expired := pool.SubmitTask(pond.Task{
+		Fn:       func(ctx context.Context) error { return nil },
+		Deadline: time.Now(),
+	})
+	panicked := pool.SubmitTask(pond.Task{
+		Fn: func(ctx context.Context) error { panic("boom") },
+	})
+
+	time.Sleep(5 * time.Millisecond)
+	close(release)
+
+	_, err := expired.Wait()
+	assertEqual(t, pond.ErrTaskDropped, err)
+
+	_, err = panicked.Wait()
+	var panicErr *pond.PanicError
+	assertEqual(t, true, errors.As(err, &panicErr))
+
+	pool.StopAndWait()
+
+	_, err = pool.SubmitTask(pond.Task{
+		Fn: func(ctx context.Context) error { return nil },
+	}).Wait()
+	assertEqual(t, pond.ErrSubmitOnStoppedPool, err)
+}
+
+func TestSubmitTaskKey(t *testing.T) {
+
+	pool := pond.New(4, 10)
+	defer pool.StopAndWait()
+
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	var order []int
+	release := make(chan struct{})
+
+	var futures []*pond.Future[struct{}]
+	for i := 0; i < 5; i++ {
+		i := i
+		futures = append(futures, pool.SubmitTask(pond.Task{
+			Fn: func(ctx context.Context) error {
+				mutex.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				order = append(order, i)
+				mutex.Unlock()
+
+				if i == 0 {
+					<-release
+				}
+				time.Sleep(time.Millisecond)
+
+				mutex.Lock()
+				running--
+				mutex.Unlock()
+				return nil
+			},
+			Key: "account-1",
+		}))
+	}
+
+	// Tasks with another key are not held back
+	other := pool.SubmitTask(pond.Task{
+		Fn: func(ctx context.Context) error {
+			return nil
+		},
+		Key: "account-2",
+	})
+	assertEqual(t, nil, other.Err())
+	close(release)
+
+	for _, future := range futures {
+		assertEqual(t, nil, future.Err())
+	}
+	assertEqual(t, 1, maxRunning)
+	assertEqual(t, "[0 1 2 3 4]", fmt.Sprint(order))
+}
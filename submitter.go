@@ -0,0 +1,88 @@
+package pond
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrSubmitterLimitReached is returned when attempting to submit a task on behalf of a submitter that already has
+	// the maximum number of tasks waiting to start (see MaxQueuedPerSubmitter)
+	ErrSubmitterLimitReached = errors.New("submitter has reached its maximum number of queued tasks")
+)
+
+// MaxQueuedPerSubmitter allows to limit the number of tasks that a single submitter can have waiting to start
+// (see SubmitFrom), so a runaway producer cannot fill the whole queue at the expense of the others.
+// A value of 0 (the default) means no limit.
+func MaxQueuedPerSubmitter(maxQueued int) Option {
+	return func(pool *WorkerPool) {
+		pool.submitters = &submitterLimiter{
+			maxQueued: maxQueued,
+			queued:    make(map[string]int),
+		}
+	}
+}
+
+// submitterLimiter tracks the number of tasks each submitter has waiting to start
+type submitterLimiter struct {
+	maxQueued int
+	queued    map[string]int
+	mutex     sync.Mutex
+}
+
+// acquire reserves a slot for a task of the given submitter, returning false if it has reached the limit.
+// Tasks without a submitter are not limited.
+func (l *submitterLimiter) acquire(submitter string) bool {
+	if l == nil || l.maxQueued <= 0 || submitter == "" {
+		return true
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.queued[submitter] >= l.maxQueued {
+		return false
+	}
+	l.queued[submitter]++
+	return true
+}
+
+// release frees the slot of a task of the given submitter that has started (or will never start)
+func (l *submitterLimiter) release(submitter string) {
+	if l == nil || l.maxQueued <= 0 || submitter == "" {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.queued[submitter] <= 1 {
+		delete(l.queued, submitter)
+		return
+	}
+	l.queued[submitter]--
+}
+
+// SubmitFrom sends a task on behalf of the given submitter (e.g. a client or producer ID) to this worker pool
+// for execution, just like Submit. If the pool limits the number of tasks each submitter can have waiting to start
+// (see MaxQueuedPerSubmitter), it returns ErrSubmitterLimitReached without submitting the task when the limit is reached.
+// Tasks submitted on behalf of the empty submitter are not limited. It returns ErrSubmitOnStoppedPool if the pool
+// has been stopped, and the error of the submit hook that vetoed the task, if any (see SubmitHooks).
+func (p *WorkerPool) SubmitFrom(submitter string, task func()) error {
+	if task == nil {
+		return nil
+	}
+
+	if p.Stopped() {
+		return ErrSubmitOnStoppedPool
+	}
+
+	if !p.submitters.acquire(submitter) {
+		return ErrSubmitterLimitReached
+	}
+
+	queued := newQueuedTask(task)
+	queued.info.Submitter = submitter
+
+	return p.submitUnlessStopped(queued)
+}
@@ -0,0 +1,108 @@
+package pond_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestSubmitFrom(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MaxQueuedPerSubmitter(2))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	task := func() {}
+
+	// The worker is busy, so tasks wait in the queue
+	assertEqual(t, nil, pool.SubmitFrom("a", task))
+	assertEqual(t, nil, pool.SubmitFrom("a", task))
+	assertEqual(t, pond.ErrSubmitterLimitReached, pool.SubmitFrom("a", task))
+
+	// Other submitters are not affected
+	assertEqual(t, nil, pool.SubmitFrom("b", task))
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, uint64(4), pool.SuccessfulTasks())
+	assertEqual(t, pond.ErrSubmitOnStoppedPool, pool.SubmitFrom("a", task))
+}
+
+func TestSubmitFromReleasesSlotsWhenTasksStart(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MaxQueuedPerSubmitter(1))
+	defer pool.StopAndWait()
+
+	for i := 0; i < 3; i++ {
+		done := make(chan struct{})
+		assertEqual(t, nil, pool.SubmitFrom("a", func() {
+			close(done)
+		}))
+		<-done
+	}
+
+	assertEqual(t, uint64(3), pool.SubmittedTasks())
+}
+
+func TestSubmitFromWithoutSubmitter(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MaxQueuedPerSubmitter(1))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	// Tasks without a submitter are not limited
+	for i := 0; i < 3; i++ {
+		assertEqual(t, nil, pool.SubmitFrom("", func() {}))
+	}
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, uint64(4), pool.SuccessfulTasks())
+}
+
+func TestSubmitFromWhileStopping(t *testing.T) {
+
+	pool := pond.New(1, 1, pond.MaxQueuedPerSubmitter(10))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	assertEqual(t, nil, pool.SubmitFrom("a", func() {}))
+
+	// The queue is full, so the submission blocks until the pool is stopped
+	submitted := make(chan error)
+	go func() {
+		submitted <- pool.SubmitFrom("a", func() {})
+	}()
+
+	for pool.WaitingTasks() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	pool.Stop()
+	for !pool.Stopped() {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	assertEqual(t, pond.ErrSubmitOnStoppedPool, <-submitted)
+}
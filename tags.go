@@ -0,0 +1,60 @@
+package pond
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Tags attaches the given key/value tags to a pool, so the telemetry of services running several pools can be told
+// apart: along with the pool's name (see Name), they are attached to its metrics (as "key:value" tags, see Metrics),
+// its stats (see StatsSnapshot), the metadata of its tasks passed to handlers and event listeners (see TaskInfo),
+// its trace records (see RecordTrace) and the lines it logs.
+func Tags(tags map[string]string) Option {
+	return func(pool *WorkerPool) {
+		pool.tags = copyTags(tags)
+	}
+}
+
+// Tags returns the tags attached to this pool (see Tags)
+func (p *WorkerPool) Tags() map[string]string {
+	return copyTags(p.tags)
+}
+
+// copyTags returns a copy of the given tags, or nil if there are none
+func copyTags(tags map[string]string) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	copied := make(map[string]string, len(tags))
+	for key, value := range tags {
+		copied[key] = value
+	}
+	return copied
+}
+
+// sortedTags returns the given tags formatted as key, separator and value, sorted by key
+func sortedTags(tags map[string]string, separator string) []string {
+	formatted := make([]string, 0, len(tags))
+	for key, value := range tags {
+		formatted = append(formatted, key+separator+value)
+	}
+	sort.Strings(formatted)
+	return formatted
+}
+
+// describePool returns a description of the pool with the given name and tags to include in log lines,
+// e.g. ` in pool "name" [key=value]`, or an empty string if it has neither
+func describePool(name string, tags map[string]string) string {
+	var description string
+	if name != "" {
+		description = fmt.Sprintf(" in pool %q", name)
+	} else if len(tags) > 0 {
+		description = " in pool"
+	}
+	if len(tags) > 0 {
+		description += " [" + strings.Join(sortedTags(tags, "="), " ") + "]"
+	}
+	return description
+}
@@ -0,0 +1,32 @@
+package pond_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestTags(t *testing.T) {
+
+	tags := map[string]string{"service": "billing", "region": "eu"}
+	sink := &recordingSink{}
+	pool := pond.New(1, 10, pond.Name("invoices"), pond.Tags(tags), pond.Metrics(sink, time.Hour))
+
+	// Tags are copied, so changing the original map has no effect
+	tags["region"] = "us"
+	assertEqual(t, "eu", pool.Tags()["region"])
+	assertEqual(t, "eu", pool.StatsSnapshot().Tags["region"])
+
+	var info pond.TaskInfo
+	pool.SubmitContext(func(ctx context.Context) {
+		info, _ = pond.FromContext(ctx)
+	})
+	pool.StopAndWait()
+
+	assertEqual(t, "invoices", info.Pool)
+	assertEqual(t, "billing", info.Tags["service"])
+	assertEqual(t, true, sink.contains("tasks.duration|ms|pool:invoices,region:eu,service:billing"))
+	assertEqual(t, true, sink.contains("tasks.submitted:1|c|pool:invoices,region:eu,service:billing"))
+}
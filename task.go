@@ -0,0 +1,187 @@
+package pond
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// lastTaskID holds the ID of the last task submitted to any worker pool
+var lastTaskID uint64
+
+// TaskInfo holds metadata about a task submitted to a worker pool
+type TaskInfo struct {
+	// ID uniquely identifies the task within the process
+	ID uint64
+	// Label identifies the kind of task, as given to SubmitLabeled or to the task group it belongs to
+	Label string
+	// Pool and Tags are the name and tags of the pool the task was submitted to (see Name and Tags)
+	Pool string
+	Tags map[string]string
+	// Submitter identifies who submitted the task, as given to SubmitFrom
+	Submitter string
+	// SubmittedAt is the time at which the task was submitted
+	SubmittedAt time.Time
+	// SubmittedFrom is the call site that submitted the task, if the pool captures it (see CaptureCallSite)
+	SubmittedFrom string
+	// Lane is the lane the task was submitted to (see Lanes)
+	Lane Lane
+	// Deadline is the time by which the task must start executing, or the zero time if it has none
+	Deadline time.Time
+	// Attempt is the number of times the task has been attempted, starting at 1
+	Attempt int
+	// StartedAt is the time at which a worker started executing the task, or the zero time if it has not started
+	StartedAt time.Time
+	// Duration is how long the task ran, which is only known once it has finished (e.g. in event listeners)
+	Duration time.Duration
+	// Checkpoint is the latest state reported by the task with Checkpoint, or the one it resumes from if it's
+	// a retry (see RetryPolicy.ResumeFromCheckpoint). Outside of the task, it's only known once it has finished.
+	Checkpoint interface{}
+	// AllocatedBytes is an estimate of the memory allocated by the task, which is only known once it has finished
+	// and only if the pool measures allocations (see MeasureAllocations)
+	AllocatedBytes uint64
+}
+
+// taskInfoKey is the context key under which a running task is stored
+type taskInfoKey struct{}
+
+// taskValue is the value stored in the context of a running task: a snapshot of its metadata and the task itself
+type taskValue struct {
+	info TaskInfo
+	task *queuedTask
+}
+
+// FromContext returns the metadata of the task the given context was passed to, if any
+func FromContext(ctx context.Context) (TaskInfo, bool) {
+	value, ok := ctx.Value(taskInfoKey{}).(taskValue)
+	return value.info, ok
+}
+
+// withTaskInfo returns a copy of ctx that carries the given task and a snapshot of its metadata
+func withTaskInfo(ctx context.Context, task *queuedTask) context.Context {
+	return context.WithValue(ctx, taskInfoKey{}, taskValue{info: task.info, task: task})
+}
+
+// taskFromContext returns the running task the given context was passed to, or nil if there is none
+func taskFromContext(ctx context.Context) *queuedTask {
+	value, _ := ctx.Value(taskInfoKey{}).(taskValue)
+	return value.task
+}
+
+// taskOutcome represents the result of processing a task
+type taskOutcome int
+
+const (
+	taskSucceeded taskOutcome = iota
+	taskFailed
+	taskExpired
+)
+
+// queuedTask represents a task along with its metadata, as it travels through the queue
+type queuedTask struct {
+	run  func()
+	info TaskInfo
+	// Function invoked with the reason's error if the task is dropped without being executed, if any.
+	// Tasks that don't set it are rejected instead (see discard).
+	onDiscard func(err error)
+	// Function invoked with the error of the submit hook that vetoed the submission of the task, or with the reason's
+	// error if the task is dropped without being executed and onDiscard is not set, if any
+	onReject func(err error)
+	// Set for tasks submitted by the pool itself, which are not subject to submit hooks
+	internal bool
+	// Set for tasks submitted with SubmitFront, which are queued ahead of the others
+	front bool
+	// Pool and context of the worker executing the task, set when it starts
+	pool      *WorkerPool
+	workerCtx context.Context
+	// Set to 1 once the task reports a checkpoint
+	checkpointed int32
+	// Set to 1 once the task reports a latency sample (see ReportLatency)
+	latencyReported int32
+	// State of the invariant checks, which only exists in builds with the pond_debug tag
+	invariants taskInvariants
+	// Functions to call once the task is done (see OnTaskDone)
+	cleanups taskCleanups
+}
+
+// newQueuedTask wraps the given task function, assigning it an ID and recording its submission time
+func newQueuedTask(task func()) *queuedTask {
+	return &queuedTask{
+		run: task,
+		info: TaskInfo{
+			ID:          atomic.AddUint64(&lastTaskID, 1),
+			SubmittedAt: time.Now(),
+			Attempt:     1,
+		},
+	}
+}
+
+// newQueuedTasks wraps the given task functions (skipping nil ones) with a single allocation for all of them,
+// assigning them consecutive IDs and the same submission time
+func newQueuedTasks(tasks []func()) []*queuedTask {
+	count := 0
+	for _, task := range tasks {
+		if task != nil {
+			count++
+		}
+	}
+
+	block := make([]queuedTask, count)
+	queued := make([]*queuedTask, count)
+	firstID := atomic.AddUint64(&lastTaskID, uint64(count)) - uint64(count) + 1
+	now := time.Now()
+
+	i := 0
+	for _, task := range tasks {
+		if task == nil {
+			continue
+		}
+		block[i].run = task
+		block[i].info = TaskInfo{
+			ID:          firstID + uint64(i),
+			SubmittedAt: now,
+			Attempt:     1,
+		}
+		queued[i] = &block[i]
+		i++
+	}
+	return queued
+}
+
+// newContextTask wraps the given context-aware task function. The task receives a context
+// derived from parent that carries its metadata (see FromContext).
+func newContextTask(parent context.Context, task func(context.Context)) *queuedTask {
+	queued := newQueuedTask(nil)
+	if task != nil {
+		queued.run = func() {
+			task(withTaskInfo(parent, queued))
+		}
+	}
+	return queued
+}
+
+// expired returns true if the task has a deadline and it has passed, or if it has been waiting
+// for longer than maxQueueAge (when greater than zero)
+func (t *queuedTask) expired(now time.Time, maxQueueAge time.Duration) bool {
+	if !t.info.Deadline.IsZero() && now.After(t.info.Deadline) {
+		return true
+	}
+	return maxQueueAge > 0 && now.Sub(t.info.SubmittedAt) > maxQueueAge
+}
+
+// discard invokes the function registered to be notified when the task is dropped without being executed, or
+// rejects the task with the given error if there is none, so helpers waiting for the task always hear about it
+func (t *queuedTask) discard(err error) {
+	if t.onDiscard != nil {
+		t.onDiscard(err)
+		return
+	}
+	t.reject(err)
+}
+
+// reject invokes the callback of the task, if any, to report that its submission was vetoed with the given error
+func (t *queuedTask) reject(err error) {
+	if t.onReject != nil {
+		t.onReject(err)
+	}
+}
@@ -0,0 +1,368 @@
+package pond
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrTenantQueueFull is returned when attempting to submit a task for a tenant that has reached its maximum queue depth
+	ErrTenantQueueFull = errors.New("tenant queue is full")
+)
+
+// TenantQuota defines the limits applied to the tasks of a single tenant. A value of 0 means no limit.
+type TenantQuota struct {
+	// MaxConcurrency is the maximum number of tasks of the tenant that can run at the same time
+	MaxConcurrency int
+	// MaxQueueDepth is the maximum number of tasks of the tenant that can be waiting to run
+	MaxQueueDepth int
+}
+
+// TenantStats holds the counters of a single tenant
+type TenantStats struct {
+	Running    int
+	Waiting    int
+	Submitted  uint64
+	Successful uint64
+	Failed     uint64
+	Rejected   uint64
+}
+
+// DefaultTenantQuota allows to change the quota applied to tenants that do not have a specific one (see SetTenantQuota)
+func DefaultTenantQuota(quota TenantQuota) Option {
+	return func(pool *WorkerPool) {
+		pool.tenants.defaultQuota = quota
+	}
+}
+
+// tenant holds the queued tasks and counters of a single tenant
+type tenant struct {
+	id    string
+	quota *TenantQuota
+	queue []tenantTask
+	stats TenantStats
+	// Element in the scheduler's ready list, if the tenant has queued tasks
+	elem *list.Element
+}
+
+// tenantTask is a task waiting in the queue of a tenant
+type tenantTask struct {
+	run         func()
+	submittedAt time.Time
+}
+
+// tenantScheduler dispatches tenant tasks to the pool, dequeueing them in round-robin order across tenants
+type tenantScheduler struct {
+	pool         *WorkerPool
+	defaultQuota TenantQuota
+	tenants      map[string]*tenant
+	// Tenants with queued tasks, in the order they will be served
+	ready   list.List
+	runners int
+	mutex   sync.Mutex
+}
+
+// SubmitTenant sends a task on behalf of the given tenant to this worker pool for execution.
+// Tasks are dequeued fairly across tenants, so a tenant flooding the pool cannot starve the others, and are subject
+// to the tenant's quota. It returns ErrTenantQueueFull if the tenant has reached its maximum queue depth,
+// ErrSubmitOnStoppedPool if the pool has been stopped, and the error of the submit hook that vetoed the task, if any
+// (see SubmitHooks). Hooks see the tenant as the submitter of the task, and changes they make are ignored.
+func (p *WorkerPool) SubmitTenant(tenantID string, task func()) error {
+	if task == nil {
+		return nil
+	}
+
+	if p.Stopped() {
+		return ErrSubmitOnStoppedPool
+	}
+
+	if len(p.submitHooks) > 0 {
+		info := TaskInfo{
+			Submitter:   tenantID,
+			Pool:        p.name,
+			Tags:        p.tags,
+			SubmittedAt: p.now(),
+		}
+		if err := p.callSubmitHooks(&info); err != nil {
+			p.dropTask(DropVetoed, info)
+			return err
+		}
+	}
+
+	return p.tenants.submit(tenantID, task)
+}
+
+// SetTenantQuota sets the quota applied to the given tenant, overriding the default tenant quota
+func (p *WorkerPool) SetTenantQuota(tenantID string, quota TenantQuota) {
+	s := p.tenants
+
+	s.mutex.Lock()
+	s.getTenant(tenantID).quota = &quota
+	s.mutex.Unlock()
+
+	// A higher concurrency limit may allow more tasks to run
+	_ = s.maybeStartRunners()
+}
+
+// TenantStats returns the counters of the given tenant. Tenants without a specific quota are forgotten, along with
+// their counters, once they have no tasks waiting or running, so the pool doesn't keep track of every tenant it has
+// ever seen. Set a quota for the tenants whose counters must be kept (see SetTenantQuota).
+func (p *WorkerPool) TenantStats(tenantID string) TenantStats {
+	s := p.tenants
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if t, ok := s.tenants[tenantID]; ok {
+		stats := t.stats
+		stats.Waiting = len(t.queue)
+		return stats
+	}
+	return TenantStats{}
+}
+
+// oldestSubmission returns the submission time of the oldest task waiting in the queue of a tenant, or the zero time
+// if there is none
+func (s *tenantScheduler) oldestSubmission() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var oldest time.Time
+	for elem := s.ready.Front(); elem != nil; elem = elem.Next() {
+		// Tenant queues are FIFO, so the oldest task is the first one
+		if submittedAt := elem.Value.(*tenant).queue[0].submittedAt; oldest.IsZero() || submittedAt.Before(oldest) {
+			oldest = submittedAt
+		}
+	}
+	return oldest
+}
+
+// waiting returns the metadata of the tasks waiting in the queues of tenants
+func (s *tenantScheduler) waiting() []TaskInfo {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var waiting []TaskInfo
+	for elem := s.ready.Front(); elem != nil; elem = elem.Next() {
+		t := elem.Value.(*tenant)
+		for _, task := range t.queue {
+			waiting = append(waiting, s.infoOf(t, task))
+		}
+	}
+	return waiting
+}
+
+// cancel removes the tasks waiting in the queues of tenants whose metadata matches, reporting them to the dropped
+// task handler with the given reason, and returns how many were removed
+func (s *tenantScheduler) cancel(match func(info TaskInfo) bool, reason DropReason) int {
+	var cancelled []TaskInfo
+
+	s.mutex.Lock()
+	for elem := s.ready.Front(); elem != nil; {
+		t, next := elem.Value.(*tenant), elem.Next()
+
+		queue := t.queue[:0]
+		for _, task := range t.queue {
+			if info := s.infoOf(t, task); match(info) {
+				cancelled = append(cancelled, info)
+			} else {
+				queue = append(queue, task)
+			}
+		}
+		for i := len(queue); i < len(t.queue); i++ {
+			t.queue[i] = tenantTask{}
+		}
+		t.queue = queue
+
+		if len(t.queue) == 0 {
+			s.ready.Remove(elem)
+			t.elem = nil
+			s.prune(t)
+		}
+		elem = next
+	}
+	s.mutex.Unlock()
+
+	for _, info := range cancelled {
+		s.pool.dropTask(reason, info)
+	}
+	return len(cancelled)
+}
+
+// infoOf returns the metadata of a task waiting in the queue of the given tenant, which is its submitter
+func (s *tenantScheduler) infoOf(t *tenant, task tenantTask) TaskInfo {
+	return TaskInfo{
+		Pool:        s.pool.name,
+		Tags:        s.pool.tags,
+		Submitter:   t.id,
+		SubmittedAt: task.submittedAt,
+	}
+}
+
+func newTenantScheduler(pool *WorkerPool) *tenantScheduler {
+	return &tenantScheduler{
+		pool:    pool,
+		tenants: make(map[string]*tenant),
+	}
+}
+
+func (s *tenantScheduler) submit(tenantID string, task func()) error {
+
+	s.mutex.Lock()
+	t := s.getTenant(tenantID)
+	quota := s.quotaOf(t)
+	if quota.MaxQueueDepth > 0 && len(t.queue) >= quota.MaxQueueDepth {
+		t.stats.Rejected++
+		s.mutex.Unlock()
+		return ErrTenantQueueFull
+	}
+
+	t.stats.Submitted++
+	t.queue = append(t.queue, tenantTask{run: task, submittedAt: s.pool.now()})
+	if t.elem == nil {
+		t.elem = s.ready.PushBack(t)
+	}
+	s.mutex.Unlock()
+
+	return s.maybeStartRunners()
+}
+
+// maybeStartRunners submits runners to the pool while there are eligible tasks and less runners than workers.
+// If the pool was stopped and no runner is left to execute the waiting tasks, they are dropped and
+// ErrSubmitOnStoppedPool is returned.
+func (s *tenantScheduler) maybeStartRunners() error {
+	for {
+		s.mutex.Lock()
+		if s.runners >= s.pool.effectiveMaxWorkers() || s.nextTenant() == nil {
+			s.mutex.Unlock()
+			return nil
+		}
+		s.runners++
+		s.mutex.Unlock()
+
+		runner := newQueuedTask(s.run)
+		runner.internal = true
+		runner.onDiscard = s.discardRunner
+		if err := s.pool.submitUnlessStopped(runner); err != nil {
+			s.mutex.Lock()
+			s.runners--
+			abandoned := s.runners == 0
+			s.mutex.Unlock()
+
+			// Runners still executing tenant tasks keep going until the queues of tenants are empty
+			if !abandoned {
+				return nil
+			}
+			s.cancel(func(TaskInfo) bool { return true }, DropStopped)
+			return err
+		}
+	}
+}
+
+// discardRunner releases the slot of a runner that was dropped without being executed (e.g. because it expired) and
+// starts another one in its place if there are eligible tasks. The runner is started from another goroutine, since
+// this one may be a worker of the pool, which must not wait for room in the queue.
+func (s *tenantScheduler) discardRunner(error) {
+	s.mutex.Lock()
+	s.runners--
+	s.mutex.Unlock()
+
+	go func() {
+		_ = s.maybeStartRunners()
+	}()
+}
+
+// run represents the work done by a runner, which executes tenant tasks until there are no eligible ones left
+func (s *tenantScheduler) run() {
+	s.mutex.Lock()
+	for {
+		t := s.nextTenant()
+		if t == nil {
+			s.runners--
+			s.mutex.Unlock()
+			return
+		}
+
+		task, info := t.queue[0].run, s.infoOf(t, t.queue[0])
+		t.queue[0] = tenantTask{}
+		t.queue = t.queue[1:]
+		t.stats.Running++
+
+		// Move the tenant to the back of the line so the others get their turn
+		s.ready.Remove(t.elem)
+		t.elem = nil
+		if len(t.queue) > 0 {
+			t.elem = s.ready.PushBack(t)
+		}
+		s.mutex.Unlock()
+
+		succeeded := s.execute(task, info)
+
+		s.mutex.Lock()
+		t.stats.Running--
+		if succeeded {
+			t.stats.Successful++
+		} else {
+			t.stats.Failed++
+		}
+		s.prune(t)
+	}
+}
+
+// execute runs a single tenant task, reporting panics to the pool's panic handler. They are only counted as failed
+// by the tenant, since the runner that executes the task recovers them and carries on.
+func (s *tenantScheduler) execute(task func(), info TaskInfo) (succeeded bool) {
+
+	defer func() {
+		if panic := recover(); panic != nil {
+			s.pool.handlePanic(panic, info)
+		}
+	}()
+
+	task()
+
+	return true
+}
+
+// nextTenant returns the first tenant in line that has queued tasks and has not reached its concurrency limit.
+// Must be called with the mutex held.
+func (s *tenantScheduler) nextTenant() *tenant {
+	for elem := s.ready.Front(); elem != nil; elem = elem.Next() {
+		t := elem.Value.(*tenant)
+		quota := s.quotaOf(t)
+		if quota.MaxConcurrency <= 0 || t.stats.Running < quota.MaxConcurrency {
+			return t
+		}
+	}
+	return nil
+}
+
+// getTenant returns the tenant with the given ID, creating it if necessary. Must be called with the mutex held.
+func (s *tenantScheduler) getTenant(tenantID string) *tenant {
+	t, ok := s.tenants[tenantID]
+	if !ok {
+		t = &tenant{
+			id: tenantID,
+		}
+		s.tenants[tenantID] = t
+	}
+	return t
+}
+
+// prune forgets the given tenant if it has no specific quota and no tasks waiting or running.
+// Must be called with the mutex held.
+func (s *tenantScheduler) prune(t *tenant) {
+	if t.quota == nil && len(t.queue) == 0 && t.stats.Running == 0 {
+		delete(s.tenants, t.id)
+	}
+}
+
+// quotaOf returns the quota that applies to the given tenant. Must be called with the mutex held.
+func (s *tenantScheduler) quotaOf(t *tenant) TenantQuota {
+	if t.quota != nil {
+		return *t.quota
+	}
+	return s.defaultQuota
+}
@@ -0,0 +1,203 @@
+package pond_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestSubmitTenantIsFair(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	var mutex sync.Mutex
+	var order []string
+	record := func(tenantID string) func() {
+		return func() {
+			mutex.Lock()
+			order = append(order, tenantID)
+			mutex.Unlock()
+		}
+	}
+
+	// Block the only worker until every task has been submitted
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.SubmitTenant("a", func() {
+		close(started)
+		<-release
+	})
+	<-started
+	for i := 0; i < 10; i++ {
+		assertEqual(t, nil, pool.SubmitTenant("a", record("a")))
+	}
+	for i := 0; i < 2; i++ {
+		assertEqual(t, nil, pool.SubmitTenant("b", record("b")))
+	}
+
+	stats := pool.TenantStats("a")
+	assertEqual(t, uint64(11), stats.Submitted)
+	assertEqual(t, 1, stats.Running)
+	assertEqual(t, 10, stats.Waiting)
+
+	close(release)
+	pool.StopAndWait()
+
+	// Tenant b must not wait behind all of tenant a's tasks
+	assertEqual(t, 12, len(order))
+	assertEqual(t, "a", order[0])
+	assertEqual(t, "b", order[1])
+	assertEqual(t, "a", order[2])
+	assertEqual(t, "b", order[3])
+
+	// Tenants without a specific quota are forgotten once they have no tasks left
+	assertEqual(t, pond.TenantStats{}, pool.TenantStats("a"))
+}
+
+func TestSubmitTenantWithQuota(t *testing.T) {
+
+	pool := pond.New(4, 10, pond.DefaultTenantQuota(pond.TenantQuota{
+		MaxConcurrency: 1,
+		MaxQueueDepth:  2,
+	}))
+
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	release := make(chan struct{})
+	task := func() {
+		mutex.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mutex.Unlock()
+
+		<-release
+
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+	}
+
+	// First task starts running, the next 2 are queued and the last one is rejected
+	started := make(chan struct{})
+	assertEqual(t, nil, pool.SubmitTenant("a", func() {
+		close(started)
+		task()
+	}))
+	<-started
+	assertEqual(t, nil, pool.SubmitTenant("a", task))
+	assertEqual(t, nil, pool.SubmitTenant("a", task))
+	assertEqual(t, pond.ErrTenantQueueFull, pool.SubmitTenant("a", task))
+	assertEqual(t, uint64(1), pool.TenantStats("a").Rejected)
+
+	// A tenant with a specific quota is not affected by the default one
+	pool.SetTenantQuota("b", pond.TenantQuota{})
+	for i := 0; i < 5; i++ {
+		assertEqual(t, nil, pool.SubmitTenant("b", func() {}))
+	}
+
+	close(release)
+	pool.StopAndWait()
+
+	assertEqual(t, 1, maxRunning)
+	assertEqual(t, pond.TenantStats{}, pool.TenantStats("a"))
+	assertEqual(t, uint64(5), pool.TenantStats("b").Successful)
+	assertEqual(t, pond.TenantStats{}, pool.TenantStats("unknown"))
+}
+
+func TestSubmitTenantWithPanic(t *testing.T) {
+
+	var panicInfo pond.TaskInfo
+	pool := pond.New(1, 10, pond.TaskPanicHandler(func(_ interface{}, info pond.TaskInfo) {
+		panicInfo = info
+	}))
+	pool.SetTenantQuota("a", pond.TenantQuota{})
+
+	pool.SubmitTenant("a", func() {
+		panic("boom")
+	})
+	pool.SubmitTenant("a", func() {})
+	pool.SubmitTenant("a", nil)
+
+	pool.StopAndWait()
+
+	assertEqual(t, uint64(1), pool.TenantStats("a").Failed)
+	assertEqual(t, uint64(1), pool.TenantStats("a").Successful)
+	assertEqual(t, "a", panicInfo.Submitter)
+
+	// The runner that executed the tenant tasks recovered the panic
+	assertEqual(t, uint64(0), pool.FailedTasks())
+	assertEqual(t, pool.SubmittedTasks(), pool.CompletedTasks()+pool.ExpiredTasks())
+	assertEqual(t, pond.ErrSubmitOnStoppedPool, pool.SubmitTenant("a", func() {}))
+}
+
+func TestSubmitTenantWhileStopping(t *testing.T) {
+
+	pool := pond.New(1, 1)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	pool.Submit(func() {})
+
+	// The queue is full, so the runner of the tenant task waits for room until the pool is stopped
+	submitted := make(chan error)
+	go func() {
+		submitted <- pool.SubmitTenant("a", func() {})
+	}()
+	for pool.WaitingTasks() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	pool.Stop()
+	for !pool.Stopped() {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	assertEqual(t, pond.ErrSubmitOnStoppedPool, <-submitted)
+	assertEqual(t, 0, pool.TenantStats("a").Waiting)
+}
+
+func TestSubmitTenantWithExpiredRunner(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.MaxQueueAge(time.Millisecond))
+	pool.SetTenantQuota("a", pond.TenantQuota{})
+
+	// Occupy the worker until the runner of the tenant tasks expires
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var ran int32
+	for i := 0; i < 2; i++ {
+		pool.SubmitTenant("a", func() {
+			atomic.AddInt32(&ran, 1)
+		})
+	}
+	time.Sleep(5 * time.Millisecond)
+	close(release)
+
+	// Another runner takes the place of the expired one
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&ran) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	pool.StopAndWait()
+
+	assertEqual(t, int32(2), atomic.LoadInt32(&ran))
+	assertEqual(t, true, pool.ExpiredTasks() > 0)
+	assertEqual(t, uint64(2), pool.TenantStats("a").Successful)
+}
@@ -0,0 +1,124 @@
+package pond
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TraceRecord describes a task processed by a pool that records a trace (see RecordTrace)
+type TraceRecord struct {
+	// ID, Label and Lane are those of the task, and Pool and Tags those of the pool (see TaskInfo)
+	ID    uint64            `json:"id"`
+	Label string            `json:"label,omitempty"`
+	Lane  Lane              `json:"lane,omitempty"`
+	Pool  string            `json:"pool,omitempty"`
+	Tags  map[string]string `json:"tags,omitempty"`
+	// SubmittedAt is the time at which the task was submitted
+	SubmittedAt time.Time `json:"submittedAt"`
+	// Wait is how long the task waited in the queue
+	Wait time.Duration `json:"wait"`
+	// Duration is how long the task ran, or 0 if it expired
+	Duration time.Duration `json:"duration,omitempty"`
+	// Failed is true if the task panicked
+	Failed bool `json:"failed,omitempty"`
+	// Expired is true if the task was discarded because it waited too long (see MaxQueueAge)
+	Expired bool `json:"expired,omitempty"`
+}
+
+// RecordTrace makes a pool record a trace of the tasks it processes to the given writer, as one JSON-encoded
+// TraceRecord per line, written when the task finishes or expires. Traces capture the arrival pattern and durations
+// of production load, which can be replayed offline against other pool configurations (see ReadTrace and the bench
+// package). If writing fails, the error is logged to stderr and recording stops.
+func RecordTrace(w io.Writer) Option {
+	return func(pool *WorkerPool) {
+		pool.trace = newTraceRecorder(w, &sync.Mutex{})
+	}
+}
+
+// traceRecorder writes the trace records of a pool, one at a time. The recorders of pools cloned from one another
+// (see CloneWith) write to the same writer, so they share the lock that serializes their writes.
+type traceRecorder struct {
+	writer  io.Writer
+	encoder *json.Encoder
+	failed  bool
+	mutex   *sync.Mutex
+}
+
+// newTraceRecorder creates a recorder writing to the given writer, while holding the given lock
+func newTraceRecorder(w io.Writer, mutex *sync.Mutex) *traceRecorder {
+	return &traceRecorder{
+		writer:  w,
+		encoder: json.NewEncoder(w),
+		mutex:   mutex,
+	}
+}
+
+// record writes the record of the given task, which finished or expired at the given time
+func (r *traceRecorder) record(info TaskInfo, outcome taskOutcome, now time.Time) {
+	if r == nil {
+		return
+	}
+
+	record := TraceRecord{
+		ID:          info.ID,
+		Label:       info.Label,
+		Lane:        info.Lane,
+		Pool:        info.Pool,
+		Tags:        info.Tags,
+		SubmittedAt: info.SubmittedAt,
+		Duration:    info.Duration,
+		Failed:      outcome == taskFailed,
+		Expired:     outcome == taskExpired,
+	}
+	if outcome == taskExpired {
+		record.Wait = now.Sub(info.SubmittedAt)
+	} else {
+		record.Wait = info.StartedAt.Sub(info.SubmittedAt)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.failed {
+		return
+	}
+	if err := r.encoder.Encode(record); err != nil {
+		r.failed = true
+		fmt.Fprintf(os.Stderr, "Failed to record task trace%s, recording stops: %v\n", describePool(info.Pool, info.Tags), err)
+	}
+}
+
+// ReadTrace reads the records of a trace written by a pool (see RecordTrace), sorted by submission time
+func ReadTrace(r io.Reader) ([]TraceRecord, error) {
+	var records []TraceRecord
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record TraceRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid trace record at line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].SubmittedAt.Equal(records[j].SubmittedAt) {
+			return records[i].ID < records[j].ID
+		}
+		return records[i].SubmittedAt.Before(records[j].SubmittedAt)
+	})
+	return records, nil
+}
@@ -0,0 +1,44 @@
+package pond_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestRecordTrace(t *testing.T) {
+
+	var trace bytes.Buffer
+	pool := pond.New(1, 10, pond.RecordTrace(&trace), pond.PanicHandler(func(interface{}) {}))
+
+	pool.SubmitLabeled("slow", func() {
+		time.Sleep(10 * time.Millisecond)
+	})
+	pool.SubmitWithDeadline(func() {}, time.Now().Add(time.Millisecond))
+	pool.Submit(func() {
+		panic("failed")
+	})
+	pool.StopAndWait()
+
+	records, err := pond.ReadTrace(&trace)
+	assertEqual(t, nil, err)
+	assertEqual(t, 3, len(records))
+
+	assertEqual(t, "slow", records[0].Label)
+	assertEqual(t, true, records[0].Duration >= 10*time.Millisecond)
+	assertEqual(t, false, records[0].Failed)
+	assertEqual(t, true, records[1].Expired)
+	assertEqual(t, time.Duration(0), records[1].Duration)
+	assertEqual(t, true, records[1].Wait >= 10*time.Millisecond)
+	assertEqual(t, true, records[2].Failed)
+	assertEqual(t, true, records[0].SubmittedAt.Before(records[2].SubmittedAt))
+}
+
+func TestReadTraceInvalidRecord(t *testing.T) {
+
+	_, err := pond.ReadTrace(strings.NewReader("{\"id\":1}\nnot json\n"))
+	assertEqual(t, true, err != nil && strings.HasPrefix(err.Error(), "invalid trace record at line 2"))
+}
@@ -0,0 +1,72 @@
+package pond
+
+import (
+	"math"
+	"time"
+)
+
+// utilizationSamples is the number of times the number of busy workers is sampled per utilization window
+const utilizationSamples = 10
+
+// TargetUtilization makes a worker pool shrink proactively when its workers are underused, beyond stopping workers
+// that stay idle for the idle timeout: the number of busy workers is sampled regularly, and whenever the average
+// utilization over the given window (the fraction of running workers that were busy) is below target (between 0
+// and 1), idle workers are stopped so that the remaining ones would have been used at the target utilization.
+// The pool grows back as usual when tasks are submitted and no worker is idle, so the number of goroutines stays
+// proportional to the actual load. MinWorkers, KeepWarm and the scaling policy (see Scaling) are respected.
+func TargetUtilization(target float64, window time.Duration) Option {
+	return func(pool *WorkerPool) {
+		pool.utilization = &utilizationScaler{
+			target: target,
+			window: window,
+		}
+	}
+}
+
+// utilizationScaler holds the settings of target-utilization scaling
+type utilizationScaler struct {
+	target float64
+	window time.Duration
+}
+
+// desiredWorkers returns the number of workers that would have been used at the target utilization,
+// given the average numbers of busy and running workers over a window
+func (s *utilizationScaler) desiredWorkers(busy, running float64) int {
+	if running == 0 || busy/running >= s.target {
+		return int(math.Ceil(running))
+	}
+	return int(math.Ceil(busy / s.target))
+}
+
+// scaleToUtilization represents the work done by the goroutine that shrinks the pool to its target utilization
+func (p *WorkerPool) scaleToUtilization() {
+	defer p.workersWaitGroup.Done()
+
+	ticker := time.NewTicker(p.utilization.window / utilizationSamples)
+	defer ticker.Stop()
+
+	var busy, running, samples int
+	for {
+		select {
+		case <-ticker.C:
+			runningWorkers := p.RunningWorkers()
+			busy += runningWorkers - p.IdleWorkers()
+			running += runningWorkers
+			samples++
+
+			if samples < utilizationSamples {
+				continue
+			}
+
+			desired := p.utilization.desiredWorkers(float64(busy)/float64(samples), float64(running)/float64(samples))
+			for excess := p.RunningWorkers() - desired; excess > 0; excess-- {
+				if !p.maybeStopIdleWorker() {
+					break
+				}
+			}
+			busy, running, samples = 0, 0, 0
+		case <-p.context.Done():
+			return
+		}
+	}
+}
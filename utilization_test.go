@@ -0,0 +1,61 @@
+package pond_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestTargetUtilizationShrinksUnderusedPool(t *testing.T) {
+
+	pool := pond.New(10, 100, pond.IdleTimeout(time.Hour), pond.TargetUtilization(0.5, 50*time.Millisecond))
+
+	release := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		pool.Submit(func() {
+			<-release
+		})
+	}
+	assertEqual(t, 10, pool.RunningWorkers())
+
+	// Keep 2 workers busy, so 4 of them are needed to be used at 50%
+	busy := make(chan struct{})
+	close(release)
+	pool.SubmitAndWait(func() {})
+	for i := 0; i < 2; i++ {
+		pool.Submit(func() {
+			<-busy
+		})
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	assertEqual(t, 4, pool.RunningWorkers())
+
+	// The pool grows back under load
+	for i := 0; i < 4; i++ {
+		pool.Submit(func() {
+			<-busy
+		})
+		time.Sleep(5 * time.Millisecond)
+	}
+	assertEqual(t, 6, pool.RunningWorkers())
+
+	close(busy)
+	pool.StopAndWait()
+}
+
+func TestTargetUtilizationValidation(t *testing.T) {
+
+	_, err := pond.NewWithOptions(10, 10, pond.TargetUtilization(1.5, time.Second))
+	assertEqual(t, "invalid worker pool configuration: target utilization must be greater than 0 and at most 1, got 1.5", err.Error())
+
+	_, err = pond.NewWithOptions(10, 10, pond.TargetUtilization(0.5, 0))
+	assertEqual(t, "invalid worker pool configuration: utilization window must be greater than 0, got 0s", err.Error())
+
+	pool := pond.New(10, 10, pond.TargetUtilization(0.5, time.Second))
+	config := pool.Options()
+	assertEqual(t, 0.5, config.TargetUtilization)
+	assertEqual(t, time.Second, config.UtilizationWindow)
+	pool.StopAndWait()
+}
@@ -0,0 +1,46 @@
+package pond
+
+import "context"
+
+// SubmitAndWaitErr sends a task to this worker pool for execution, waits for it to complete and returns its error.
+// If the task panics, it returns a PanicError (and the panic is handled by the pool's panic handler as well).
+// If the task is not executed, it returns why, as SubmitTask does (e.g. ErrSubmitOnStoppedPool).
+func (p *WorkerPool) SubmitAndWaitErr(task func() error) error {
+	if task == nil {
+		return nil
+	}
+
+	return p.SubmitTask(Task{
+		Fn: func(context.Context) error {
+			return task()
+		},
+	}).Err()
+}
+
+// SubmitAndWaitErrContext is like SubmitAndWaitErr, but stops waiting once ctx is done, in which case it returns
+// ctx.Err() and the task keeps running. The context passed to the task is linked to ctx as in SubmitLinked,
+// and the task is skipped if ctx is done before it starts.
+func (p *WorkerPool) SubmitAndWaitErrContext(ctx context.Context, task func(ctx context.Context) error) error {
+	if ctx == nil {
+		panic("a non-nil context needs to be specified when using SubmitAndWaitErrContext")
+	}
+	if task == nil {
+		return nil
+	}
+
+	future := p.SubmitTask(Task{
+		Fn: func(taskCtx context.Context) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			linked := p.link(ctx)
+			defer linked.cancel(context.Canceled)
+
+			return task(withTaskInfo(linked, taskFromContext(taskCtx)))
+		},
+	})
+
+	_, err := future.WaitContext(ctx)
+	return err
+}
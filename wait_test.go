@@ -0,0 +1,72 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestSubmitAndWaitErr(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.PanicHandler(func(interface{}) {}))
+
+	assertEqual(t, nil, pool.SubmitAndWaitErr(nil))
+	assertEqual(t, nil, pool.SubmitAndWaitErr(func() error {
+		return nil
+	}))
+	assertEqual(t, "failed", pool.SubmitAndWaitErr(func() error {
+		return errors.New("failed")
+	}).Error())
+
+	var panicErr *pond.PanicError
+	err := pool.SubmitAndWaitErr(func() error {
+		panic("boom")
+	})
+	assertEqual(t, true, errors.As(err, &panicErr))
+	assertEqual(t, "boom", panicErr.Value)
+
+	pool.StopAndWait()
+
+	assertEqual(t, pond.ErrSubmitOnStoppedPool, pool.SubmitAndWaitErr(func() error {
+		return nil
+	}))
+}
+
+func TestSubmitAndWaitErrContext(t *testing.T) {
+
+	pool := pond.New(1, 10)
+
+	err := pool.SubmitAndWaitErrContext(context.Background(), func(ctx context.Context) error {
+		_, ok := pond.FromContext(ctx)
+		assertEqual(t, true, ok)
+		return errors.New("failed")
+	})
+	assertEqual(t, "failed", err.Error())
+
+	// Stops waiting once the context is done and cancels the task's context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	cancelled := make(chan error)
+	err = pool.SubmitAndWaitErrContext(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return nil
+	})
+	assertEqual(t, context.DeadlineExceeded, err)
+	assertEqual(t, context.DeadlineExceeded, <-cancelled)
+
+	// Skips the task if the context is done before it starts
+	executed := false
+	err = pool.SubmitAndWaitErrContext(ctx, func(ctx context.Context) error {
+		executed = true
+		return nil
+	})
+	assertEqual(t, context.DeadlineExceeded, err)
+
+	pool.StopAndWait()
+	assertEqual(t, false, executed)
+}
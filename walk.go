@@ -0,0 +1,88 @@
+package pond
+
+import (
+	"context"
+	"io/fs"
+	"sync"
+)
+
+// WalkDir walks the file tree rooted at root (see fs.WalkDir) and calls fn for each file on the worker pool,
+// so files are processed concurrently while the walk itself is bounded by the pool: it pauses whenever the pool
+// can't accept more tasks. Use os.DirFS to walk a directory of the local file system.
+// Errors returned by fn, panics and errors reading directories don't stop the walk. WalkDir waits for all files
+// to be processed and returns a GroupError holding a TaskError per failure (whose Label is the path), or nil.
+// If ctx is canceled, the walk stops, files that were not processed yet are skipped and the context's error is returned.
+func WalkDir(ctx context.Context, pool *WorkerPool, fsys fs.FS, root string, fn func(ctx context.Context, path string, d fs.DirEntry) error) error {
+
+	if ctx == nil {
+		panic("a non-nil context needs to be specified when using WalkDir")
+	}
+
+	var waitGroup sync.WaitGroup
+	var mutex sync.Mutex
+	var errs []error
+
+	fail := func(index int, path string, err error) {
+		mutex.Lock()
+		errs = append(errs, &TaskError{Index: index, Label: path, Err: err})
+		mutex.Unlock()
+	}
+
+	index := 0
+	walkErr := fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			fail(index, path, err)
+			index++
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		i := index
+		index++
+		waitGroup.Add(1)
+		pool.submitOrReject(func() {
+			defer waitGroup.Done()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			completed := false
+			defer func() {
+				if !completed {
+					// Report the panic as the error of the file and let the pool handle it
+					p := recover()
+					fail(i, path, newPanicError(p))
+					panic(p)
+				}
+			}()
+
+			if err := fn(ctx, path, d); err != nil {
+				fail(i, path, err)
+			}
+			completed = true
+		}, func(err error) {
+			fail(i, path, err)
+			waitGroup.Done()
+		})
+		return nil
+	})
+
+	waitGroup.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if walkErr != nil {
+		return walkErr
+	}
+	if len(errs) > 0 {
+		return &GroupError{Errors: errs}
+	}
+	return nil
+}
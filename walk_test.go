@@ -0,0 +1,86 @@
+package pond_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"sort"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kraneware/pond"
+)
+
+func TestWalkDir(t *testing.T) {
+
+	pool := pond.New(3, 10)
+	defer pool.StopAndWait()
+
+	fsys := fstest.MapFS{
+		"a.txt":         {Data: []byte("a")},
+		"dir/b.txt":     {Data: []byte("bb")},
+		"dir/sub/c.txt": {Data: []byte("ccc")},
+	}
+
+	var mutex sync.Mutex
+	var paths []string
+	var size int
+	err := pond.WalkDir(context.Background(), pool, fsys, ".", func(ctx context.Context, path string, d fs.DirEntry) error {
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		paths = append(paths, path)
+		size += len(data)
+		mutex.Unlock()
+		return nil
+	})
+
+	sort.Strings(paths)
+	assertEqual(t, nil, err)
+	assertEqual(t, 3, len(paths))
+	assertEqual(t, "dir/sub/c.txt", paths[2])
+	assertEqual(t, 6, size)
+}
+
+func TestWalkDirWithErrors(t *testing.T) {
+
+	pool := pond.New(3, 10)
+	defer pool.StopAndWait()
+
+	fsys := fstest.MapFS{
+		"a.txt": {},
+		"b.txt": {},
+	}
+
+	sampleErr := errors.New("sample error")
+	err := pond.WalkDir(context.Background(), pool, fsys, ".", func(ctx context.Context, path string, d fs.DirEntry) error {
+		if path == "b.txt" {
+			return sampleErr
+		}
+		return nil
+	})
+
+	assertEqual(t, true, errors.Is(err, sampleErr))
+	assertEqual(t, "1 tasks failed: task 1 (b.txt): sample error", err.Error())
+}
+
+func TestWalkDirWithCancelledContext(t *testing.T) {
+
+	pool := pond.New(3, 10)
+	defer pool.StopAndWait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	err := pond.WalkDir(ctx, pool, fstest.MapFS{"a.txt": {}}, ".", func(ctx context.Context, path string, d fs.DirEntry) error {
+		called = true
+		return nil
+	})
+
+	assertEqual(t, context.Canceled, err)
+	assertEqual(t, false, called)
+}
@@ -0,0 +1,161 @@
+package pond
+
+// LabelWeights enables weighted fair queuing between labels: whenever tasks of several labels are waiting, each label
+// gets a share of the dequeued tasks proportional to its weight (e.g. with {"sync": 3, "reports": 1}, 3 sync tasks
+// are dequeued for every report). Labels without a weight, including the empty one, have a weight of 1, so an empty map shares them evenly.
+// Scheduling is work-conserving: labels without waiting tasks are skipped, lending their share to the others, and
+// don't accumulate credit while idle. Within each label (and lane, see Lanes), tasks are dequeued in the pool's order.
+func LabelWeights(weights map[string]int) Option {
+	return func(pool *WorkerPool) {
+		pool.labelWeights = copyWeights(weights)
+		if pool.labelWeights == nil {
+			pool.labelWeights = map[string]int{}
+		}
+	}
+}
+
+// copyWeights returns a copy of the given label weights, or nil if there are none
+func copyWeights(weights map[string]int) map[string]int {
+	if weights == nil {
+		return nil
+	}
+
+	copied := make(map[string]int, len(weights))
+	for label, weight := range weights {
+		copied[label] = weight
+	}
+	return copied
+}
+
+// weightedBuffer is a task buffer that holds a buffer per label, in the pool's order, and dequeues from them using
+// smooth weighted round-robin
+type weightedBuffer struct {
+	order   Order
+	weights map[string]int
+	// Queues of the labels with waiting tasks, in the order they got their first waiting task
+	queues  []*labelQueue
+	byLabel map[string]*labelQueue
+	// Tasks pushed to the front, which go before all labels, the last one first
+	front  []*queuedTask
+	length int
+}
+
+// labelQueue holds the waiting tasks of a label
+type labelQueue struct {
+	label  string
+	weight int
+	// Credit accumulated by the label, the one with the most credit is served next
+	credit int
+	tasks  taskBuffer
+}
+
+// newWeightedBuffer creates a task buffer that shares dequeues between labels according to the given weights
+func newWeightedBuffer(order Order, weights map[string]int) *weightedBuffer {
+	return &weightedBuffer{
+		order:   order,
+		weights: weights,
+		byLabel: make(map[string]*labelQueue),
+	}
+}
+
+func (b *weightedBuffer) Push(task *queuedTask) {
+	label := task.info.Label
+	queue, ok := b.byLabel[label]
+	if !ok {
+		weight, ok := b.weights[label]
+		if !ok {
+			weight = 1
+		}
+		queue = &labelQueue{
+			label:  label,
+			weight: weight,
+			tasks:  newTaskBuffer(b.order),
+		}
+		b.byLabel[label] = queue
+		b.queues = append(b.queues, queue)
+	}
+
+	queue.tasks.Push(task)
+	b.length++
+}
+
+func (b *weightedBuffer) PushFront(task *queuedTask) {
+	b.front = append(b.front, task)
+	b.length++
+}
+
+func (b *weightedBuffer) Pop() *queuedTask {
+	if last := len(b.front) - 1; last >= 0 {
+		task := b.front[last]
+		b.front[last] = nil
+		b.front = b.front[:last]
+		b.length--
+		return task
+	}
+
+	var next *labelQueue
+	total := 0
+	for _, queue := range b.queues {
+		queue.credit += queue.weight
+		total += queue.weight
+		if next == nil || queue.credit > next.credit {
+			next = queue
+		}
+	}
+	next.credit -= total
+
+	task := next.tasks.Pop()
+	b.length--
+
+	// Forget labels that have no waiting tasks left, so they don't keep their credit
+	if next.tasks.Len() == 0 {
+		delete(b.byLabel, next.label)
+		for i, queue := range b.queues {
+			if queue == next {
+				b.queues = append(b.queues[:i], b.queues[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return task
+}
+
+func (b *weightedBuffer) Len() int {
+	return b.length
+}
+
+func (b *weightedBuffer) Remove(match func(task *queuedTask) bool) []*queuedTask {
+	var removed []*queuedTask
+	b.front, removed = removeTasks(b.front, match)
+
+	queues := b.queues[:0]
+	for _, queue := range b.queues {
+		removed = append(removed, queue.tasks.Remove(match)...)
+		if queue.tasks.Len() > 0 {
+			queues = append(queues, queue)
+		} else {
+			delete(b.byLabel, queue.label)
+		}
+	}
+	for i := len(queues); i < len(b.queues); i++ {
+		b.queues[i] = nil
+	}
+	b.queues = queues
+	b.length -= len(removed)
+	return removed
+}
+
+func (b *weightedBuffer) Each(visit func(task *queuedTask) bool) bool {
+	for _, task := range b.front {
+		if !visit(task) {
+			return false
+		}
+	}
+	for _, queue := range b.queues {
+		if !queue.tasks.Each(visit) {
+			return false
+		}
+	}
+	return true
+}
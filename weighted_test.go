@@ -0,0 +1,92 @@
+package pond_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestLabelWeights(t *testing.T) {
+
+	pool := pond.New(1, 20, pond.MinWorkers(1), pond.LabelWeights(map[string]int{"sync": 3, "reports": 1}))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var order strings.Builder
+	var mutex sync.Mutex
+	submit := func(label string, count int) {
+		for i := 0; i < count; i++ {
+			pool.SubmitLabeled(label, func() {
+				mutex.Lock()
+				order.WriteString(label[:1])
+				mutex.Unlock()
+			})
+		}
+	}
+	submit("reports", 4)
+	submit("sync", 6)
+
+	close(release)
+	pool.StopAndWait()
+
+	// Sync tasks get 3 turns for every report, then reports borrow the unused bandwidth
+	assertEqual(t, "srsssrssrr", order.String())
+}
+
+func TestLabelWeightsWithLanes(t *testing.T) {
+
+	pool := pond.New(1, 20, pond.MinWorkers(1), pond.Lanes(0), pond.LabelWeights(nil))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var order strings.Builder
+	var mutex sync.Mutex
+	record := func(name string) func() {
+		return func() {
+			mutex.Lock()
+			order.WriteString(name)
+			mutex.Unlock()
+		}
+	}
+	pool.SubmitBatch(record("b"))
+	pool.SubmitLabeled("x", record("x"))
+	pool.SubmitLabeled("x", record("x"))
+	pool.SubmitLabeled("y", record("y"))
+
+	close(release)
+	pool.StopAndWait()
+
+	// Interactive tasks still go first, shared evenly between labels
+	assertEqual(t, "xyxb", order.String())
+	assertEqual(t, 0, len(pool.Options().LabelWeights))
+	assertEqual(t, true, pool.Options().LabelWeights != nil)
+}
+
+func TestLabelWeightsValidation(t *testing.T) {
+
+	_, err := pond.NewWithOptions(1, 10, pond.LabelWeights(map[string]int{"sync": 0}))
+	assertEqual(t, "invalid worker pool configuration: weight of label \"sync\" must be greater than 0, got 0", err.Error())
+
+	_, err = pond.NewWithOptions(1, 0, pond.LabelWeights(map[string]int{"sync": 1}))
+	assertEqual(t, true, err != nil)
+
+	// Invalid weights are ignored by New
+	pool := pond.New(1, 10, pond.LabelWeights(map[string]int{"sync": -1, "reports": 2}))
+	defer pool.StopAndWait()
+	assertEqual(t, 1, len(pool.Options().LabelWeights))
+	assertEqual(t, 2, pool.Options().LabelWeights["reports"])
+}
@@ -2,34 +2,129 @@ package pond
 
 import (
 	"context"
+	"runtime/pprof"
 	"sync"
 )
 
 // worker represents a worker goroutine
-func worker(context context.Context, waitGroup *sync.WaitGroup, firstTask func(), tasks <-chan func(), taskExecutor func(func(), bool)) {
+func worker(context context.Context, waitGroup *sync.WaitGroup, firstTask *queuedTask, tasks *taskQueue, taskExecutor func(context.Context, *queuedTask, bool)) {
+
+	// Annotate this goroutine with the profiler labels carried by the context (pool name and worker ID)
+	pprof.SetGoroutineLabels(context)
 
 	// If provided, execute the first task immediately, before listening to the tasks channel
 	if firstTask != nil {
-		taskExecutor(firstTask, true)
+		taskExecutor(context, firstTask, true)
 	}
 
 	defer func() {
 		waitGroup.Done()
 	}()
 
+	// Channel through which tasks are handed over to this worker while it waits for them
+	consumer := make(chan *queuedTask, 1)
+
 	for {
-		select {
-		case <-context.Done():
-			// Pool context was cancelled, exit
+		task, ok := tasks.Pop(context, consumer)
+		if !ok {
+			// Pool context was cancelled or we have received a signal to exit
 			return
-		case task, ok := <-tasks:
-			if task == nil || !ok {
-				// We have received a signal to exit
-				return
-			}
-
-			// We have received a task, execute it
-			taskExecutor(task, false)
 		}
+
+		// We have received a task, execute it
+		taskExecutor(context, task, false)
+	}
+}
+
+// workerIDs hands out the IDs of workers, reusing the IDs of the workers that exited so that they stay below
+// the maximum number of workers
+type workerIDs struct {
+	free []uint64
+	next uint64
+	// Channel closed when an ID is released, or nil if nobody is waiting for one
+	released chan struct{}
+	mutex    sync.Mutex
+}
+
+// acquire returns an ID for a new worker, given a function that returns the number of workers counted by the pool,
+// including the new one. If there are no free IDs and all the IDs below that number are taken, some of them still
+// belong to workers that were asked to exit, so it waits for them to return their ID rather than growing the range.
+func (w *workerIDs) acquire(running func() int) uint64 {
+	for {
+		w.mutex.Lock()
+		if n := len(w.free); n > 0 {
+			id := w.free[n-1]
+			w.free = w.free[:n-1]
+			w.mutex.Unlock()
+			return id
+		}
+		if count := running(); w.next == 0 || w.next < uint64(count) {
+			id := w.next
+			w.next++
+			w.mutex.Unlock()
+			return id
+		}
+		if w.released == nil {
+			w.released = make(chan struct{})
+		}
+		released := w.released
+		w.mutex.Unlock()
+
+		<-released
+	}
+}
+
+// release returns the ID of a worker that exited, so it can be reused
+func (w *workerIDs) release(id uint64) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.free = append(w.free, id)
+	if w.released != nil {
+		close(w.released)
+		w.released = nil
+	}
+}
+
+// workerState holds the state of a worker goroutine that is reused across the tasks it executes
+type workerState struct {
+	id uint64
+	// Scratch buffer handed to tasks (see WorkerBuffer)
+	buffer []byte
+}
+
+// workerStateKey is the context key under which the state of a worker is stored
+type workerStateKey struct{}
+
+// withWorkerState returns a copy of ctx that carries the given worker state
+func withWorkerState(ctx context.Context, state *workerState) context.Context {
+	return context.WithValue(ctx, workerStateKey{}, state)
+}
+
+// workerStateFrom returns the worker state carried by ctx, or nil if there is none
+func workerStateFrom(ctx context.Context) *workerState {
+	state, _ := ctx.Value(workerStateKey{}).(*workerState)
+	return state
+}
+
+// runningWorkerState returns the state of the worker running the task the given context was passed to,
+// or nil if ctx doesn't belong to a running task
+func runningWorkerState(ctx context.Context) *workerState {
+	if task := taskFromContext(ctx); task != nil && task.workerCtx != nil {
+		return workerStateFrom(task.workerCtx)
+	}
+	return nil
+}
+
+// WorkerID returns the ID of the worker running the task the given context was passed to (see SubmitContext),
+// and false if ctx doesn't belong to a running task. IDs are unique among the running workers of a pool and lie in
+// [0, MaxWorkers), or below the raised maximum during a Burst: a worker keeps its ID until it exits, and the ID is
+// then reused by a later worker. So they can index preallocated per-worker resources (e.g. buffers or client
+// connections) that are never used by two tasks at the same time. The ID is the same as the one in the worker's
+// profiler labels.
+func WorkerID(ctx context.Context) (uint64, bool) {
+	if state := runningWorkerState(ctx); state != nil {
+		return state.id, true
 	}
+	return 0, false
 }
@@ -0,0 +1,87 @@
+package pond_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kraneware/pond"
+)
+
+func TestWorkerID(t *testing.T) {
+
+	pool := pond.New(2, 10)
+
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(2)
+
+	ids := make(chan uint64, 3)
+	for i := 0; i < 2; i++ {
+		pool.SubmitContext(func(ctx context.Context) {
+			// Keep both workers busy so each task runs on a different one
+			waitGroup.Done()
+			waitGroup.Wait()
+
+			id, ok := pond.WorkerID(ctx)
+			assertEqual(t, true, ok)
+			ids <- id
+		})
+	}
+	pool.StopAndWait()
+
+	first, second := <-ids, <-ids
+	assertEqual(t, true, first < 2 && second < 2)
+	assertEqual(t, false, first == second)
+
+	// Tasks running on the same worker see the same ID
+	single := pond.New(1, 10)
+	for i := 0; i < 2; i++ {
+		single.SubmitContext(func(ctx context.Context) {
+			id, _ := pond.WorkerID(ctx)
+			ids <- id
+		})
+	}
+	single.StopAndWait()
+
+	assertEqual(t, <-ids, <-ids)
+
+	id, ok := pond.WorkerID(context.Background())
+	assertEqual(t, uint64(0), id)
+	assertEqual(t, false, ok)
+}
+
+func TestWorkerIDsAreReused(t *testing.T) {
+
+	pool := pond.New(3, 100, pond.IdleTimeout(time.Millisecond))
+
+	var mutex sync.Mutex
+	seen := make(map[uint64]bool)
+	for round := 0; round < 5; round++ {
+		var waitGroup sync.WaitGroup
+		waitGroup.Add(10)
+		for i := 0; i < 10; i++ {
+			pool.SubmitContext(func(ctx context.Context) {
+				defer waitGroup.Done()
+
+				id, _ := pond.WorkerID(ctx)
+				mutex.Lock()
+				seen[id] = true
+				mutex.Unlock()
+				time.Sleep(time.Millisecond)
+			})
+		}
+		waitGroup.Wait()
+
+		// Let the idle workers exit, so the next round starts new ones
+		for pool.RunningWorkers() > 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	pool.StopAndWait()
+
+	// IDs stay below the maximum number of workers
+	for id := range seen {
+		assertEqual(t, true, id < 3)
+	}
+}
@@ -0,0 +1,44 @@
+package pond
+
+import "context"
+
+// Yield is a preemption point that long-running tasks can call periodically with the context they were given
+// (see SubmitContext). When a batch task (see SubmitBatch) yields while interactive tasks are waiting in the
+// queue, its continuation is paused and its worker runs the waiting interactive tasks before resuming it, which
+// approximates preempting low-priority tasks when the pool is overloaded. The paused task gives up its slot in
+// the concurrency budget (see Budget) until it resumes. Otherwise, Yield returns immediately.
+// It returns the error of ctx, so tasks can also use it to stop once they are canceled.
+func Yield(ctx context.Context) error {
+	if task := taskFromContext(ctx); task != nil && task.pool != nil && task.info.Lane == BatchLane {
+		task.pool.yield(task)
+	}
+	return ctx.Err()
+}
+
+// yield runs the interactive tasks waiting in the queue on the worker of the given task, which is paused meanwhile
+func (p *WorkerPool) yield(paused *queuedTask) {
+	yielded := false
+	var workerCtx context.Context
+
+	for {
+		task, ok := p.tasks.PopLane(InteractiveLane)
+		if !ok {
+			break
+		}
+		if !yielded {
+			// Lend the slot of the paused task to the tasks that run in its place
+			p.adaptive.release()
+			p.release(1)
+			yielded = true
+
+			// The paused task may still be using the worker's state (e.g. its scratch buffer), so give others their own
+			workerCtx = withWorkerState(paused.workerCtx, &workerState{id: workerStateFrom(paused.workerCtx).id})
+		}
+		p.executeTask(workerCtx, task, false, true)
+	}
+
+	if yielded {
+		p.acquire(context.Background(), 1)
+		p.adaptive.acquire()
+	}
+}
@@ -0,0 +1,103 @@
+package pond_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kraneware/pond"
+)
+
+func TestYieldRunsWaitingInteractiveTasks(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.Lanes(0))
+
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+	var order []string
+
+	pool.SubmitBatchContext(func(ctx context.Context) {
+		close(started)
+		<-proceed
+		order = append(order, "batch")
+		pond.Yield(ctx)
+		order = append(order, "resumed")
+	})
+	<-started
+
+	pool.Submit(func() {
+		order = append(order, "interactive1")
+	})
+	pool.Submit(func() {
+		order = append(order, "interactive2")
+	})
+	close(proceed)
+
+	pool.StopAndWait()
+
+	assertEqual(t, "batch,interactive1,interactive2,resumed", strings.Join(order, ","))
+	assertEqual(t, uint64(3), pool.CompletedTasks())
+	assertEqual(t, uint64(0), pool.WaitingTasks())
+}
+
+func TestYieldFromInteractiveTask(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.Lanes(0))
+
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+	var order []string
+
+	pool.SubmitContext(func(ctx context.Context) {
+		close(started)
+		<-proceed
+		pond.Yield(ctx)
+		order = append(order, "first")
+	})
+	<-started
+
+	pool.Submit(func() {
+		order = append(order, "second")
+	})
+	close(proceed)
+
+	pool.StopAndWait()
+
+	assertEqual(t, "first,second", strings.Join(order, ","))
+}
+
+func TestYieldReturnsContextError(t *testing.T) {
+
+	assertEqual(t, nil, pond.Yield(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assertEqual(t, context.Canceled, pond.Yield(ctx))
+}
+
+func TestYieldKeepsWorkerBusy(t *testing.T) {
+
+	pool := pond.New(1, 10, pond.Lanes(0))
+
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+	idleWorkers := -1
+
+	pool.SubmitBatchContext(func(ctx context.Context) {
+		close(started)
+		<-proceed
+		pond.Yield(ctx)
+	})
+	<-started
+
+	pool.Submit(func() {
+		idleWorkers = pool.IdleWorkers()
+	})
+	close(proceed)
+
+	pool.StopAndWait()
+
+	// The worker remains busy while it runs tasks in place of the yielding one
+	assertEqual(t, 0, idleWorkers)
+}